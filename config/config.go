@@ -19,7 +19,9 @@ type jwtConfig struct {
 }
 
 type envConfig struct {
-	Mode string
+	Mode        string
+	TimeZone    string
+	DeliveryFee string
 }
 
 type cloudinaryConfig struct {
@@ -61,7 +63,9 @@ func InitConfig() AppConfig {
 		},
 
 		ENVConfig: envConfig{
-			Mode: getEnv("ENV_MODE", "testing"),
+			Mode:        getEnv("ENV_MODE", "testing"),
+			TimeZone:    getEnv("TIMEZONE", "Asia/Jakarta"),
+			DeliveryFee: getEnv("DELIVERY_FEE", "0"),
 		},
 
 		CloudinaryConfig: cloudinaryConfig{
@@ -2,6 +2,7 @@ package db
 
 import (
 	"final-project-backend/config"
+	"final-project-backend/entity"
 	"fmt"
 	"log"
 	"os"
@@ -43,7 +44,76 @@ func Connect() (err error) {
 		return err
 	}
 
-	err = db.AutoMigrate()
+	err = db.AutoMigrate(
+		&dataMigration{},
+		&entity.IdempotencyKey{},
+		&entity.OrderStatusEvent{},
+		&entity.MenuOption{},
+		&entity.MenuOptionList{},
+		&entity.MenuAvailability{},
+		&entity.CouponCategory{},
+		&entity.CouponMenu{},
+		&entity.CouponClaim{},
+		&entity.CouponRedemption{},
+		&entity.OrderPromotion{},
+	)
+	if err != nil {
+		return err
+	}
+
+	err = addMissingColumns(&entity.Order{}, "Status")
+	if err != nil {
+		return err
+	}
+
+	err = addMissingColumns(&entity.Menu{}, "IsSoldOut", "Stock")
+	if err != nil {
+		return err
+	}
+
+	err = addMissingColumns(&entity.Coupon{}, "Type", "MaxDiscount", "MinSubtotal", "ValidFrom", "ValidUntil", "UsageLimitPerUser",
+		"RedeemCode", "RedemptionLimit", "ClaimLimitPerUser")
+	if err != nil {
+		return err
+	}
+
+	err = runOnce("unique_coupon_redeem_codes", uniqueCouponRedeemCodes)
+	if err != nil {
+		return err
+	}
+
+	err = addMissingColumns(&entity.Promotion{}, "RuleType", "BuyQuantity", "FreeQuantity", "CategoryID", "Percentage",
+		"Priority", "StacksWithPromotions", "StacksWithCoupons")
+	if err != nil {
+		return err
+	}
+
+	err = addMissingColumns(&entity.Category{}, "Slug", "DisplayOrder", "IconUrl", "IconPublicID")
+	if err != nil {
+		return err
+	}
+
+	err = runOnce("unique_category_slugs", uniqueCategorySlugs)
+	if err != nil {
+		return err
+	}
+
+	err = runOnce("backfill_coupon_redemptions", backfillCouponRedemptions)
+	if err != nil {
+		return err
+	}
+
+	err = migrateMenuSearch()
+	if err != nil {
+		return err
+	}
+
+	err = runOnce("backfill_menu_options", backfillMenuOptions)
+	if err != nil {
+		return err
+	}
+
+	err = runOnce("backfill_order_statuses", backfillOrderStatuses)
 	if err != nil {
 		return err
 	}
@@ -51,6 +121,163 @@ func Connect() (err error) {
 	return
 }
 
+// backfillOrderStatuses gives the orders placed before the status column
+// existed the status of their delivery, which was all that tracked their
+// progress. Their timeline gets the placement and, for orders that moved on,
+// the move to their current status as of their last update.
+func backfillOrderStatuses(tx *gorm.DB) error {
+	err := tx.Exec(`UPDATE orders SET status = deliveries.status FROM deliveries
+		WHERE deliveries.order_id = orders.id AND orders.status = 'placed'
+		AND deliveries.status IN ('on the way', 'delivered')`).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(`INSERT INTO order_status_events (created_at, updated_at, order_id, from_status, to_status, actor_id, note)
+		SELECT orders.order_date, orders.order_date, orders.id, '', 'placed', orders.user_id, ''
+		FROM orders WHERE NOT EXISTS (SELECT 1 FROM order_status_events WHERE order_status_events.order_id = orders.id)
+		UNION ALL
+		SELECT orders.updated_at, orders.updated_at, orders.id, 'placed', orders.status, 0, 'recorded from the delivery status'
+		FROM orders WHERE orders.status <> 'placed'
+		AND NOT EXISTS (SELECT 1 FROM order_status_events WHERE order_status_events.order_id = orders.id)`).Error
+}
+
+// backfillCouponRedemptions records the orders that used a coupon before the
+// ledger existed. Those orders could only use fixed coupons, which took their
+// discount off the subtotal without going below zero, so an order with a total
+// was given the whole discount. An order with a total of zero was given its
+// subtotal, rebuilt from the menus' current prices. Coupons that have since
+// been changed to another type are skipped since their old discount is lost.
+func backfillCouponRedemptions(tx *gorm.DB) error {
+	return tx.Exec(`INSERT INTO coupon_redemptions (created_at, updated_at, coupon_id, user_id, order_id,
+			discount, delivery_discount, order_total, redeemed_at, cancelled_at)
+		SELECT orders.order_date, orders.order_date, orders.coupon_id, orders.user_id, orders.id,
+			CASE WHEN orders.total_price > 0 THEN coupons.discount
+				ELSE LEAST(coupons.discount, (SELECT COALESCE(SUM(order_details.quantity * menus.price), 0)
+					FROM order_details JOIN menus ON menus.id = order_details.menu_id
+					WHERE order_details.order_id = orders.id AND order_details.deleted_at IS NULL)) END,
+			0, orders.total_price, orders.order_date,
+			CASE WHEN orders.status = 'cancelled' THEN orders.updated_at END
+		FROM orders JOIN coupons ON coupons.id = orders.coupon_id
+		WHERE orders.deleted_at IS NULL AND coupons.type = 'fixed'
+		AND NOT EXISTS (SELECT 1 FROM coupon_redemptions WHERE coupon_redemptions.order_id = orders.id)`).Error
+}
+
+// uniqueCategorySlugs gives the categories created before slugs existed one
+// derived from their name, suffixes the ID to slugs that would clash, and then
+// makes slugs unique among the categories that are not deleted.
+func uniqueCategorySlugs(tx *gorm.DB) error {
+	err := tx.Exec(`UPDATE categories SET slug = trim(both '-' from regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g'))
+		WHERE slug IS NULL OR slug = ''`).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Exec(`UPDATE categories SET slug = trim(both '-' from categories.slug || '-' || categories.id)
+		WHERE categories.deleted_at IS NULL AND (categories.slug = '' OR EXISTS (SELECT 1 FROM categories other
+			WHERE other.slug = categories.slug AND other.id < categories.id AND other.deleted_at IS NULL))`).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Exec("DROP INDEX IF EXISTS idx_categories_slug").Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec("CREATE UNIQUE INDEX idx_categories_slug ON categories (slug) WHERE deleted_at IS NULL").Error
+}
+
+// uniqueCouponRedeemCodes suffixes the ID to redeem codes that clash with an
+// older coupon's and then makes redeem codes unique among the coupons that are
+// not deleted. Coupons without a redeem code are left out of the index.
+func uniqueCouponRedeemCodes(tx *gorm.DB) error {
+	err := tx.Exec(`UPDATE coupons SET redeem_code = coupons.redeem_code || coupons.id
+		WHERE coupons.deleted_at IS NULL AND coupons.redeem_code <> '' AND EXISTS (SELECT 1 FROM coupons other
+			WHERE other.redeem_code = coupons.redeem_code AND other.id < coupons.id AND other.deleted_at IS NULL)`).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Exec("DROP INDEX IF EXISTS idx_coupons_redeem_code").Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec("CREATE UNIQUE INDEX idx_coupons_redeem_code ON coupons (redeem_code) WHERE deleted_at IS NULL AND redeem_code <> ''").Error
+}
+
+// migrateMenuSearch adds the menus full-text search column and fills it for
+// menus that do not have it yet.
+func migrateMenuSearch() error {
+	err := addMissingColumns(&entity.Menu{}, "SearchVector")
+	if err != nil {
+		return err
+	}
+
+	if !db.Migrator().HasIndex(&entity.Menu{}, "idx_menus_search_vector") {
+		err = db.Migrator().CreateIndex(&entity.Menu{}, "idx_menus_search_vector")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = MigrateMenuSearch(db)
+	if err != nil {
+		return err
+	}
+
+	return db.Exec("UPDATE menus SET search_vector = menu_search_vector(id) WHERE search_vector IS NULL").Error
+}
+
+// MigrateMenuSearch creates the function computing a menu's search vector
+// from its name, description and category names, and the trigram index that
+// lets misspelt searches still find menus by name. Names are indexed both as
+// is and English-stemmed so Indonesian and English terms match. The
+// repository tests use it to set up their database.
+func MigrateMenuSearch(db *gorm.DB) error {
+	err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Exec("CREATE INDEX IF NOT EXISTS idx_menus_name_trgm ON menus USING gin (name gin_trgm_ops)").Error
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(`CREATE OR REPLACE FUNCTION menu_search_vector(menu_id bigint) RETURNS tsvector AS $$
+		SELECT setweight(to_tsvector('simple', coalesce(m.name, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(m.name, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(m.description, '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(m.description, '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce((
+				SELECT string_agg(c.name, ' ') FROM categories_menus cm
+				JOIN categories c ON c.id = cm.category_id
+				WHERE cm.menu_id = m.id AND cm.deleted_at IS NULL
+			), '')), 'C')
+		FROM menus m WHERE m.id = menu_id
+	$$ LANGUAGE sql STABLE`).Error
+}
+
+// addMissingColumns adds new fields to an existing table. AutoMigrate is not
+// used for those tables since it would also rewrite the types of the jsonb
+// and varchar columns created outside of gorm.
+func addMissingColumns(model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if db.Migrator().HasColumn(model, field) {
+			continue
+		}
+
+		err := db.Migrator().AddColumn(model, field)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func Get() *gorm.DB {
 	return db
 }
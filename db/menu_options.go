@@ -0,0 +1,78 @@
+package db
+
+import (
+	"encoding/json"
+	"final-project-backend/entity"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// backfillMenuOptions copies the option groups of menus created before the
+// menu_options tables existed out of their menu_options JSON column. Menus
+// that ever had rows in the tables are left alone.
+func backfillMenuOptions(tx *gorm.DB) error {
+	var menus []entity.Menu
+	err := tx.Select("id", "menu_options").
+		Where("NOT EXISTS (SELECT 1 FROM menu_options WHERE menu_options.menu_id = menus.id)").
+		Find(&menus).Error
+	if err != nil {
+		return err
+	}
+
+	for _, menu := range menus {
+		var options []entity.MenuOption
+		err = json.Unmarshal([]byte(menu.MenuOptions), &options)
+		if err != nil {
+			log.Printf("skipping menu options of menu %d: %v", menu.ID, err)
+			continue
+		}
+
+		if len(options) == 0 {
+			continue
+		}
+
+		for i := range options {
+			legacyMenuOption(&options[i])
+			options[i].MenuID = menu.ID
+		}
+
+		err = tx.Create(&options).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// legacyMenuOption fills the fields the JSON column never had. Radio groups
+// always needed exactly one choice.
+func legacyMenuOption(option *entity.MenuOption) {
+	if option.Max < 1 {
+		option.Max = len(option.MenuOptionLists)
+		if option.Max < 1 {
+			option.Max = 1
+		}
+	}
+
+	switch strings.ToLower(option.Type) {
+	case "radio", entity.MenuOptionTypeSingle:
+		option.Type = entity.MenuOptionTypeSingle
+		option.Max = 1
+		option.Min = 1
+	case "checkbox", entity.MenuOptionTypeMulti:
+		option.Type = entity.MenuOptionTypeMulti
+	default:
+		option.Type = entity.MenuOptionTypeMulti
+		if option.Max == 1 {
+			option.Type = entity.MenuOptionTypeSingle
+		}
+	}
+
+	option.Available = true
+	for i := range option.MenuOptionLists {
+		option.MenuOptionLists[i].Available = true
+	}
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dataMigration records a one-off data migration that has been applied.
+type dataMigration struct {
+	Name      string `gorm:"primaryKey"`
+	AppliedAt time.Time
+}
+
+// runOnce applies a one-off data migration in a transaction together with its
+// record, so it is skipped on every later boot. An instance booting at the
+// same time waits on the record's insert and then skips it too.
+func runOnce(name string, migrate func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&dataMigration{Name: name, AppliedAt: time.Now()})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		return migrate(tx)
+	})
+}
@@ -83,3 +83,81 @@ var ErrPaymentOptionNotFound = errors.New("payment option not found")
 var ErrPromotionNotFound = errors.New("promotion not found")
 
 var ErrUserCouponNotFound = errors.New("user coupon not found")
+
+var ErrInvalidMenuOption = errors.New("invalid menu option")
+
+var ErrInvalidQuantity = errors.New("invalid quantity")
+
+var ErrMenuNotInPromotion = errors.New("menu is not part of the promotion")
+
+var ErrEmptyCart = errors.New("cart is empty")
+
+var ErrCartMenuDeleted = errors.New("a menu in the cart is no longer available")
+
+var ErrCartChanged = errors.New("cart changed during checkout")
+
+var ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+
+var ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different request")
+
+var ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+
+var ErrInvalidOrderStatus = errors.New("invalid order status")
+
+var ErrInvalidStatusTransition = errors.New("order cannot move to the requested status")
+
+var ErrOrderNotCancellable = errors.New("order can no longer be cancelled")
+
+var ErrMenuOptionNotFound = errors.New("menu option not found")
+
+var ErrMenuOptionListNotFound = errors.New("menu option choice not found")
+
+var ErrInvalidMenuOptionGroup = errors.New("invalid menu option group")
+
+var ErrMenuUnavailable = errors.New("menu is not available right now")
+
+var ErrOutOfStock = errors.New("not enough stock for the order")
+
+var ErrInvalidAvailabilityWindow = errors.New("invalid availability window")
+
+var ErrInvalidStock = errors.New("stock cannot be negative")
+
+var ErrCategoryNotFound = errors.New("category not found")
+
+var ErrInvalidCategorySlug = errors.New("category slug can only contain lowercase letters, numbers and dashes")
+
+var ErrCategorySlugTaken = errors.New("category slug is already used")
+
+var ErrInvalidMenuFile = errors.New("menu file cannot be read")
+
+var ErrInvalidMenuFileFormat = errors.New("menu file format must be csv or json")
+
+var ErrMenuImportRejected = errors.New("menu import has invalid rows, nothing was imported")
+
+var ErrInvalidCoupon = errors.New("invalid coupon")
+
+var ErrCouponUnavailable = errors.New("coupon is not available")
+
+var ErrCouponNotStarted = errors.New("coupon is not valid yet")
+
+var ErrCouponExpired = errors.New("coupon has expired")
+
+var ErrCouponMinSubtotal = errors.New("order subtotal is below the coupon minimum")
+
+var ErrCouponNotEligible = errors.New("coupon does not apply to any ordered menu")
+
+var ErrCouponUsageLimit = errors.New("coupon usage limit reached")
+
+var ErrRedeemCodeNotFound = errors.New("redeem code not found")
+
+var ErrRedeemCodeTaken = errors.New("redeem code is already used by another coupon")
+
+var ErrCouponFullyRedeemed = errors.New("coupon has been fully redeemed")
+
+var ErrCouponClaimLimit = errors.New("coupon claim limit reached")
+
+var ErrInvalidCouponAssignment = errors.New("invalid coupon assignment")
+
+var ErrInvalidPromotionRule = errors.New("invalid promotion rule")
+
+var ErrPromotionNotOrderable = errors.New("promotion cannot be ordered as a bundle")
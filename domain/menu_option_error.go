@@ -0,0 +1,29 @@
+package domain
+
+import "strings"
+
+// MenuOptionGroupError explains why the selection of one option group was
+// rejected.
+type MenuOptionGroupError struct {
+	Title  string
+	Reason string
+}
+
+// MenuOptionError lists every option group whose selection breaks the
+// group's rules. errors.Is matches it with ErrInvalidMenuOption.
+type MenuOptionError struct {
+	Groups []MenuOptionGroupError
+}
+
+func (e *MenuOptionError) Error() string {
+	reasons := make([]string, len(e.Groups))
+	for i, group := range e.Groups {
+		reasons[i] = group.Title + ": " + group.Reason
+	}
+
+	return ErrInvalidMenuOption.Error() + ": " + strings.Join(reasons, "; ")
+}
+
+func (e *MenuOptionError) Unwrap() error {
+	return ErrInvalidMenuOption
+}
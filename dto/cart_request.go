@@ -20,3 +20,9 @@ type CartItemUpdateRequest struct {
 	Quantity    int                 `json:"quantity"`
 	MenuOptions []entity.MenuOption `json:"menu_options" binding:"required"`
 }
+
+type CartCheckoutRequest struct {
+	CouponID        *uint  `json:"coupon_id,omitempty"`
+	PaymentOptionID uint   `json:"payment_option_id" binding:"required"`
+	DeliveryAddress string `json:"delivery_address" binding:"required"`
+}
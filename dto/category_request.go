@@ -0,0 +1,16 @@
+package dto
+
+import "mime/multipart"
+
+// CategoryFormRequest creates or updates a category. The slug is derived from
+// the name when left empty.
+type CategoryFormRequest struct {
+	Name         string               `form:"name" binding:"required"`
+	Slug         string               `form:"slug"`
+	DisplayOrder int                  `form:"display_order"`
+	Icon         multipart.FileHeader `form:"icon"`
+}
+
+type CategoryQuery struct {
+	WithMenus bool `form:"with_menus"`
+}
@@ -1,8 +1,75 @@
 package dto
 
+import "time"
+
+// CouponRequest creates or updates a coupon. Type defaults to fixed; Discount
+// is an amount for fixed coupons and a percentage for percentage coupons.
+// RedeemCode sets a vanity code users can claim the coupon with, while
+// GenerateRedeemCode picks a random one; without either the coupon cannot be
+// claimed. Availability defaults to true when left out.
 type CouponRequest struct {
-	IssuerID     uint   `json:"admin_id"`
-	Description  string `json:"description" binding:"required"`
-	Discount     int    `json:"discount" binding:"required"`
-	Availability bool   `json:"availability"`
+	IssuerID           uint       `json:"admin_id"`
+	Description        string     `json:"description" binding:"required"`
+	Type               string     `json:"type"`
+	Discount           int        `json:"discount"`
+	MaxDiscount        int        `json:"max_discount"`
+	MinSubtotal        int        `json:"min_subtotal"`
+	ValidFrom          *time.Time `json:"valid_from"`
+	ValidUntil         *time.Time `json:"valid_until"`
+	UsageLimitPerUser  int        `json:"usage_limit_per_user"`
+	RedeemCode         string     `json:"redeem_code"`
+	GenerateRedeemCode bool       `json:"generate_redeem_code"`
+	RedemptionLimit    int        `json:"redemption_limit"`
+	ClaimLimitPerUser  int        `json:"claim_limit_per_user"`
+	CategoryIDs        []uint     `json:"category_ids"`
+	MenuIDs            []uint     `json:"menu_ids"`
+	Availability       *bool      `json:"availability"`
+}
+
+// CouponUpdateRequest changes the fields of a coupon that are present in the
+// body; omitted or null fields keep their value. An empty redeem_code removes
+// the coupon's redeem code, and category_ids or menu_ids replace the coupon's
+// eligible categories or menus.
+type CouponUpdateRequest struct {
+	Description        *string    `json:"description"`
+	Type               *string    `json:"type"`
+	Discount           *int       `json:"discount"`
+	MaxDiscount        *int       `json:"max_discount"`
+	MinSubtotal        *int       `json:"min_subtotal"`
+	ValidFrom          *time.Time `json:"valid_from"`
+	ValidUntil         *time.Time `json:"valid_until"`
+	UsageLimitPerUser  *int       `json:"usage_limit_per_user"`
+	RedeemCode         *string    `json:"redeem_code"`
+	GenerateRedeemCode bool       `json:"generate_redeem_code"`
+	RedemptionLimit    *int       `json:"redemption_limit"`
+	ClaimLimitPerUser  *int       `json:"claim_limit_per_user"`
+	CategoryIDs        *[]uint    `json:"category_ids"`
+	MenuIDs            *[]uint    `json:"menu_ids"`
+	Availability       *bool      `json:"availability"`
+}
+
+type RedeemCouponRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// CouponAssignmentRequest gives a coupon either to the listed users or to
+// every customer in the segment. Quantity defaults to 1.
+type CouponAssignmentRequest struct {
+	UserIDs  []uint       `json:"user_ids"`
+	Segment  *UserSegment `json:"segment"`
+	Quantity int          `json:"quantity"`
+}
+
+// UserSegment selects the customers matching all of its filters.
+type UserSegment struct {
+	RegisteredAfter *time.Time `json:"registered_after"`
+	MinOrders       int        `json:"min_orders"`
+	TopPlayers      int        `json:"top_players"`
+	FavoriteMenuID  uint       `json:"favorite_menu_id"`
+}
+
+// IsEmpty reports whether the segment has no filter, which would match every
+// customer.
+func (s UserSegment) IsEmpty() bool {
+	return s.RegisteredAfter == nil && s.MinOrders == 0 && s.TopPlayers == 0 && s.FavoriteMenuID == 0
 }
@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+// CouponAssignmentReport tells how many users received the coupon. Listed
+// user IDs that do not exist are returned in MissingUserIDs.
+type CouponAssignmentReport struct {
+	CouponID       uint   `json:"coupon_id"`
+	Quantity       int    `json:"quantity"`
+	Matched        int    `json:"matched"`
+	Assigned       int    `json:"assigned"`
+	MissingUserIDs []uint `json:"missing_user_ids"`
+}
+
+// CouponRedemptionSummary totals the redemptions of one coupon that were not
+// cancelled. DiscountGiven includes delivery discounts and ROI is the order
+// value earned for every rupiah of discount given.
+type CouponRedemptionSummary struct {
+	CouponID      uint    `json:"coupon_id"`
+	Description   string  `json:"description"`
+	Type          string  `json:"type"`
+	Redemptions   int64   `json:"redemptions"`
+	Users         int64   `json:"users"`
+	DiscountGiven int64   `json:"discount_given"`
+	OrderValue    int64   `json:"order_value"`
+	ROI           float64 `json:"roi"`
+}
+
+type CouponRedemptionReport struct {
+	StartDate     string                    `json:"start_date,omitempty"`
+	EndDate       string                    `json:"end_date,omitempty"`
+	Coupons       []CouponRedemptionSummary `json:"coupons"`
+	DiscountGiven int64                     `json:"discount_given"`
+	OrderValue    int64                     `json:"order_value"`
+	ROI           float64                   `json:"roi"`
+}
+
+// CouponDiscountPeriod totals the discount given in the day, week or month
+// starting at Period.
+type CouponDiscountPeriod struct {
+	Period        time.Time `json:"period"`
+	Redemptions   int64     `json:"redemptions"`
+	DiscountGiven int64     `json:"discount_given"`
+	OrderValue    int64     `json:"order_value"`
+}
+
+type CouponDiscountReport struct {
+	Period    string                 `json:"period"`
+	StartDate string                 `json:"start_date,omitempty"`
+	EndDate   string                 `json:"end_date,omitempty"`
+	Periods   []CouponDiscountPeriod `json:"periods"`
+}
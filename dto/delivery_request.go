@@ -2,4 +2,5 @@ package dto
 
 type DeliveryRequest struct {
 	Status string `json:"status"`
+	Note   string `json:"note"`
 }
@@ -0,0 +1,18 @@
+package dto
+
+// MenuAvailabilityRequest replaces a menu's sold-out flag and availability
+// windows. No windows makes the menu available all day.
+type MenuAvailabilityRequest struct {
+	IsSoldOut bool                            `json:"is_sold_out"`
+	Windows   []MenuAvailabilityWindowRequest `json:"windows"`
+}
+
+type MenuAvailabilityWindowRequest struct {
+	DayOfWeek *int   `json:"day_of_week"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+type MenuSoldOutRequest struct {
+	IsSoldOut *bool `json:"is_sold_out" binding:"required"`
+}
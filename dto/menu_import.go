@@ -0,0 +1,30 @@
+package dto
+
+import "encoding/json"
+
+// MenuImportRow is one menu of an import or export file. Categories are
+// matched by name or slug and Options holds the same JSON as the menu_options
+// form field.
+type MenuImportRow struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Price       int             `json:"price"`
+	Categories  []string        `json:"categories"`
+	Options     json.RawMessage `json:"options,omitempty"`
+	PictureUrl  string          `json:"picture_url"`
+}
+
+// MenuImportRowError lists the problems of one row; row 1 is the first menu
+// of the file, not counting the CSV header.
+type MenuImportRowError struct {
+	Row    int      `json:"row"`
+	Name   string   `json:"name"`
+	Errors []string `json:"errors"`
+}
+
+type MenuImportReport struct {
+	DryRun   bool                 `json:"dry_run"`
+	Total    int                  `json:"total"`
+	Imported int                  `json:"imported"`
+	Errors   []MenuImportRowError `json:"errors"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+// MenuOptionRequest creates or replaces an option group. Available defaults
+// to true when left out.
+type MenuOptionRequest struct {
+	Title           string                  `json:"title" binding:"required"`
+	Type            string                  `json:"type" binding:"required"`
+	Min             int                     `json:"min"`
+	Max             int                     `json:"max" binding:"required"`
+	Available       *bool                   `json:"available"`
+	MenuOptionLists []MenuOptionListRequest `json:"menu_option_lists"`
+}
+
+type MenuOptionListRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Price       int    `json:"price"`
+	Description string `json:"description"`
+	Available   *bool  `json:"available"`
+}
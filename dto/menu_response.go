@@ -3,13 +3,24 @@ package dto
 import "final-project-backend/entity"
 
 type MenuResponse struct {
-	ID              uint                `json:"id"`
-	Name            string              `json:"name"`
-	Description     string              `json:"description"`
-	Price           int                 `json:"price"`
-	PictureUrl      string              `json:"picture_url"`
-	AvgRating       float64             `json:"avg_rating"`
-	UserRatingCount int                 `json:"user_rating_count"`
-	MenuOptions     []entity.MenuOption `json:"menu_options"`
-	Categories      []entity.Category   `json:"categories,omitempty"`
+	ID              uint                      `json:"id"`
+	Name            string                    `json:"name"`
+	Description     string                    `json:"description"`
+	Price           int                       `json:"price"`
+	PictureUrl      string                    `json:"picture_url"`
+	AvgRating       float64                   `json:"avg_rating"`
+	UserRatingCount int                       `json:"user_rating_count"`
+	MenuOptions     []entity.MenuOption       `json:"menu_options"`
+	Categories      []entity.Category         `json:"categories,omitempty"`
+	IsSoldOut       bool                      `json:"is_sold_out"`
+	Stock           *int                      `json:"stock"`
+	IsAvailable     bool                      `json:"is_available"`
+	Availability    []entity.MenuAvailability `json:"availability_windows"`
+}
+
+type MenuSuggestionResponse struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Price      int    `json:"price"`
+	PictureUrl string `json:"picture_url"`
 }
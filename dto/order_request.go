@@ -19,3 +19,8 @@ type OrderDetailRequest struct {
 type OrderTotalRerquest struct {
 	Date string `json:"date" binding:"required"`
 }
+
+type OrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"final-project-backend/entity"
+	"time"
+)
+
+// OrderQuoteLine is one priced order line. PromotionDiscount is the part of
+// the quote's promotion discount taken off the line.
+type OrderQuoteLine struct {
+	MenuID            uint                `json:"menu_id"`
+	MenuName          string              `json:"menu_name"`
+	Quantity          int                 `json:"quantity"`
+	UnitPrice         int                 `json:"unit_price"`
+	OptionSurcharge   int                 `json:"option_surcharge"`
+	Subtotal          int                 `json:"subtotal"`
+	PromotionDiscount int                 `json:"promotion_discount"`
+	MenuOptions       []entity.MenuOption `json:"menu_options"`
+	CategoryIDs       []uint              `json:"-"`
+}
+
+type OrderQuote struct {
+	Lines             []OrderQuoteLine   `json:"lines"`
+	PromotionPrice    int                `json:"promotion_price,omitempty"`
+	Subtotal          int                `json:"subtotal"`
+	Promotions        []AppliedPromotion `json:"promotions,omitempty"`
+	PromotionDiscount int                `json:"promotion_discount"`
+	DeliveryFee       int                `json:"delivery_fee"`
+	CouponID          *uint              `json:"coupon_id,omitempty"`
+	CouponDiscount    int                `json:"coupon_discount"`
+	DeliveryDiscount  int                `json:"delivery_discount"`
+	TotalPrice        int                `json:"total_price"`
+}
+
+// CouponEvaluation is what a coupon takes off an order: Discount off the
+// menus and DeliveryDiscount off the delivery fee.
+type CouponEvaluation struct {
+	CouponID         uint `json:"coupon_id"`
+	Discount         int  `json:"discount"`
+	DeliveryDiscount int  `json:"delivery_discount"`
+}
+
+type OrderResponse struct {
+	ID              uint                     `json:"id"`
+	OrderDate       time.Time                `json:"order_date"`
+	CouponID        *uint                    `json:"coupon_id,omitempty"`
+	PaymentOptionID uint                     `json:"payment_option_id"`
+	PaymentOption   entity.PaymentOption     `json:"payment_option"`
+	OrderedMenus    string                   `json:"ordered_menus"`
+	Status          string                   `json:"status"`
+	TotalPrice      int                      `json:"total_price"`
+	UserID          uint                     `json:"user_id"`
+	Delivery        entity.Delivery          `json:"delivery"`
+	OrderDetails    []entity.OrderDetailResp `json:"order_details"`
+	Promotions      []AppliedPromotion       `json:"promotions"`
+}
+
+// AppliedPromotion is an automatic promotion applied to a quoted order.
+type AppliedPromotion struct {
+	PromotionID uint   `json:"promotion_id"`
+	Name        string `json:"name"`
+	RuleType    string `json:"rule_type"`
+	Discount    int    `json:"discount"`
+}
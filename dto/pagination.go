@@ -0,0 +1,32 @@
+package dto
+
+// MaxLimit caps the page size a client may request.
+const MaxLimit = 100
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	TotalItems int64 `json:"total_items"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// NewPagination describes the page of query out of totalItems rows. An
+// unpaginated query is described as a single page holding every row.
+func NewPagination(query Query, totalItems int64) Pagination {
+	if !query.IsPaginated() {
+		pagination := Pagination{Page: 1, Limit: int(totalItems), TotalItems: totalItems}
+		if totalItems > 0 {
+			pagination.TotalPages = 1
+		}
+		return pagination
+	}
+
+	limit := query.GetLimit()
+
+	return Pagination{
+		Page:       query.GetPage(),
+		Limit:      limit,
+		TotalItems: totalItems,
+		TotalPages: int((totalItems + int64(limit) - 1) / int64(limit)),
+	}
+}
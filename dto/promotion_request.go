@@ -5,12 +5,22 @@ import (
 	"time"
 )
 
+// PromotionFormRequest creates or updates a promotion. Price and MenuIDs are
+// needed unless RuleType makes it a category_percentage rule.
 type PromotionFormRequest struct {
-	Name             string               `form:"name" binding:"required"`
-	Description      string               `form:"description" binding:"required"`
-	Price            int                  `form:"price" binding:"required"`
-	Picture          multipart.FileHeader `form:"picture"`
-	ExpiredDate      time.Time            `form:"expired_date" binding:"required"`
-	PaymentOptionIDs []uint               `form:"payment_option_ids" binding:"required"`
-	MenuIDs          []uint               `form:"menu_ids" binding:"required"`
+	Name                 string               `form:"name" binding:"required"`
+	Description          string               `form:"description" binding:"required"`
+	Price                int                  `form:"price"`
+	Picture              multipart.FileHeader `form:"picture"`
+	ExpiredDate          time.Time            `form:"expired_date" binding:"required"`
+	PaymentOptionIDs     []uint               `form:"payment_option_ids" binding:"required"`
+	MenuIDs              []uint               `form:"menu_ids"`
+	RuleType             string               `form:"rule_type"`
+	BuyQuantity          int                  `form:"buy_quantity"`
+	FreeQuantity         int                  `form:"free_quantity"`
+	CategoryID           uint                 `form:"category_id"`
+	Percentage           int                  `form:"percentage"`
+	Priority             int                  `form:"priority"`
+	StacksWithPromotions bool                 `form:"stacks_with_promotions"`
+	StacksWithCoupons    bool                 `form:"stacks_with_coupons"`
 }
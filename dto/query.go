@@ -1,11 +1,53 @@
 package dto
 
 type Query struct {
-	Search   string `form:"s"`
-	SortBy   string `form:"sortBy,default=order_date"`
-	Sort     string `form:"sort,default=desc"`
-	Limit    int    `form:"limit,default=10"`
-	Page     int    `form:"page,default=1"`
-	Category string `form:"cat,default="`
-	Days     string `form:"days,default=0"`
+	Search          string   `form:"s"`
+	SortBy          string   `form:"sortBy"`
+	Sort            string   `form:"sort"`
+	Limit           int      `form:"limit"`
+	Page            int      `form:"page"`
+	Category        string   `form:"cat,default="`
+	Days            string   `form:"days,default=0"`
+	StartDate       string   `form:"start_date"`
+	EndDate         string   `form:"end_date"`
+	MinTotal        *int     `form:"min_total"`
+	MaxTotal        *int     `form:"max_total"`
+	Status          string   `form:"status"`
+	PaymentOptionID uint     `form:"payment_option_id"`
+	CategoryIDs     []uint   `form:"category_ids"`
+	MinPrice        *int     `form:"min_price"`
+	MaxPrice        *int     `form:"max_price"`
+	MinRating       *float64 `form:"min_rating"`
+	Period          string   `form:"period"`
+}
+
+// IsPaginated reports whether the client asked for a page. Lists requested
+// without limit and page return every row, as they did before pagination.
+func (q Query) IsPaginated() bool {
+	return q.Limit > 0 || q.Page > 0
+}
+
+// GetLimit returns the page size, falling back to 10 and capped at MaxLimit.
+func (q Query) GetLimit() int {
+	if q.Limit <= 0 {
+		return 10
+	}
+
+	if q.Limit > MaxLimit {
+		return MaxLimit
+	}
+
+	return q.Limit
+}
+
+func (q Query) GetPage() int {
+	if q.Page <= 0 {
+		return 1
+	}
+
+	return q.Page
+}
+
+func (q Query) GetOffset() int {
+	return (q.GetPage() - 1) * q.GetLimit()
 }
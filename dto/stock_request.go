@@ -0,0 +1,10 @@
+package dto
+
+// StockRequest sets an absolute stock count; a null stock stops tracking it.
+type StockRequest struct {
+	Stock *int `json:"stock"`
+}
+
+type RestockRequest struct {
+	Quantity int `json:"quantity" binding:"required,gt=0"`
+}
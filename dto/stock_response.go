@@ -0,0 +1,19 @@
+package dto
+
+// LowStockItem is a menu, or one choice of its option groups when ChoiceID
+// is set, that is running out of stock.
+type LowStockItem struct {
+	MenuID      uint   `json:"menu_id"`
+	MenuName    string `json:"menu_name"`
+	OptionID    uint   `json:"option_id,omitempty"`
+	OptionTitle string `json:"option_title,omitempty"`
+	ChoiceID    uint   `json:"choice_id,omitempty"`
+	ChoiceName  string `json:"choice_name,omitempty"`
+	Stock       int    `json:"stock"`
+}
+
+type LowStockReport struct {
+	Threshold int            `json:"threshold"`
+	Menus     []LowStockItem `json:"menus"`
+	Choices   []LowStockItem `json:"choices"`
+}
@@ -4,6 +4,10 @@ import "gorm.io/gorm"
 
 type Category struct {
 	gorm.Model
-	Name string `json:"name"`
-	Menu []Menu `gorm:"many2many:categories_menus;" json:"menu,omitempty"`
+	Name         string `json:"name"`
+	Slug         string `gorm:"uniqueIndex:idx_categories_slug,where:deleted_at IS NULL" json:"slug"`
+	DisplayOrder int    `gorm:"not null;default:0" json:"display_order"`
+	IconUrl      string `json:"icon_url"`
+	IconPublicID string `json:"icon_public_id"`
+	Menu         []Menu `gorm:"many2many:categories_menus;" json:"menus,omitempty"`
 }
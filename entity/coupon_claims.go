@@ -0,0 +1,11 @@
+package entity
+
+import "gorm.io/gorm"
+
+// CouponClaim records a user redeeming a coupon code, counted against the
+// coupon's redemption limits.
+type CouponClaim struct {
+	gorm.Model
+	CouponID uint `gorm:"index" json:"coupon_id"`
+	UserID   uint `gorm:"index" json:"user_id"`
+}
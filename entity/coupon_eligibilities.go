@@ -0,0 +1,11 @@
+package entity
+
+type CouponCategory struct {
+	CouponID   uint `gorm:"primaryKey" json:"coupon_id"`
+	CategoryID uint `gorm:"primaryKey" json:"category_id"`
+}
+
+type CouponMenu struct {
+	CouponID uint `gorm:"primaryKey" json:"coupon_id"`
+	MenuID   uint `gorm:"primaryKey" json:"menu_id"`
+}
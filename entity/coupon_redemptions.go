@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CouponRedemption records a coupon used on an order. Cancelling the order
+// sets CancelledAt instead of removing the record.
+type CouponRedemption struct {
+	gorm.Model
+	CouponID         uint       `gorm:"index" json:"coupon_id"`
+	UserID           uint       `gorm:"index" json:"user_id"`
+	OrderID          uint       `gorm:"uniqueIndex" json:"order_id"`
+	Discount         int        `json:"discount"`
+	DeliveryDiscount int        `json:"delivery_discount"`
+	OrderTotal       int        `json:"order_total"`
+	RedeemedAt       time.Time  `gorm:"index" json:"redeemed_at"`
+	CancelledAt      *time.Time `json:"cancelled_at"`
+}
@@ -1,18 +1,45 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	CouponTypeFixed        = "fixed"
+	CouponTypePercentage   = "percentage"
+	CouponTypeFreeDelivery = "free_delivery"
+)
+
+// Coupon discounts an order. Discount is an amount for fixed coupons and a
+// percentage for percentage coupons, where MaxDiscount caps the amount. Zero
+// MaxDiscount, MinSubtotal and UsageLimitPerUser mean no limit, and a coupon
+// without eligible categories or menus applies to the whole order.
+//
+// Users can claim a coupon with a RedeemCode through as many claims as
+// RedemptionLimit allows in total and ClaimLimitPerUser allows each user,
+// zero again meaning no limit. Coupons without a RedeemCode cannot be claimed.
 type Coupon struct {
 	gorm.Model
-	Code         uuid.UUID `json:"code"`
-	Description  string    `json:"description"`
-	IssuerID     uint      `json:"issuer_id"`
-	Discount     int       `json:"discount"`
-	Availability bool      `json:"availability"`
-	Users        []User    `gorm:"many2many:users_coupons;" json:"users,omitempty"`
+	Code               uuid.UUID  `json:"code"`
+	Description        string     `json:"description"`
+	IssuerID           uint       `json:"issuer_id"`
+	Type               string     `gorm:"not null;default:'fixed'" json:"type"`
+	Discount           int        `json:"discount"`
+	MaxDiscount        int        `gorm:"not null;default:0" json:"max_discount"`
+	MinSubtotal        int        `gorm:"not null;default:0" json:"min_subtotal"`
+	ValidFrom          *time.Time `json:"valid_from"`
+	ValidUntil         *time.Time `json:"valid_until"`
+	UsageLimitPerUser  int        `gorm:"not null;default:0" json:"usage_limit_per_user"`
+	RedeemCode         string     `gorm:"uniqueIndex:idx_coupons_redeem_code,where:deleted_at IS NULL AND redeem_code <> ''" json:"redeem_code,omitempty"`
+	RedemptionLimit    int        `gorm:"not null;default:0" json:"redemption_limit"`
+	ClaimLimitPerUser  int        `gorm:"not null;default:0" json:"claim_limit_per_user"`
+	Availability       bool       `json:"availability"`
+	EligibleCategories []Category `gorm:"many2many:coupon_categories;" json:"eligible_categories,omitempty"`
+	EligibleMenus      []Menu     `gorm:"many2many:coupon_menus;" json:"eligible_menus,omitempty"`
+	Users              []User     `gorm:"many2many:users_coupons;" json:"users,omitempty"`
 }
 
 // type Person struct {
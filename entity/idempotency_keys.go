@@ -0,0 +1,12 @@
+package entity
+
+import "gorm.io/gorm"
+
+type IdempotencyKey struct {
+	gorm.Model
+	Key          string `gorm:"uniqueIndex:idx_idempotency_keys_user_key" json:"key"`
+	UserID       uint   `gorm:"uniqueIndex:idx_idempotency_keys_user_key" json:"user_id"`
+	RequestHash  string `json:"request_hash"`
+	StatusCode   int    `json:"status_code"`
+	ResponseBody string `json:"response_body"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// MenuAvailability is a window in which a menu can be ordered, written in the
+// restaurant's time zone. A nil DayOfWeek (0 is Sunday) repeats the window
+// every day, and empty times cover the whole day. A window ending before it
+// starts runs past midnight.
+type MenuAvailability struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MenuID    uint      `gorm:"index" json:"-"`
+	DayOfWeek *int      `json:"day_of_week"`
+	StartTime string    `gorm:"type:varchar(5)" json:"start_time"`
+	EndTime   string    `gorm:"type:varchar(5)" json:"end_time"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
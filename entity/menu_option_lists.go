@@ -1,8 +1,24 @@
 package entity
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MenuOptionList is one choice of a MenuOption group. Checked is not stored;
+// it only marks a chosen item in cart and order snapshots. A nil Stock means
+// the choice's stock is not tracked.
 type MenuOptionList struct {
-	Name        string `json:"name" binding:"required"`
-	Price       int    `json:"price,string" binding:"required"`
-	Description string `json:"description" binding:"required"`
-	Checked     bool   `json:"checked"`
+	ID           uint           `gorm:"primaryKey" json:"id,omitempty"`
+	MenuOptionID uint           `gorm:"index" json:"-"`
+	Name         string         `json:"name" binding:"required"`
+	Price        int            `json:"price,string" binding:"required"`
+	Description  string         `json:"description" binding:"required"`
+	Available    bool           `json:"available"`
+	Stock        *int           `json:"stock,omitempty"`
+	Checked      bool           `gorm:"-" json:"checked"`
+	CreatedAt    time.Time      `json:"-"`
+	UpdatedAt    time.Time      `json:"-"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 }
@@ -1,8 +1,29 @@
 package entity
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MenuOptionTypeSingle = "single"
+	MenuOptionTypeMulti  = "multi"
+)
+
+// MenuOption is an option group of a menu, e.g. "Size" or "Toppings". The
+// same struct is stored as a JSON snapshot on cart items and order details,
+// where Checked marks the chosen MenuOptionLists.
 type MenuOption struct {
+	ID              uint             `gorm:"primaryKey" json:"id,omitempty"`
+	MenuID          uint             `gorm:"index" json:"-"`
 	Title           string           `json:"title" binding:"required"`
 	Type            string           `json:"type" binding:"required"`
+	Min             int              `json:"min"`
 	Max             int              `json:"max,string" binding:"required"`
+	Available       bool             `json:"available"`
 	MenuOptionLists []MenuOptionList `json:"menu_option_lists" binding:"required"`
+	CreatedAt       time.Time        `json:"-"`
+	UpdatedAt       time.Time        `json:"-"`
+	DeletedAt       gorm.DeletedAt   `gorm:"index" json:"-"`
 }
@@ -6,13 +6,18 @@ import (
 
 type Menu struct {
 	gorm.Model
-	Name            string     `json:"name"`
-	Description     string     `json:"description"`
-	Price           int        `json:"price"`
-	PictureUrl      string     `json:"picture_url"`
-	PicturePublicId string     `json:"picture_public_id"`
-	MenuOptions     string     `json:"menu_options"`
-	AvgRating       float64    `json:"avg_rating"`
-	UserRatingCount int        `json:"user_rating_count"`
-	Categories      []Category `gorm:"many2many:categories_menus;" json:"categories"`
+	Name                string             `json:"name"`
+	Description         string             `json:"description"`
+	Price               int                `json:"price"`
+	PictureUrl          string             `json:"picture_url"`
+	PicturePublicId     string             `json:"picture_public_id"`
+	MenuOptions         string             `json:"menu_options"`
+	AvgRating           float64            `json:"avg_rating"`
+	UserRatingCount     int                `json:"user_rating_count"`
+	Categories          []Category         `gorm:"many2many:categories_menus;" json:"categories"`
+	OptionGroups        []MenuOption       `gorm:"foreignKey:MenuID" json:"option_groups,omitempty"`
+	IsSoldOut           bool               `gorm:"not null;default:false" json:"is_sold_out"`
+	Stock               *int               `json:"stock"`
+	AvailabilityWindows []MenuAvailability `gorm:"foreignKey:MenuID" json:"availability_windows,omitempty"`
+	SearchVector        string             `gorm:"->:false;<-:false;type:tsvector;index:idx_menus_search_vector,type:gin" json:"-"`
 }
@@ -15,8 +15,11 @@ type OrderDetail struct {
 }
 
 type OrderDetailResp struct {
+	ID          uint         `json:"id"`
 	OrderID     uint         `json:"order_id"`
 	MenuID      uint         `json:"menu_id"`
 	Quantity    int          `json:"quantity"`
 	MenuOptions []MenuOption `json:"menu_options"`
+	IsReviewed  bool         `json:"is_reviewed"`
+	Menu        Menu         `json:"menu"`
 }
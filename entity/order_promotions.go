@@ -0,0 +1,14 @@
+package entity
+
+import "gorm.io/gorm"
+
+// OrderPromotion is a promotion applied automatically to an order, with the
+// discount it gave.
+type OrderPromotion struct {
+	gorm.Model
+	OrderID     uint   `gorm:"index" json:"order_id"`
+	PromotionID uint   `json:"promotion_id"`
+	Name        string `json:"name"`
+	RuleType    string `json:"rule_type"`
+	Discount    int    `json:"discount"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "gorm.io/gorm"
+
+type OrderStatusEvent struct {
+	gorm.Model
+	OrderID    uint   `gorm:"index" json:"order_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ActorID    uint   `json:"actor_id"`
+	Note       string `json:"note"`
+}
@@ -8,12 +8,15 @@ import (
 
 type Order struct {
 	gorm.Model
-	OrderDate       time.Time `json:"order_date"`
-	CouponID        *uint     `json:"coupon_id,omitempty"`
-	PaymentOptionID uint      `json:"payment_option_id"`
-	OrderedMenus    string    `json:"ordered_menus"`
+	OrderDate       time.Time     `json:"order_date"`
+	CouponID        *uint         `json:"coupon_id,omitempty"`
+	PaymentOptionID uint          `json:"payment_option_id"`
+	PaymentOption   PaymentOption `json:"payment_option"`
+	OrderedMenus    string        `json:"ordered_menus"`
+	Status          string        `gorm:"default:placed" json:"status"`
 	OrderDetails    []OrderDetail
-	TotalPrice      int `json:"total_price"`
+	Promotions      []OrderPromotion `json:"promotions,omitempty"`
+	TotalPrice      int              `json:"total_price"`
 	Delivery        Delivery
 	UserID          uint `json:"user_id"`
 }
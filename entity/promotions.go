@@ -6,14 +6,40 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	PromotionRuleBuyXGetY           = "buy_x_get_y"
+	PromotionRuleCategoryPercentage = "category_percentage"
+	PromotionRuleBundlePrice        = "bundle_price"
+)
+
+// Promotion is a bundle ordered at Price through its own order flow. With a
+// RuleType it is applied automatically to regular orders until it expires,
+// and only bundle_price promotions can still be ordered on their own:
+//   - buy_x_get_y makes FreeQuantity of every BuyQuantity + FreeQuantity units
+//     of a promotion menu free
+//   - category_percentage takes Percentage off the menus of CategoryID
+//   - bundle_price charges Price for each full set of the promotion menus
+//
+// Promotions are applied by descending Priority. Once one is applied, others
+// only join it when both stack with promotions. A coupon only joins the
+// promotions that stack with coupons, and the order keeps whichever of the
+// coupon and the promotions it replaces costs less.
 type Promotion struct {
 	gorm.Model
-	Name                string               `json:"name"`
-	Description         string               `json:"description"`
-	Price               int                  `json:"price"`
-	ExpiredDate         time.Time            `json:"expired_date"`
-	PictureUrl          string               `json:"picture_url"`
-	PicturePublicID     string               `json:"picture_public_id"`
-	PaymentRequirements []PaymentRequirement `json:"payment_requirements"`
-	PromotionDetails    []PromotionDetail    `json:"promotion_details"`
+	Name                 string               `json:"name"`
+	Description          string               `json:"description"`
+	Price                int                  `json:"price"`
+	ExpiredDate          time.Time            `json:"expired_date"`
+	RuleType             string               `gorm:"not null;default:''" json:"rule_type"`
+	BuyQuantity          int                  `gorm:"not null;default:0" json:"buy_quantity"`
+	FreeQuantity         int                  `gorm:"not null;default:0" json:"free_quantity"`
+	CategoryID           uint                 `gorm:"not null;default:0" json:"category_id"`
+	Percentage           int                  `gorm:"not null;default:0" json:"percentage"`
+	Priority             int                  `gorm:"not null;default:0" json:"priority"`
+	StacksWithPromotions bool                 `gorm:"not null;default:false" json:"stacks_with_promotions"`
+	StacksWithCoupons    bool                 `gorm:"not null;default:false" json:"stacks_with_coupons"`
+	PictureUrl           string               `json:"picture_url"`
+	PicturePublicID      string               `json:"picture_public_id"`
+	PaymentRequirements  []PaymentRequirement `json:"payment_requirements"`
+	PromotionDetails     []PromotionDetail    `json:"promotion_details"`
 }
@@ -46,18 +46,18 @@ func (h *Handler) AddToCart(c *gin.Context) {
 
 	userID := user.(dto.UserResponse).ID
 
-	menuOptionsString, err := json.Marshal(item.MenuOptions)
-	if err != nil {
-		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
-		return
-	}
-
 	menu, err := h.menuUsecase.GetMenuById(item.MenuID)
 	if menu == nil {
 		util.ResponseErrorJSON(c, domain.ErrMenuNotFound.Error(), "MENU_NOT_FOUND", http.StatusNotFound)
 		return
 	}
 
+	menuOptionsString, err := json.Marshal(item.MenuOptions)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
 	cartItemData := dto.CartItemData{
 		UserID:      userID,
 		MenuID:      item.MenuID,
@@ -66,6 +66,18 @@ func (h *Handler) AddToCart(c *gin.Context) {
 	}
 
 	cartRes, err := h.cartUsecase.AddToCart(cartItemData)
+	if errors.Is(err, domain.ErrMenuUnavailable) {
+		util.ResponseErrorJSON(c, domain.ErrMenuUnavailable.Error(), "MENU_UNAVAILABLE", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidMenuOption) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_MENU_OPTION", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrInternalServer) {
+		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+		return
+	}
 	if err != nil {
 		util.ResponseErrorJSON(c, err.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
 		return
@@ -115,6 +127,10 @@ func (h *Handler) UpdateCartItem(c *gin.Context) {
 	cartItem.MenuOptions = string(menuOptionsString)
 
 	cartItem, err = h.cartUsecase.UpdateCartItem(*cartItem)
+	if errors.Is(err, domain.ErrInvalidMenuOption) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_MENU_OPTION", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrUpdateCart.Error(), "UPDATE_CART_FAILED", http.StatusInternalServerError)
 		return
@@ -159,3 +175,25 @@ func (h *Handler) EmptyCart(c *gin.Context) {
 
 	util.ResponseSuccesJSON(c, nil, http.StatusNoContent)
 }
+
+func (h *Handler) CheckoutCart(c *gin.Context) {
+	user, ok := c.Get("user")
+	if !ok {
+		util.ResponseErrorJSON(c, domain.ErrUnauthorized.Error(), "UNAUTHORIZED", 401)
+		return
+	}
+
+	var input dto.CartCheckoutRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	orderRes, err := h.checkoutUsecase.CheckoutCart(user.(dto.UserResponse), input)
+	if err != nil {
+		responseCheckoutError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, orderRes, http.StatusCreated)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func responseCategoryError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrCategoryNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrCategoryNotFound.Error(), "CATEGORY_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidCategorySlug) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidCategorySlug.Error(), "INVALID_CATEGORY_SLUG", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrCategorySlugTaken) {
+		util.ResponseErrorJSON(c, domain.ErrCategorySlugTaken.Error(), "CATEGORY_SLUG_TAKEN", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrUploadImage) {
+		util.ResponseErrorJSON(c, domain.ErrUploadImage.Error(), "UPLOAD_IMAGE_FAILED", http.StatusBadRequest)
+		return
+	}
+
+	util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+}
+
+func (h *Handler) GetCategories(c *gin.Context) {
+	var query dto.CategoryQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidParams.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	categories, err := h.categoryUsecase.GetCategories(query)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "GET_MENU_FAILED", http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, categories, http.StatusOK)
+}
+
+func (h *Handler) GetCategoryById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.categoryUsecase.GetCategoryByID(uint(id))
+	if err != nil {
+		responseCategoryError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, category, http.StatusOK)
+}
+
+func (h *Handler) CreateCategory(c *gin.Context) {
+	var input dto.CategoryFormRequest
+	err := c.ShouldBindWith(&input, binding.FormMultipart)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.categoryUsecase.CreateCategory(input)
+	if err != nil {
+		responseCategoryError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, category, http.StatusCreated)
+}
+
+func (h *Handler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	var input dto.CategoryFormRequest
+	err = c.ShouldBindWith(&input, binding.FormMultipart)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.categoryUsecase.UpdateCategory(uint(id), input)
+	if err != nil {
+		responseCategoryError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, category, http.StatusOK)
+}
+
+func (h *Handler) DeleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	err = h.categoryUsecase.DeleteCategory(uint(id))
+	if err != nil {
+		responseCategoryError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, nil, http.StatusNoContent)
+}
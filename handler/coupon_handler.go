@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"final-project-backend/domain"
 	"final-project-backend/dto"
 	"final-project-backend/util"
@@ -29,6 +30,14 @@ func (h *Handler) CreateCoupon(c *gin.Context) {
 	couponRequestBody.IssuerID = userId
 
 	coupon, err := h.couponUsecase.CreateCoupon(couponRequestBody)
+	if errors.Is(err, domain.ErrInvalidCoupon) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_COUPON", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrRedeemCodeTaken) {
+		util.ResponseErrorJSON(c, err.Error(), "REDEEM_CODE_TAKEN", http.StatusConflict)
+		return
+	}
 	if err != nil {
 		util.ResponseErrorJSON(c, err.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
 		return
@@ -38,13 +47,20 @@ func (h *Handler) CreateCoupon(c *gin.Context) {
 }
 
 func (h *Handler) GetCoupons(c *gin.Context) {
-	coupons, err := h.couponUsecase.GetCoupons()
+	query := dto.Query{}
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidParams.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	coupons, total, err := h.couponUsecase.GetCoupons(query)
 	if err != nil {
 		util.ResponseErrorJSON(c, err.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
 		return
 	}
 
-	util.ResponseSuccesJSON(c, coupons, http.StatusOK)
+	util.ResponsePaginatedJSON(c, coupons, dto.NewPagination(query, total), http.StatusOK)
 }
 
 func (h *Handler) GetUserCoupons(c *gin.Context) {
@@ -59,6 +75,40 @@ func (h *Handler) GetUserCoupons(c *gin.Context) {
 	util.ResponseSuccesJSON(c, userCoupons, http.StatusOK)
 }
 
+func (h *Handler) RedeemCoupon(c *gin.Context) {
+	user := c.MustGet("user").(dto.UserResponse)
+
+	redeemRequestBody := dto.RedeemCouponRequest{}
+	err := c.ShouldBindJSON(&redeemRequestBody)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	userCoupon, err := h.couponUsecase.RedeemCoupon(user.ID, redeemRequestBody.Code)
+	if errors.Is(err, domain.ErrRedeemCodeNotFound) {
+		util.ResponseErrorJSON(c, err.Error(), "REDEEM_CODE_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrCouponFullyRedeemed) {
+		util.ResponseErrorJSON(c, err.Error(), "COUPON_FULLY_REDEEMED", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrCouponClaimLimit) {
+		util.ResponseErrorJSON(c, err.Error(), "COUPON_CLAIM_LIMIT_REACHED", http.StatusConflict)
+		return
+	}
+	if responseCouponRejection(c, err) {
+		return
+	}
+	if err != nil {
+		util.ResponseErrorJSON(c, err.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, userCoupon, http.StatusOK)
+}
+
 func (h *Handler) UpdateCoupon(c *gin.Context) {
 	id := c.Param("id")
 	idInt, err := strconv.Atoi(id)
@@ -67,7 +117,7 @@ func (h *Handler) UpdateCoupon(c *gin.Context) {
 		return
 	}
 
-	couponRequestBody := dto.CouponRequest{}
+	couponRequestBody := dto.CouponUpdateRequest{}
 	err = c.BindJSON(&couponRequestBody)
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", 400)
@@ -87,11 +137,15 @@ func (h *Handler) UpdateCoupon(c *gin.Context) {
 		return
 	}
 
-	coupon.Discount = couponRequestBody.Discount
-	coupon.Description = couponRequestBody.Description
-	coupon.Availability = couponRequestBody.Availability
-
-	couponRes, err := h.couponUsecase.UpdateCoupon(*coupon)
+	couponRes, err := h.couponUsecase.UpdateCoupon(*coupon, couponRequestBody)
+	if errors.Is(err, domain.ErrInvalidCoupon) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_COUPON", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrRedeemCodeTaken) {
+		util.ResponseErrorJSON(c, err.Error(), "REDEEM_CODE_TAKEN", http.StatusConflict)
+		return
+	}
 	if err != nil {
 		util.ResponseErrorJSON(c, err.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
 		return
@@ -100,6 +154,42 @@ func (h *Handler) UpdateCoupon(c *gin.Context) {
 	util.ResponseSuccesJSON(c, couponRes, http.StatusOK)
 }
 
+func (h *Handler) AssignCoupon(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidParams.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	assignmentRequestBody := dto.CouponAssignmentRequest{}
+	err = c.ShouldBindJSON(&assignmentRequestBody)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	coupon, err := h.couponUsecase.GetCouponById(uint(id))
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrCouponNotFound.Error(), "COUPON_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+
+	report, err := h.couponUsecase.AssignCouponToUsers(*coupon, assignmentRequestBody)
+	if errors.Is(err, domain.ErrInvalidCouponAssignment) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_COUPON_ASSIGNMENT", http.StatusBadRequest)
+		return
+	}
+	if responseCouponRejection(c, err) {
+		return
+	}
+	if err != nil {
+		util.ResponseErrorJSON(c, err.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, report, http.StatusOK)
+}
+
 func (h *Handler) DeleteCouponById(c *gin.Context) {
 	id := c.Param("id")
 	idInt, err := strconv.Atoi(id)
@@ -122,3 +212,30 @@ func (h *Handler) DeleteCouponById(c *gin.Context) {
 
 	util.ResponseSuccesJSON(c, nil, http.StatusNoContent)
 }
+
+// couponRejections maps the reasons a coupon is rejected at checkout to their
+// response codes.
+var couponRejections = []struct {
+	err  error
+	code string
+}{
+	{domain.ErrCouponUnavailable, "COUPON_UNAVAILABLE"},
+	{domain.ErrCouponNotStarted, "COUPON_NOT_STARTED"},
+	{domain.ErrCouponExpired, "COUPON_EXPIRED"},
+	{domain.ErrCouponMinSubtotal, "COUPON_MIN_SUBTOTAL_NOT_MET"},
+	{domain.ErrCouponNotEligible, "COUPON_NOT_ELIGIBLE"},
+	{domain.ErrCouponUsageLimit, "COUPON_USAGE_LIMIT_REACHED"},
+}
+
+// responseCouponRejection writes the error response when err rejects a
+// coupon and reports whether it did.
+func responseCouponRejection(c *gin.Context, err error) bool {
+	for _, rejection := range couponRejections {
+		if errors.Is(err, rejection.err) {
+			util.ResponseErrorJSON(c, err.Error(), rejection.code, http.StatusBadRequest)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func responseCouponReportError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInvalidQuery) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidQuery.Error(), "INVALID_QUERY", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrCouponNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrCouponNotFound.Error(), "COUPON_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+
+	util.ResponseErrorJSON(c, err.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+}
+
+func (h *Handler) GetCouponRedemptions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidParams.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	query := dto.Query{}
+	err = c.ShouldBindQuery(&query)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidParams.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	redemptions, total, err := h.couponReportUsecase.GetCouponRedemptions(uint(id), query)
+	if err != nil {
+		responseCouponReportError(c, err)
+		return
+	}
+
+	util.ResponsePaginatedJSON(c, redemptions, dto.NewPagination(query, total), http.StatusOK)
+}
+
+func (h *Handler) GetCouponRedemptionReport(c *gin.Context) {
+	query := dto.Query{}
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidParams.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.couponReportUsecase.GetRedemptionReport(query)
+	if err != nil {
+		responseCouponReportError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, report, http.StatusOK)
+}
+
+func (h *Handler) GetCouponDiscountReport(c *gin.Context) {
+	query := dto.Query{}
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidParams.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.couponReportUsecase.GetDiscountReport(query)
+	if err != nil {
+		responseCouponReportError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, report, http.StatusOK)
+}
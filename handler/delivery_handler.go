@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"final-project-backend/domain"
 	"final-project-backend/dto"
 	"final-project-backend/util"
 	"net/http"
@@ -25,10 +27,15 @@ func (h *Handler) UpdateDelivery(c *gin.Context) {
 		return
 	}
 
+	user := c.MustGet("user").(dto.UserResponse)
 	delivery.Status = deliveryRequest.Status
-	delivery, err = h.deliveryUsecase.UpdateDeliveryStatus(*delivery)
+	delivery, err = h.deliveryUsecase.UpdateDeliveryStatus(*delivery, user, deliveryRequest.Note)
+	if errors.Is(err, domain.ErrInvalidDeliveryStatus) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidDeliveryStatus.Error(), "INVALID_DELIVERY_STATUS", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		util.ResponseErrorJSON(c, err.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+		responseOrderStatusError(c, err)
 		return
 	}
 
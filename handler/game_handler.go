@@ -101,7 +101,14 @@ func (h *Handler) AnswerGameQuestion(c *gin.Context) {
 }
 
 func (h *Handler) GetGameLeaderboard(c *gin.Context) {
-	gamesLeaderboard, err := h.gameUsecase.GetGamesLeaderboard()
+	query := dto.Query{}
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidParams.Error(), "BAD_REQUEST", 400)
+		return
+	}
+
+	gamesLeaderboard, total, err := h.gameUsecase.GetGamesLeaderboard(query)
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INTERNAL_SERVER_ERROR", 500)
 		return
@@ -115,5 +122,5 @@ func (h *Handler) GetGameLeaderboard(c *gin.Context) {
 			Username:         game.User.Username,
 		})
 	}
-	util.ResponseSuccesJSON(c, dtoResp, 200)
+	util.ResponsePaginatedJSON(c, dtoResp, dto.NewPagination(query, total), 200)
 }
@@ -5,42 +5,60 @@ import (
 )
 
 type Handler struct {
-	authUsecase      usecase.AuthUsecase
-	userUsecase      usecase.UserUsecase
-	couponUsecase    usecase.CouponUsecase
-	menuUsecase      usecase.MenuUsecase
-	mediaUsecase     usecase.MediaUsecase
-	cartUsecase      usecase.CartUsecase
-	orderUsecase     usecase.OrderUsecase
-	deliveryUsecase  usecase.DeliveryUsecase
-	gameUsecase      usecase.GameUsecase
-	promotionUsecase usecase.PromotionUsecase
+	authUsecase         usecase.AuthUsecase
+	userUsecase         usecase.UserUsecase
+	couponUsecase       usecase.CouponUsecase
+	couponReportUsecase usecase.CouponReportUsecase
+	menuUsecase         usecase.MenuUsecase
+	menuOptionUsecase   usecase.MenuOptionUsecase
+	stockUsecase        usecase.StockUsecase
+	categoryUsecase     usecase.CategoryUsecase
+	menuImportUsecase   usecase.MenuImportUsecase
+	mediaUsecase        usecase.MediaUsecase
+	cartUsecase         usecase.CartUsecase
+	orderUsecase        usecase.OrderUsecase
+	deliveryUsecase     usecase.DeliveryUsecase
+	gameUsecase         usecase.GameUsecase
+	promotionUsecase    usecase.PromotionUsecase
+	checkoutUsecase     usecase.CheckoutUsecase
 }
 
 type HandlerConfig struct {
-	AuthUsecase      usecase.AuthUsecase
-	UserUsecase      usecase.UserUsecase
-	CouponUsecase    usecase.CouponUsecase
-	MenuUsecase      usecase.MenuUsecase
-	MediaUsecase     usecase.MediaUsecase
-	CartUsecase      usecase.CartUsecase
-	OrderUsecase     usecase.OrderUsecase
-	DeliveryUsecase  usecase.DeliveryUsecase
-	GameUsecase      usecase.GameUsecase
-	PromotionUsecase usecase.PromotionUsecase
+	AuthUsecase         usecase.AuthUsecase
+	UserUsecase         usecase.UserUsecase
+	CouponUsecase       usecase.CouponUsecase
+	CouponReportUsecase usecase.CouponReportUsecase
+	MenuUsecase         usecase.MenuUsecase
+	MenuOptionUsecase   usecase.MenuOptionUsecase
+	StockUsecase        usecase.StockUsecase
+	CategoryUsecase     usecase.CategoryUsecase
+	MenuImportUsecase   usecase.MenuImportUsecase
+	MediaUsecase        usecase.MediaUsecase
+	CartUsecase         usecase.CartUsecase
+	OrderUsecase        usecase.OrderUsecase
+	DeliveryUsecase     usecase.DeliveryUsecase
+	GameUsecase         usecase.GameUsecase
+	PromotionUsecase    usecase.PromotionUsecase
+	CheckoutUsecase     usecase.CheckoutUsecase
 }
 
 func New(c HandlerConfig) *Handler {
 	return &Handler{
-		authUsecase:      c.AuthUsecase,
-		userUsecase:      c.UserUsecase,
-		couponUsecase:    c.CouponUsecase,
-		menuUsecase:      c.MenuUsecase,
-		mediaUsecase:     c.MediaUsecase,
-		cartUsecase:      c.CartUsecase,
-		orderUsecase:     c.OrderUsecase,
-		deliveryUsecase:  c.DeliveryUsecase,
-		gameUsecase:      c.GameUsecase,
-		promotionUsecase: c.PromotionUsecase,
+		authUsecase:         c.AuthUsecase,
+		userUsecase:         c.UserUsecase,
+		couponUsecase:       c.CouponUsecase,
+		couponReportUsecase: c.CouponReportUsecase,
+		menuUsecase:         c.MenuUsecase,
+		menuOptionUsecase:   c.MenuOptionUsecase,
+		stockUsecase:        c.StockUsecase,
+		categoryUsecase:     c.CategoryUsecase,
+		menuImportUsecase:   c.MenuImportUsecase,
+		mediaUsecase:        c.MediaUsecase,
+		cartUsecase:         c.CartUsecase,
+		orderUsecase:        c.OrderUsecase,
+		deliveryUsecase:     c.DeliveryUsecase,
+		gameUsecase:         c.GameUsecase,
+		promotionUsecase:    c.PromotionUsecase,
+		checkoutUsecase:     c.CheckoutUsecase,
 	}
 }
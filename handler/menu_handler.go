@@ -1,15 +1,16 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
 	"final-project-backend/domain"
 	"final-project-backend/dto"
 	"final-project-backend/entity"
+	"final-project-backend/usecase"
 	"final-project-backend/util"
 	"net/http"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -23,25 +24,20 @@ func (h *Handler) CreateMenu(c *gin.Context) {
 		return
 	}
 
-	var menuOptionsJson []entity.MenuOption
-	err = json.Unmarshal([]byte(input.MenuOptions), &menuOptionsJson)
-
+	menuOptions, err := h.menuOptionUsecase.ParseMenuOptions(input.MenuOptions)
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
 		return
 	}
 
-	for _, menuOption := range menuOptionsJson {
-		if menuOption.Title == "" || menuOption.Type == "" || menuOption.Max == 0 {
-			util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
-			return
-		}
-		for _, menuOptionList := range menuOption.MenuOptionLists {
-			if menuOptionList.Name == "" {
-				util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
-				return
-			}
-		}
+	err = h.categoryUsecase.ValidateCategoryIDs(input.Categories)
+	if errors.Is(err, domain.ErrInvalidCategory) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_CATEGORY", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INSERT_MENU_FAILED", http.StatusInternalServerError)
+		return
 	}
 
 	menu := entity.Menu{
@@ -58,41 +54,19 @@ func (h *Handler) CreateMenu(c *gin.Context) {
 		menu.PicturePublicId = publicId
 	}
 
-	menuRes, err := h.menuUsecase.CreateMenu(menu)
-
-	if err == domain.ErrInvalidBody {
-		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
-		return
-	}
-
+	menuRes, err := h.menuUsecase.CreateMenu(menu, input.Categories, menuOptions)
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INSERT_MENU_FAILED", http.StatusInternalServerError)
 		return
 	}
 
-	var menuCategory = []entity.CategoriesMenu{}
-	input.Categories = util.UniqueUint(input.Categories)
-
-	for _, category := range input.Categories {
-		menuCategory = append(menuCategory, entity.CategoriesMenu{
-			CategoryID: category,
-			MenuID:     menuRes.ID,
-		})
-	}
-
-	err = h.menuUsecase.CreateMenuCategories(menuCategory)
-	if err != nil {
-		util.ResponseErrorJSON(c, domain.ErrInvalidCategory.Error(), "INSERT_MENU_CATEGORY_FAILED", http.StatusBadRequest)
-		return
-	}
-
 	dtoMenuRes := dto.MenuResponse{
 		ID:          menuRes.ID,
 		Name:        menuRes.Name,
 		Description: menuRes.Description,
 		Price:       menuRes.Price,
 		PictureUrl:  menuRes.PictureUrl,
-		MenuOptions: menuOptionsJson,
+		MenuOptions: menuRes.OptionGroups,
 	}
 
 	util.ResponseSuccesJSON(c, dtoMenuRes, http.StatusOK)
@@ -116,18 +90,19 @@ func (h *Handler) GetMenus(c *gin.Context) {
 		return
 	}
 
-	menus, err := h.menuUsecase.GetMenus(query)
+	menus, total, err := h.menuUsecase.GetMenus(query)
+	if errors.Is(err, domain.ErrInvalidQuery) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidQuery.Error(), "INVALID_QUERY", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "GET_MENU_FAILED", http.StatusInternalServerError)
 		return
 	}
 
+	now := time.Now()
 	var dtoMenus []dto.MenuResponse
 	for _, menu := range menus {
-
-		var menuOptions []entity.MenuOption
-		json.Unmarshal([]byte(menu.MenuOptions), &menuOptions)
-
 		dtoMenus = append(dtoMenus, dto.MenuResponse{
 			ID:              menu.ID,
 			Name:            menu.Name,
@@ -136,12 +111,16 @@ func (h *Handler) GetMenus(c *gin.Context) {
 			PictureUrl:      menu.PictureUrl,
 			AvgRating:       menu.AvgRating,
 			UserRatingCount: menu.UserRatingCount,
-			MenuOptions:     menuOptions,
+			MenuOptions:     menu.OptionGroups,
 			Categories:      menu.Categories,
+			IsSoldOut:       menu.IsSoldOut,
+			Stock:           menu.Stock,
+			IsAvailable:     usecase.IsMenuAvailable(menu, now),
+			Availability:    menu.AvailabilityWindows,
 		})
 	}
 
-	util.ResponseSuccesJSON(c, dtoMenus, http.StatusOK)
+	util.ResponsePaginatedJSON(c, dtoMenus, dto.NewPagination(query, total), http.StatusOK)
 }
 
 func (h *Handler) GetMenuById(c *gin.Context) {
@@ -165,8 +144,11 @@ func (h *Handler) GetMenuById(c *gin.Context) {
 		return
 	}
 
-	var menuOptions []entity.MenuOption
-	json.Unmarshal([]byte(menu.MenuOptions), &menuOptions)
+	menuOptions, err := h.menuOptionUsecase.GetMenuOptions(menu.ID)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "GET_MENU_FAILED", http.StatusInternalServerError)
+		return
+	}
 
 	dtoMenuRes := dto.MenuResponse{
 		ID:              menu.ID,
@@ -178,6 +160,10 @@ func (h *Handler) GetMenuById(c *gin.Context) {
 		UserRatingCount: menu.UserRatingCount,
 		MenuOptions:     menuOptions,
 		Categories:      menu.Categories,
+		IsSoldOut:       menu.IsSoldOut,
+		Stock:           menu.Stock,
+		IsAvailable:     usecase.IsMenuAvailable(*menu, time.Now()),
+		Availability:    menu.AvailabilityWindows,
 	}
 
 	util.ResponseSuccesJSON(c, dtoMenuRes, http.StatusOK)
@@ -211,25 +197,20 @@ func (h *Handler) UpdateMenu(c *gin.Context) {
 		return
 	}
 
-	var menuOptionsJson []entity.MenuOption
-	err = json.Unmarshal([]byte(input.MenuOptions), &menuOptionsJson)
-
+	menuOptions, err := h.menuOptionUsecase.ParseMenuOptions(input.MenuOptions)
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
 		return
 	}
 
-	for _, menuOption := range menuOptionsJson {
-		if menuOption.Title == "" || menuOption.Type == "" || menuOption.Max == 0 {
-			util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
-			return
-		}
-		for _, menuOptionList := range menuOption.MenuOptionLists {
-			if menuOptionList.Name == "" {
-				util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
-				return
-			}
-		}
+	err = h.categoryUsecase.ValidateCategoryIDs(input.Categories)
+	if errors.Is(err, domain.ErrInvalidCategory) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_CATEGORY", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "UPDATE_MENU_FAILED", http.StatusInternalServerError)
+		return
 	}
 
 	if (menu.PictureUrl != "") && (input.Picture.Size != 0) {
@@ -253,24 +234,7 @@ func (h *Handler) UpdateMenu(c *gin.Context) {
 		menu.PicturePublicId = publicId
 	}
 
-	var menuCategory = []entity.CategoriesMenu{}
-	input.Categories = util.UniqueUint(input.Categories)
-
-	for _, category := range input.Categories {
-		menuCategory = append(menuCategory, entity.CategoriesMenu{
-			CategoryID: category,
-			MenuID:     menuIdUint,
-		})
-	}
-
-	err = h.menuUsecase.UpdateMenuCategories(menuIdUint, menuCategory)
-	if err != nil {
-		util.ResponseErrorJSON(c, domain.ErrInvalidCategory.Error(), "UPDATE_MENU_CATEGORY_FAILED", http.StatusBadRequest)
-		return
-	}
-	menu.Categories = nil
-	menu, err = h.menuUsecase.UpdateMenu(*menu)
-
+	menu, err = h.menuUsecase.UpdateMenu(*menu, input.Categories, menuOptions)
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrUpdateMenu.Error(), "UPDATE_MENU_FAILED", http.StatusInternalServerError)
 		return
@@ -282,7 +246,7 @@ func (h *Handler) UpdateMenu(c *gin.Context) {
 		Description: menu.Description,
 		Price:       menu.Price,
 		PictureUrl:  menu.PictureUrl,
-		MenuOptions: menuOptionsJson,
+		MenuOptions: menu.OptionGroups,
 	}
 
 	util.ResponseSuccesJSON(c, dtoMenuRes, http.StatusOK)
@@ -367,12 +331,93 @@ func (h *Handler) GetFavoriteMenus(c *gin.Context) {
 	util.ResponseSuccesJSON(c, favoriteMenus, http.StatusOK)
 }
 
-func (h *Handler) GetCategories(c *gin.Context) {
-	categories, err := h.menuUsecase.GetCategories()
+func (h *Handler) SuggestMenus(c *gin.Context) {
+	menus, err := h.menuUsecase.SuggestMenus(c.Query("s"))
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "GET_MENU_FAILED", http.StatusInternalServerError)
 		return
 	}
 
-	util.ResponseSuccesJSON(c, categories, http.StatusOK)
+	suggestions := []dto.MenuSuggestionResponse{}
+	for _, menu := range menus {
+		suggestions = append(suggestions, dto.MenuSuggestionResponse{
+			ID:         menu.ID,
+			Name:       menu.Name,
+			Price:      menu.Price,
+			PictureUrl: menu.PictureUrl,
+		})
+	}
+
+	util.ResponseSuccesJSON(c, suggestions, http.StatusOK)
+}
+
+func responseMenuAvailabilityError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrMenuNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrMenuNotFound.Error(), "MENU_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidAvailabilityWindow) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidAvailabilityWindow.Error(), "INVALID_AVAILABILITY_WINDOW", http.StatusBadRequest)
+		return
+	}
+
+	util.ResponseErrorJSON(c, domain.ErrUpdateMenu.Error(), "UPDATE_MENU_FAILED", http.StatusInternalServerError)
+}
+
+func menuAvailabilityResponse(menu entity.Menu) dto.MenuResponse {
+	return dto.MenuResponse{
+		ID:           menu.ID,
+		Name:         menu.Name,
+		Description:  menu.Description,
+		Price:        menu.Price,
+		PictureUrl:   menu.PictureUrl,
+		IsSoldOut:    menu.IsSoldOut,
+		Stock:        menu.Stock,
+		IsAvailable:  usecase.IsMenuAvailable(menu, time.Now()),
+		Availability: menu.AvailabilityWindows,
+	}
+}
+
+func (h *Handler) UpdateMenuAvailability(c *gin.Context) {
+	menuId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	var request dto.MenuAvailabilityRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	menu, err := h.menuUsecase.UpdateMenuAvailability(uint(menuId), request)
+	if err != nil {
+		responseMenuAvailabilityError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, menuAvailabilityResponse(*menu), http.StatusOK)
+}
+
+func (h *Handler) UpdateMenuSoldOut(c *gin.Context) {
+	menuId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	var request dto.MenuSoldOutRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	menu, err := h.menuUsecase.SetMenuSoldOut(uint(menuId), *request.IsSoldOut)
+	if err != nil {
+		responseMenuAvailabilityError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, menuAvailabilityResponse(*menu), http.StatusOK)
 }
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/usecase"
+	"final-project-backend/util"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMenuFileSize caps the size of an uploaded menu file.
+const maxMenuFileSize = 5 << 20
+
+// menuFileFormat takes the format from the format query, then from the
+// uploaded file's extension and finally from the content type.
+func menuFileFormat(c *gin.Context, filename string) string {
+	if format := c.Query("format"); format != "" {
+		return strings.ToLower(format)
+	}
+
+	if ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), "."); ext != "" {
+		return ext
+	}
+
+	contentType := c.ContentType()
+	if strings.Contains(contentType, "csv") {
+		return usecase.MenuFileFormatCSV
+	}
+	if strings.Contains(contentType, "json") {
+		return usecase.MenuFileFormatJSON
+	}
+
+	return ""
+}
+
+func (h *Handler) ImportMenus(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidParams.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMenuFileSize)
+
+	// the file is either uploaded in the file form field or sent as the body
+	var file io.Reader = c.Request.Body
+	var filename string
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+			return
+		}
+
+		upload, err := fileHeader.Open()
+		if err != nil {
+			util.ResponseErrorJSON(c, domain.ErrInvalidMenuFile.Error(), "INVALID_MENU_FILE", http.StatusBadRequest)
+			return
+		}
+		defer upload.Close()
+
+		file = upload
+		filename = fileHeader.Filename
+	}
+
+	report, err := h.menuImportUsecase.ImportMenus(file, menuFileFormat(c, filename), dryRun)
+	if errors.Is(err, domain.ErrMenuImportRejected) {
+		util.ResponseSuccesJSON(c, report, http.StatusUnprocessableEntity)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidMenuFileFormat) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidMenuFileFormat.Error(), "INVALID_MENU_FILE_FORMAT", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidMenuFile) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_MENU_FILE", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "IMPORT_MENU_FAILED", http.StatusInternalServerError)
+		return
+	}
+
+	if dryRun {
+		util.ResponseSuccesJSON(c, report, http.StatusOK)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, report, http.StatusCreated)
+}
+
+func (h *Handler) ExportMenus(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", usecase.MenuFileFormatJSON))
+
+	file, err := h.menuImportUsecase.ExportMenus(format)
+	if errors.Is(err, domain.ErrInvalidMenuFileFormat) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidMenuFileFormat.Error(), "INVALID_MENU_FILE_FORMAT", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "EXPORT_MENU_FAILED", http.StatusInternalServerError)
+		return
+	}
+
+	contentType := "application/json"
+	if format == usecase.MenuFileFormatCSV {
+		contentType = "text/csv"
+	}
+
+	filename := fmt.Sprintf("menus-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, file)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// menuOptionParams reads the menu, option group and choice IDs of the route;
+// params missing from the route are returned as zero.
+func menuOptionParams(c *gin.Context) (menuId uint, optionId uint, choiceId uint, ok bool) {
+	ids := []*uint{&menuId, &optionId, &choiceId}
+	for i, name := range []string{"id", "optionId", "choiceId"} {
+		param := c.Param(name)
+		if param == "" {
+			continue
+		}
+
+		id, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
+			return 0, 0, 0, false
+		}
+		*ids[i] = uint(id)
+	}
+
+	return menuId, optionId, choiceId, true
+}
+
+func responseMenuOptionError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrMenuNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrMenuNotFound.Error(), "MENU_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrMenuOptionNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrMenuOptionNotFound.Error(), "MENU_OPTION_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrMenuOptionListNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrMenuOptionListNotFound.Error(), "MENU_OPTION_CHOICE_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidMenuOptionGroup) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidMenuOptionGroup.Error(), "INVALID_MENU_OPTION_GROUP", http.StatusBadRequest)
+		return
+	}
+
+	util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+}
+
+func (h *Handler) GetMenuOptions(c *gin.Context) {
+	menuId, _, _, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	options, err := h.menuOptionUsecase.GetMenuOptions(menuId)
+	if err != nil {
+		responseMenuOptionError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, options, http.StatusOK)
+}
+
+func (h *Handler) CreateMenuOption(c *gin.Context) {
+	menuId, _, _, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	var request dto.MenuOptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	option, err := h.menuOptionUsecase.CreateMenuOption(menuId, request)
+	if err != nil {
+		responseMenuOptionError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, option, http.StatusCreated)
+}
+
+func (h *Handler) UpdateMenuOption(c *gin.Context) {
+	menuId, optionId, _, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	var request dto.MenuOptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	option, err := h.menuOptionUsecase.UpdateMenuOption(menuId, optionId, request)
+	if err != nil {
+		responseMenuOptionError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, option, http.StatusOK)
+}
+
+func (h *Handler) DeleteMenuOption(c *gin.Context) {
+	menuId, optionId, _, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.menuOptionUsecase.DeleteMenuOption(menuId, optionId)
+	if err != nil {
+		responseMenuOptionError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, nil, http.StatusNoContent)
+}
+
+func (h *Handler) CreateMenuOptionList(c *gin.Context) {
+	menuId, optionId, _, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	var request dto.MenuOptionListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	optionList, err := h.menuOptionUsecase.CreateMenuOptionList(menuId, optionId, request)
+	if err != nil {
+		responseMenuOptionError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, optionList, http.StatusCreated)
+}
+
+func (h *Handler) UpdateMenuOptionList(c *gin.Context) {
+	menuId, optionId, choiceId, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	var request dto.MenuOptionListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	optionList, err := h.menuOptionUsecase.UpdateMenuOptionList(menuId, optionId, choiceId, request)
+	if err != nil {
+		responseMenuOptionError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, optionList, http.StatusOK)
+}
+
+func (h *Handler) DeleteMenuOptionList(c *gin.Context) {
+	menuId, optionId, choiceId, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	err := h.menuOptionUsecase.DeleteMenuOptionList(menuId, optionId, choiceId)
+	if err != nil {
+		responseMenuOptionError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, nil, http.StatusNoContent)
+}
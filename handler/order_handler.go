@@ -1,15 +1,14 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
 	"final-project-backend/domain"
 	"final-project-backend/dto"
-	"final-project-backend/entity"
+	"final-project-backend/usecase"
 	"final-project-backend/util"
+	"io"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -28,13 +27,17 @@ func (h *Handler) GetAllOrders(c *gin.Context) {
 		return
 	}
 
-	orders, err := h.orderUsecase.GetAllOrders(user, query)
+	orders, total, err := h.orderUsecase.GetAllOrders(user, query)
+	if errors.Is(err, domain.ErrInvalidQuery) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidQuery.Error(), "INVALID_QUERY", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInvalidRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
 		return
 	}
 
-	util.ResponseSuccesJSON(c, orders, http.StatusOK)
+	util.ResponsePaginatedJSON(c, orders, dto.NewPagination(query, total), http.StatusOK)
 }
 
 func (h *Handler) GetAllPaymentOptions(c *gin.Context) {
@@ -50,106 +53,226 @@ func (h *Handler) GetAllPaymentOptions(c *gin.Context) {
 func (h *Handler) CreateOrder(c *gin.Context) {
 	user := c.MustGet("user").(dto.UserResponse)
 	var orderRequest dto.OrderRequest
-	var err error
 
-	if err = c.ShouldBindJSON(&orderRequest); err != nil {
+	if err := c.ShouldBindJSON(&orderRequest); err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "BAD_REQUEST", http.StatusBadRequest)
 		return
 	}
 
-	order := entity.Order{
-		CouponID:        orderRequest.CouponID,
-		PaymentOptionID: orderRequest.PaymentOptionID,
+	orderRes, err := h.checkoutUsecase.Checkout(user, orderRequest)
+	if err != nil {
+		responseCheckoutError(c, err)
+		return
 	}
 
-	totalPrice := 0
-	var orderedMenusArr []string
-	for _, orderDetailRequest := range orderRequest.OrderDetailRequest {
-		// menuOptionStr, _ := json.Marshal(orderDetailRequest.MenuOptions)
-		// err := h.menuUsecase.IsValidMenuOptions(orderDetailRequest.MenuID, string(menuOptionStr))
-		// if err != nil {
-		// 	util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
-		// 	return
-		// }
+	util.ResponseSuccesJSON(c, orderRes, http.StatusCreated)
+}
 
-		menuPrice := 0
-		menu, _ := h.menuUsecase.GetMenuById(orderDetailRequest.MenuID)
-		if menu == nil {
-			util.ResponseErrorJSON(c, domain.ErrMenuNotFound.Error(), "MENU_NOT_FOUND", http.StatusNotFound)
-			return
-		}
-		menuPrice += menu.Price * orderDetailRequest.Quantity
-		orderedMenusArr = append(orderedMenusArr, menu.Name)
-		for _, menuOption := range orderDetailRequest.MenuOptions {
-			for _, optionList := range menuOption.MenuOptionLists {
-				if optionList.Checked {
-					menuPrice += optionList.Price * orderDetailRequest.Quantity
-				}
-			}
-		}
-		totalPrice += menuPrice
+func (h *Handler) QuoteOrder(c *gin.Context) {
+	user := c.MustGet("user").(dto.UserResponse)
+	var orderRequest dto.OrderRequest
+
+	if err := c.ShouldBindJSON(&orderRequest); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
 	}
 
-	orderedMenusArr = util.UniqueString(orderedMenusArr)
-	orderedMenus := strings.Join(orderedMenusArr, ",")
+	quote, err := h.checkoutUsecase.Quote(user, orderRequest)
+	if err != nil {
+		responseCheckoutError(c, err)
+		return
+	}
 
-	order.TotalPrice = totalPrice
-	order.OrderedMenus = orderedMenus
+	util.ResponseSuccesJSON(c, quote, http.StatusOK)
+}
 
-	var coupon *entity.Coupon
-	if orderRequest.CouponID != nil {
-		coupon, err = h.couponUsecase.GetCouponById(*orderRequest.CouponID)
-		if coupon == nil {
-			util.ResponseErrorJSON(c, domain.ErrCouponNotFound.Error(), "COUPON_NOT_FOUND", http.StatusNotFound)
-			return
-		}
+func responseCheckoutError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrEmptyCart) {
+		util.ResponseErrorJSON(c, domain.ErrEmptyCart.Error(), "EMPTY_CART", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrCartMenuDeleted) {
+		util.ResponseErrorJSON(c, domain.ErrCartMenuDeleted.Error(), "CART_MENU_DELETED", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrCartChanged) {
+		util.ResponseErrorJSON(c, domain.ErrCartChanged.Error(), "CART_CHANGED", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrPaymentOptionNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrPaymentOptionNotFound.Error(), "PAYMENT_OPTION_NOT_FOUND", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrCouponNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrCouponNotFound.Error(), "COUPON_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrUserCouponNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrUserCouponNotFound.Error(), "USER_COUPON_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if responseCouponRejection(c, err) {
+		return
+	}
+	if errors.Is(err, domain.ErrMenuNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrMenuNotFound.Error(), "MENU_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrMenuUnavailable) {
+		util.ResponseErrorJSON(c, domain.ErrMenuUnavailable.Error(), "MENU_UNAVAILABLE", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrOutOfStock) {
+		util.ResponseErrorJSON(c, domain.ErrOutOfStock.Error(), "OUT_OF_STOCK", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidMenuOption) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_MENU_OPTION", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidQuantity) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidQuantity.Error(), "INVALID_QUANTITY", http.StatusBadRequest)
+		return
+	}
 
-		userCoupon, _ := h.couponUsecase.GetUserCouponByFK(*orderRequest.CouponID, user.ID)
-		if userCoupon == nil {
-			util.ResponseErrorJSON(c, domain.ErrUserCouponNotFound.Error(), "USER_COUPON_NOT_FOUND", http.StatusNotFound)
-			return
-		}
-		userCoupon.Stock = userCoupon.Stock - 1
-		h.couponUsecase.UpdateUserCoupon(*userCoupon)
-		order.TotalPrice = order.TotalPrice - coupon.Discount
+	util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+}
+
+func (h *Handler) CancelOrder(c *gin.Context) {
+	user := c.MustGet("user").(dto.UserResponse)
+	id := c.Param("id")
+	uintId64, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.orderUsecase.CancelOrder(uint(uintId64), user)
+	if err != nil {
+		responseOrderStatusError(c, err)
+		return
 	}
 
-	order.UserID = user.ID
-	order.OrderDate = time.Now()
+	util.ResponseSuccesJSON(c, order, http.StatusOK)
+}
 
-	if order.TotalPrice < 0 {
-		order.TotalPrice = 0
+func (h *Handler) UpdateOrderStatus(c *gin.Context) {
+	id := c.Param("id")
+	uintId64, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
 	}
 
-	var orderDetails []entity.OrderDetail
-	for _, orderDetailRequest := range orderRequest.OrderDetailRequest {
-		menuOptionStr, _ := json.Marshal(orderDetailRequest.MenuOptions)
-		orderDetail := entity.OrderDetail{
-			MenuID:      orderDetailRequest.MenuID,
-			Quantity:    orderDetailRequest.Quantity,
-			MenuOptions: string(menuOptionStr),
-		}
-		orderDetails = append(orderDetails, orderDetail)
+	var orderStatusRequest dto.OrderStatusRequest
+	if err := c.ShouldBindJSON(&orderStatusRequest); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
 	}
 
-	deliveryData := entity.Delivery{
-		Address: orderRequest.DeliveryAddress,
-		Status:  "pending",
+	user := c.MustGet("user").(dto.UserResponse)
+	order, err := h.orderUsecase.UpdateOrderStatus(uint(uintId64), orderStatusRequest.Status, user, orderStatusRequest.Note)
+	if err != nil {
+		responseOrderStatusError(c, err)
+		return
 	}
 
-	orderRes, err := h.orderUsecase.CreateOrderProcess(order, orderDetails, deliveryData)
+	util.ResponseSuccesJSON(c, order, http.StatusOK)
+}
+
+func (h *Handler) GetOrderTimeline(c *gin.Context) {
+	user := c.MustGet("user").(dto.UserResponse)
+	id := c.Param("id")
+	uintId64, err := strconv.ParseUint(id, 10, 64)
 	if err != nil {
-		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+		util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
 		return
 	}
 
-	err = h.cartUsecase.EmptyCart(int(user.ID))
+	events, err := h.orderUsecase.GetOrderTimeline(uint(uintId64), user)
 	if err != nil {
-		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+		responseOrderStatusError(c, err)
 		return
 	}
 
-	util.ResponseSuccesJSON(c, orderRes, http.StatusCreated)
+	util.ResponseSuccesJSON(c, events, http.StatusOK)
+}
+
+// StreamOrderEvents pushes the order's status events as Server-Sent Events,
+// starting with its latest status, until the order is final or the client
+// disconnects.
+func (h *Handler) StreamOrderEvents(c *gin.Context) {
+	user := c.MustGet("user").(dto.UserResponse)
+	id := c.Param("id")
+	uintId64, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	events, unsubscribe, err := h.orderUsecase.SubscribeOrderEvents(uint(uintId64), user)
+	if err != nil {
+		responseOrderStatusError(c, err)
+		return
+	}
+	defer unsubscribe()
+
+	timeline, err := h.orderUsecase.GetOrderTimeline(uint(uintId64), user)
+	if err != nil {
+		responseOrderStatusError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	if len(timeline) > 0 {
+		latest := timeline[len(timeline)-1]
+		c.SSEvent("status", latest)
+		if usecase.IsFinalOrderStatus(latest.ToStatus) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			c.SSEvent("ping", "")
+			return true
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("status", event)
+			return !usecase.IsFinalOrderStatus(event.ToStatus)
+		}
+	})
+}
+
+func responseOrderStatusError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrOrderNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrOrderNotFound.Error(), "ORDER_NOT_FOUND", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidOrderStatus) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidOrderStatus.Error(), "INVALID_ORDER_STATUS", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidStatusTransition) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidStatusTransition.Error(), "INVALID_STATUS_TRANSITION", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrOrderNotCancellable) {
+		util.ResponseErrorJSON(c, domain.ErrOrderNotCancellable.Error(), "ORDER_NOT_CANCELLABLE", http.StatusConflict)
+		return
+	}
+
+	util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
 }
 
 func (h *Handler) GetOrderById(c *gin.Context) {
@@ -160,7 +283,8 @@ func (h *Handler) GetOrderById(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderUsecase.GetOrderByID(uint(uintId64))
+	user := c.MustGet("user").(dto.UserResponse)
+	order, err := h.orderUsecase.GetUserOrderByID(uint(uintId64), user)
 	if errors.Is(err, domain.ErrOrderNotFound) {
 		util.ResponseErrorJSON(c, domain.ErrOrderNotFound.Error(), "ORDER_NOT_FOUND", http.StatusNotFound)
 		return
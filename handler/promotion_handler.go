@@ -60,6 +60,10 @@ func (h *Handler) CreatePromotion(c *gin.Context) {
 		util.ResponseErrorJSON(c, domain.ErrPaymentOptionNotFound.Error(), "PAYMENT_OPTION_NOT_FOUND", http.StatusBadRequest)
 		return
 	}
+	if errors.Is(err, domain.ErrInvalidPromotionRule) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_PROMOTION_RULE", http.StatusBadRequest)
+		return
+	}
 
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INSERT_MENU_FAILED", http.StatusInternalServerError)
@@ -93,6 +97,10 @@ func (h *Handler) UpdatePromotion(c *gin.Context) {
 		util.ResponseErrorJSON(c, domain.ErrPaymentOptionNotFound.Error(), "PAYMENT_OPTION_NOT_FOUND", http.StatusBadRequest)
 		return
 	}
+	if errors.Is(err, domain.ErrInvalidPromotionRule) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_PROMOTION_RULE", http.StatusBadRequest)
+		return
+	}
 
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "UPDATE_MENU_FAILED", http.StatusInternalServerError)
@@ -155,6 +163,11 @@ func (h *Handler) CreatePromotionOrder(c *gin.Context) {
 		return
 	}
 
+	if errors.Is(err, domain.ErrPromotionNotOrderable) {
+		util.ResponseErrorJSON(c, domain.ErrPromotionNotOrderable.Error(), "PROMOTION_NOT_ORDERABLE", http.StatusBadRequest)
+		return
+	}
+
 	if errors.Is(err, domain.ErrPaymentOptionNotFound) {
 		util.ResponseErrorJSON(c, domain.ErrPaymentOptionNotFound.Error(), "PAYMENT_OPTION_NOT_FOUND", http.StatusBadRequest)
 		return
@@ -165,6 +178,44 @@ func (h *Handler) CreatePromotionOrder(c *gin.Context) {
 		return
 	}
 
+	if errors.Is(err, domain.ErrUserCouponNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrUserCouponNotFound.Error(), "USER_COUPON_NOT_FOUND", http.StatusBadRequest)
+		return
+	}
+
+	if responseCouponRejection(c, err) {
+		return
+	}
+
+	if errors.Is(err, domain.ErrMenuNotFound) {
+		util.ResponseErrorJSON(c, domain.ErrMenuNotFound.Error(), "MENU_NOT_FOUND", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, domain.ErrMenuNotInPromotion) {
+		util.ResponseErrorJSON(c, domain.ErrMenuNotInPromotion.Error(), "MENU_NOT_IN_PROMOTION", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, domain.ErrMenuUnavailable) {
+		util.ResponseErrorJSON(c, domain.ErrMenuUnavailable.Error(), "MENU_UNAVAILABLE", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, domain.ErrOutOfStock) {
+		util.ResponseErrorJSON(c, domain.ErrOutOfStock.Error(), "OUT_OF_STOCK", http.StatusConflict)
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidMenuOption) {
+		util.ResponseErrorJSON(c, err.Error(), "INVALID_MENU_OPTION", http.StatusBadRequest)
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidQuantity) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidQuantity.Error(), "INVALID_QUANTITY", http.StatusBadRequest)
+		return
+	}
+
 	if err != nil {
 		util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INSERT_MENU_FAILED", http.StatusInternalServerError)
 		return
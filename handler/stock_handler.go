@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/usecase"
+	"final-project-backend/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func responseStockError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInvalidStock) {
+		util.ResponseErrorJSON(c, domain.ErrInvalidStock.Error(), "INVALID_STOCK", http.StatusBadRequest)
+		return
+	}
+
+	responseMenuOptionError(c, err)
+}
+
+func (h *Handler) UpdateMenuStock(c *gin.Context) {
+	menuId, _, _, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	var request dto.StockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	menu, err := h.stockUsecase.SetMenuStock(menuId, request.Stock)
+	if err != nil {
+		responseStockError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, menu, http.StatusOK)
+}
+
+func (h *Handler) RestockMenu(c *gin.Context) {
+	menuId, _, _, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	var request dto.RestockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	menu, err := h.stockUsecase.RestockMenu(menuId, request.Quantity)
+	if err != nil {
+		responseStockError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, menu, http.StatusOK)
+}
+
+func (h *Handler) UpdateMenuOptionListStock(c *gin.Context) {
+	menuId, optionId, choiceId, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	var request dto.StockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	optionList, err := h.stockUsecase.SetMenuOptionListStock(menuId, optionId, choiceId, request.Stock)
+	if err != nil {
+		responseStockError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, optionList, http.StatusOK)
+}
+
+func (h *Handler) RestockMenuOptionList(c *gin.Context) {
+	menuId, optionId, choiceId, ok := menuOptionParams(c)
+	if !ok {
+		return
+	}
+
+	var request dto.RestockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+		return
+	}
+
+	optionList, err := h.stockUsecase.RestockMenuOptionList(menuId, optionId, choiceId, request.Quantity)
+	if err != nil {
+		responseStockError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, optionList, http.StatusOK)
+}
+
+func (h *Handler) GetLowStockReport(c *gin.Context) {
+	threshold := usecase.DefaultLowStockThreshold
+	if param := c.Query("threshold"); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil {
+			util.ResponseErrorJSON(c, domain.ErrMalformedRequest.Error(), "BAD_REQUEST", http.StatusBadRequest)
+			return
+		}
+		threshold = value
+	}
+
+	report, err := h.stockUsecase.GetLowStockReport(threshold)
+	if err != nil {
+		responseStockError(c, err)
+		return
+	}
+
+	util.ResponseSuccesJSON(c, report, http.StatusOK)
+}
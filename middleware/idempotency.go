@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/usecase"
+	"final-project-backend/util"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key header. Requests without the header pass through.
+func Idempotency(idempotencyUsecase usecase.IdempotencyUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		user := c.MustGet("user").(dto.UserResponse)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			util.ResponseErrorJSON(c, domain.ErrInvalidBody.Error(), "INVALID_BODY_REQUEST", http.StatusBadRequest)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+
+		idempotencyKey, err := idempotencyUsecase.Begin(user.ID, key, hex.EncodeToString(hash.Sum(nil)))
+		if errors.Is(err, domain.ErrInvalidIdempotencyKey) {
+			util.ResponseErrorJSON(c, domain.ErrInvalidIdempotencyKey.Error(), "INVALID_IDEMPOTENCY_KEY", http.StatusBadRequest)
+			c.Abort()
+			return
+		}
+		if errors.Is(err, domain.ErrIdempotencyKeyMismatch) {
+			util.ResponseErrorJSON(c, domain.ErrIdempotencyKeyMismatch.Error(), "IDEMPOTENCY_KEY_MISMATCH", http.StatusUnprocessableEntity)
+			c.Abort()
+			return
+		}
+		if errors.Is(err, domain.ErrIdempotencyKeyInProgress) {
+			util.ResponseErrorJSON(c, domain.ErrIdempotencyKeyInProgress.Error(), "IDEMPOTENCY_KEY_IN_PROGRESS", http.StatusConflict)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			util.ResponseErrorJSON(c, domain.ErrInternalServer.Error(), "INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+			c.Abort()
+			return
+		}
+
+		if idempotencyKey.StatusCode != 0 {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(idempotencyKey.StatusCode, "application/json; charset=utf-8", []byte(idempotencyKey.ResponseBody))
+			c.Abort()
+			return
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				completeIdempotencyKey(idempotencyUsecase, *idempotencyKey, http.StatusInternalServerError, "")
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+		c.Next()
+
+		completeIdempotencyKey(idempotencyUsecase, *idempotencyKey, recorder.Status(), recorder.body.String())
+	}
+}
+
+// completeIdempotencyKey stores the response once it has been sent. The client
+// already has the response by then, so a failure is only logged.
+func completeIdempotencyKey(idempotencyUsecase usecase.IdempotencyUsecase, idempotencyKey entity.IdempotencyKey, statusCode int, responseBody string) {
+	err := idempotencyUsecase.Complete(idempotencyKey, statusCode, responseBody)
+	if err != nil {
+		log.Printf("complete idempotency key %q of user %d: %v", idempotencyKey.Key, idempotencyKey.UserID, err)
+	}
+}
@@ -0,0 +1,196 @@
+package middleware_test
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/middleware"
+	"final-project-backend/repository"
+	"final-project-backend/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryIdempotencyRepo keeps the keys in a map. When failUpdate is set,
+// storing a response fails like a dropped database connection would, and
+// reserving a key fails with createErr when it is set.
+type memoryIdempotencyRepo struct {
+	keys       map[string]entity.IdempotencyKey
+	failUpdate bool
+	createErr  error
+}
+
+func (r *memoryIdempotencyRepo) GetIdempotencyKey(userId uint, key string) (*entity.IdempotencyKey, error) {
+	idempotencyKey, ok := r.keys[key]
+	if !ok || idempotencyKey.UserID != userId {
+		return nil, errors.New("record not found")
+	}
+
+	return &idempotencyKey, nil
+}
+
+func (r *memoryIdempotencyRepo) CreateIdempotencyKey(idempotencyKey entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	if r.createErr != nil {
+		return nil, r.createErr
+	}
+
+	r.keys[idempotencyKey.Key] = idempotencyKey
+	return &idempotencyKey, nil
+}
+
+func (r *memoryIdempotencyRepo) UpdateIdempotencyKey(idempotencyKey entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	if r.failUpdate {
+		return nil, errors.New("connection reset")
+	}
+
+	r.keys[idempotencyKey.Key] = idempotencyKey
+	return &idempotencyKey, nil
+}
+
+func (r *memoryIdempotencyRepo) DeleteIdempotencyKey(idempotencyKey entity.IdempotencyKey) error {
+	delete(r.keys, idempotencyKey.Key)
+	return nil
+}
+
+var _ repository.IdempotencyRepository = &memoryIdempotencyRepo{}
+
+// newIdempotentRouter serves POST /orders behind the middleware with handler,
+// counting how often the handler runs.
+func newIdempotentRouter(repo *memoryIdempotencyRepo, handler gin.HandlerFunc) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(usecase.IdempotencyUsecaseConfig{IdempotencyRepo: repo})
+
+	router := gin.New()
+	router.POST("/orders",
+		func(c *gin.Context) { c.Set("user", dto.UserResponse{ID: 1}) },
+		middleware.Idempotency(idempotencyUsecase),
+		func(c *gin.Context) {
+			calls++
+			handler(c)
+		},
+	)
+
+	return router, &calls
+}
+
+func postOrder(router *gin.Engine, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	repo := &memoryIdempotencyRepo{keys: map[string]entity.IdempotencyKey{}}
+	router, calls := newIdempotentRouter(repo, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	first := postOrder(router, "order-1", `{"menu_id":1}`)
+	second := postOrder(router, "order-1", `{"menu_id":1}`)
+
+	if *calls != 1 {
+		t.Errorf("expected the handler to run once, ran %d times", *calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("expected the first response to be replayed, got %d %s", second.Code, second.Body.String())
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected the replay to be flagged")
+	}
+
+	mismatch := postOrder(router, "order-1", `{"menu_id":2}`)
+	if mismatch.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected a different body to be rejected with 422, got %d", mismatch.Code)
+	}
+}
+
+func TestIdempotencyRejectsKeyInProgress(t *testing.T) {
+	repo := &memoryIdempotencyRepo{keys: map[string]entity.IdempotencyKey{}}
+	var router *gin.Engine
+	var inner *httptest.ResponseRecorder
+	router, calls := newIdempotentRouter(repo, func(c *gin.Context) {
+		// the retry arrives while the first request is still running
+		if inner == nil {
+			inner = postOrder(router, "order-1", `{}`)
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	postOrder(router, "order-1", `{}`)
+
+	if *calls != 1 {
+		t.Errorf("expected the handler to run once, ran %d times", *calls)
+	}
+	if inner == nil || inner.Code != http.StatusConflict {
+		t.Fatalf("expected the concurrent retry to get 409, got %+v", inner)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	repo := &memoryIdempotencyRepo{keys: map[string]entity.IdempotencyKey{}}
+	status := http.StatusInternalServerError
+	router, calls := newIdempotentRouter(repo, func(c *gin.Context) {
+		c.JSON(status, gin.H{})
+	})
+
+	postOrder(router, "order-1", `{}`)
+	if len(repo.keys) != 0 {
+		t.Fatalf("expected the key to be released after a 500, got %+v", repo.keys)
+	}
+
+	status = http.StatusCreated
+	retry := postOrder(router, "order-1", `{}`)
+	if *calls != 2 || retry.Code != http.StatusCreated {
+		t.Errorf("expected the retry to run the handler again, got %d calls and %d", *calls, retry.Code)
+	}
+}
+
+func TestIdempotencyReleasesKeyWhenResponseIsNotStored(t *testing.T) {
+	repo := &memoryIdempotencyRepo{keys: map[string]entity.IdempotencyKey{}, failUpdate: true}
+	router, _ := newIdempotentRouter(repo, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	rec := postOrder(router, "order-1", `{}`)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected the response to reach the client, got %d", rec.Code)
+	}
+	if len(repo.keys) != 0 {
+		t.Errorf("expected the key to be released instead of staying in progress, got %+v", repo.keys)
+	}
+}
+
+func TestIdempotencyReportsReservationErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		createErr error
+		want      int
+	}{
+		{"key reserved by a concurrent request", domain.ErrIdempotencyKeyInProgress, http.StatusConflict},
+		{"database unavailable", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryIdempotencyRepo{keys: map[string]entity.IdempotencyKey{}, createErr: tt.createErr}
+			router, calls := newIdempotentRouter(repo, func(c *gin.Context) {
+				c.JSON(http.StatusCreated, gin.H{"id": 1})
+			})
+
+			rec := postOrder(router, "order-1", `{}`)
+			if rec.Code != tt.want || *calls != 0 {
+				t.Errorf("expected %d without running the handler, got %d after %d calls", tt.want, rec.Code, *calls)
+			}
+		})
+	}
+}
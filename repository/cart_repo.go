@@ -15,6 +15,7 @@ type CartRepository interface {
 	GetCartItemById(id uint) (*entity.UserCartItem, error)
 	GetCartItemsByDto(dto.CartItemData) (*entity.UserCartItem, error)
 	EmptyCart(userId int) error
+	DeleteCartItems(userId uint, ids []uint) (int64, error)
 }
 
 type cartRepositoryImpl struct {
@@ -132,6 +133,17 @@ func (r *cartRepositoryImpl) DeleteCartItem(item entity.UserCartItem) error {
 	return nil
 }
 
+// DeleteCartItems deletes the user's cart items with the given IDs and returns
+// how many were still in the cart.
+func (r *cartRepositoryImpl) DeleteCartItems(userId uint, ids []uint) (int64, error) {
+	res := r.db.Where("user_id = ? AND id IN ?", userId, ids).Delete(&entity.UserCartItem{})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
 func (r *cartRepositoryImpl) EmptyCart(userId int) error {
 	err := r.db.Where("user_id = ?", userId).Delete(&entity.UserCartItem{}).Error
 	if err != nil {
@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"testing"
+)
+
+func TestDeleteCartItemsKeepsOtherItems(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 0)
+
+	added := entity.UserCartItem{UserID: f.user.ID, MenuID: f.menu.ID, Quantity: 1, MenuOptions: "[]"}
+	if err := db.Create(&added).Error; err != nil {
+		t.Fatalf("seed cart item: %v", err)
+	}
+
+	var checkedOut entity.UserCartItem
+	if err := db.Where("id <> ?", added.ID).First(&checkedOut).Error; err != nil {
+		t.Fatalf("find cart item: %v", err)
+	}
+
+	cartRepo := repository.NewCartRepository(repository.CartRepoConfig{DB: db})
+	deleted, err := cartRepo.DeleteCartItems(f.user.ID, []uint{checkedOut.ID})
+	if err != nil {
+		t.Fatalf("delete cart items: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 deleted item, got %d", deleted)
+	}
+	if total := count(t, db, &entity.UserCartItem{}); total != 1 {
+		t.Errorf("expected the item added during checkout to stay, got %d items", total)
+	}
+
+	deleted, err = cartRepo.DeleteCartItems(f.user.ID, []uint{checkedOut.ID})
+	if err != nil {
+		t.Fatalf("delete cart items again: %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("expected an already checked out item not to be counted, got %d", deleted)
+	}
+}
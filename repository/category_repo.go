@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	GetCategories(withMenus bool) ([]entity.Category, error)
+	GetCategoryByID(id uint) (*entity.Category, error)
+	GetCategoryBySlug(slug string) (*entity.Category, error)
+	GetCategoriesByIDs(ids []uint) ([]entity.Category, error)
+	CreateCategory(category entity.Category) (*entity.Category, error)
+	UpdateCategory(category entity.Category) (*entity.Category, error)
+	DeleteCategory(category entity.Category) error
+}
+
+type categoryRepositoryImpl struct {
+	db *gorm.DB
+}
+
+type CategoryRepoConfig struct {
+	DB *gorm.DB
+}
+
+func NewCategoryRepository(c CategoryRepoConfig) CategoryRepository {
+	return &categoryRepositoryImpl{db: c.DB}
+}
+
+func (r *categoryRepositoryImpl) GetCategories(withMenus bool) ([]entity.Category, error) {
+	var categories []entity.Category
+
+	db := r.db.Order("display_order, name, id")
+	if withMenus {
+		db = db.Preload("Menu", func(db *gorm.DB) *gorm.DB {
+			return db.Order("menus.name, menus.id")
+		})
+	}
+
+	err := db.Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (r *categoryRepositoryImpl) GetCategoryByID(id uint) (*entity.Category, error) {
+	var category entity.Category
+	err := r.db.Where("id = ?", id).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (r *categoryRepositoryImpl) GetCategoryBySlug(slug string) (*entity.Category, error) {
+	var category entity.Category
+	err := r.db.Where("slug = ?", slug).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (r *categoryRepositoryImpl) GetCategoriesByIDs(ids []uint) ([]entity.Category, error) {
+	categories := []entity.Category{}
+	if len(ids) == 0 {
+		return categories, nil
+	}
+
+	err := r.db.Where("id IN ?", ids).Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// CreateCategory returns domain.ErrCategorySlugTaken when another category
+// already has the slug.
+func (r *categoryRepositoryImpl) CreateCategory(category entity.Category) (*entity.Category, error) {
+	err := r.db.Create(&category).Error
+	if isUniqueViolation(err) {
+		return nil, domain.ErrCategorySlugTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// UpdateCategory also refreshes the search vectors of the category's menus
+// since category names are searchable. It returns domain.ErrCategorySlugTaken
+// when another category already has the slug.
+func (r *categoryRepositoryImpl) UpdateCategory(category entity.Category) (*entity.Category, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit("Menu").Save(&category).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`UPDATE menus SET search_vector = menu_search_vector(id)
+			WHERE id IN (SELECT menu_id FROM categories_menus WHERE category_id = ? AND deleted_at IS NULL)`, category.ID).Error
+	})
+	if isUniqueViolation(err) {
+		return nil, domain.ErrCategorySlugTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// DeleteCategory detaches the category from its menus before deleting it.
+func (r *categoryRepositoryImpl) DeleteCategory(category entity.Category) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var menuIds []uint
+		err := tx.Model(&entity.CategoriesMenu{}).Where("category_id = ?", category.ID).Pluck("menu_id", &menuIds).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("category_id = ?", category.ID).Unscoped().Delete(&entity.CategoriesMenu{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Delete(&category).Error
+		if err != nil {
+			return err
+		}
+
+		if len(menuIds) == 0 {
+			return nil
+		}
+
+		return tx.Exec("UPDATE menus SET search_vector = menu_search_vector(id) WHERE id IN ?", menuIds).Error
+	})
+}
@@ -0,0 +1,86 @@
+package repository_test
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"testing"
+)
+
+func TestGetCategoriesWithMenus(t *testing.T) {
+	db := setupTestDB(t)
+	drinks := entity.Category{Name: "Drinks", Slug: "drinks", DisplayOrder: 2}
+	mains := entity.Category{Name: "Mains", Slug: "mains", DisplayOrder: 1}
+	for _, category := range []*entity.Category{&drinks, &mains} {
+		if err := db.Create(category).Error; err != nil {
+			t.Fatalf("seed category: %v", err)
+		}
+	}
+
+	menu := entity.Menu{Name: "Burger", Price: 30000, MenuOptions: "[]"}
+	if err := db.Create(&menu).Error; err != nil {
+		t.Fatalf("seed menu: %v", err)
+	}
+	if err := db.Create(&entity.CategoriesMenu{CategoryID: mains.ID, MenuID: menu.ID}).Error; err != nil {
+		t.Fatalf("seed menu category: %v", err)
+	}
+
+	categoryRepo := repository.NewCategoryRepository(repository.CategoryRepoConfig{DB: db})
+	categories, err := categoryRepo.GetCategories(true)
+	if err != nil {
+		t.Fatalf("get categories: %v", err)
+	}
+
+	if len(categories) != 2 || categories[0].ID != mains.ID || categories[1].ID != drinks.ID {
+		t.Fatalf("expected categories in display order, got %+v", categories)
+	}
+	if len(categories[0].Menu) != 1 || categories[0].Menu[0].ID != menu.ID {
+		t.Errorf("expected mains to contain the burger, got %+v", categories[0].Menu)
+	}
+	if len(categories[1].Menu) != 0 {
+		t.Errorf("expected drinks to have no menus, got %+v", categories[1].Menu)
+	}
+
+	found, err := categoryRepo.GetCategoriesByIDs([]uint{mains.ID, 999})
+	if err != nil {
+		t.Fatalf("get categories by ids: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != mains.ID {
+		t.Errorf("expected only the mains category, got %+v", found)
+	}
+}
+
+func TestCategorySlugsAreUniqueAmongLiveCategories(t *testing.T) {
+	db := setupTestDB(t)
+	categoryRepo := repository.NewCategoryRepository(repository.CategoryRepoConfig{DB: db})
+
+	drinks, err := categoryRepo.CreateCategory(entity.Category{Name: "Drinks", Slug: "drinks"})
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	_, err = categoryRepo.CreateCategory(entity.Category{Name: "Beverages", Slug: "drinks"})
+	if !errors.Is(err, domain.ErrCategorySlugTaken) {
+		t.Fatalf("expected a second drinks slug to be taken, got %v", err)
+	}
+
+	mains, err := categoryRepo.CreateCategory(entity.Category{Name: "Mains", Slug: "mains"})
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	mains.Slug = "drinks"
+	_, err = categoryRepo.UpdateCategory(*mains)
+	if !errors.Is(err, domain.ErrCategorySlugTaken) {
+		t.Fatalf("expected renaming to a used slug to be taken, got %v", err)
+	}
+
+	err = categoryRepo.DeleteCategory(*drinks)
+	if err != nil {
+		t.Fatalf("delete category: %v", err)
+	}
+	_, err = categoryRepo.CreateCategory(entity.Category{Name: "Drinks", Slug: "drinks"})
+	if err != nil {
+		t.Errorf("expected a deleted category's slug to be free again, got %v", err)
+	}
+}
@@ -1,10 +1,14 @@
 package repository
 
 import (
+	"errors"
 	"final-project-backend/domain"
+	"final-project-backend/dto"
 	"final-project-backend/entity"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CouponRepository interface {
@@ -13,12 +17,23 @@ type CouponRepository interface {
 	GetUserCouponByFK(uint, uint) (*entity.UsersCoupon, error)
 	DeleteUserCoupon(entity.UsersCoupon) error
 	GetCouponsByUserId(id uint) ([]entity.UsersCoupon, error)
-	GetCoupons() ([]entity.Coupon, error)
+	GetCoupons(dto.Query) ([]entity.Coupon, int64, error)
 	GetCouponByDiscount(discount uint) (*entity.Coupon, error)
 	UpdateCoupon(entity.Coupon) (*entity.Coupon, error)
 	DeleteCoupon(entity.Coupon) error
 	AssignCouponToUser(entity.UsersCoupon) (*entity.UsersCoupon, error)
 	UpdateUserCoupon(entity.UsersCoupon) (*entity.UsersCoupon, error)
+	ConsumeUserCoupon(couponId uint, userId uint) error
+	RestoreUserCoupon(couponId uint, userId uint) error
+	CountUserCouponOrders(couponId uint, userId uint) (int64, error)
+	GetCouponByRedeemCode(code string) (*entity.Coupon, error)
+	LockCouponByRedeemCode(code string) (*entity.Coupon, error)
+	CountCouponClaims(couponId uint) (int64, error)
+	CountUserCouponClaims(couponId uint, userId uint) (int64, error)
+	CreateCouponClaim(entity.CouponClaim) error
+	AssignCouponToUsers(couponId uint, userIds []uint, quantity int) error
+	CreateCouponRedemption(entity.CouponRedemption) error
+	CancelCouponRedemption(orderId uint) error
 }
 
 type couponRepositoryImpl struct {
@@ -36,7 +51,7 @@ func NewCouponRepository(c CouponRepoConfig) CouponRepository {
 func (r *couponRepositoryImpl) GetCouponById(id uint) (*entity.Coupon, error) {
 	var coupon entity.Coupon
 
-	res := r.db.Find(&coupon, id)
+	res := r.db.Scopes(preloadCouponEligibility).Find(&coupon, id)
 
 	if res.RowsAffected == 0 {
 		return nil, domain.ErrCouponNotFound
@@ -45,14 +60,26 @@ func (r *couponRepositoryImpl) GetCouponById(id uint) (*entity.Coupon, error) {
 	return &coupon, nil
 }
 
+// CreateCoupon returns domain.ErrRedeemCodeTaken when another coupon already
+// has the redeem code.
 func (r *couponRepositoryImpl) CreateCoupon(coupon entity.Coupon) (*entity.Coupon, error) {
-	err := r.db.Create(&coupon).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Create(&coupon).Error
+		if err != nil {
+			return err
+		}
 
+		return replaceCouponEligibility(tx, coupon)
+	})
+
+	if isUniqueViolation(err) {
+		return nil, domain.ErrRedeemCodeTaken
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &coupon, nil
+	return r.GetCouponById(coupon.ID)
 }
 
 func (r *couponRepositoryImpl) AssignCouponToUser(userCoupon entity.UsersCoupon) (*entity.UsersCoupon, error) {
@@ -75,22 +102,65 @@ func (r *couponRepositoryImpl) UpdateUserCoupon(userCoupon entity.UsersCoupon) (
 	return &userCoupon, nil
 }
 
-func (r *couponRepositoryImpl) GetCoupons() ([]entity.Coupon, error) {
+// ConsumeUserCoupon takes one coupon from the user's stock with a conditional
+// update, so concurrent checkouts can never take the stock below zero. The row
+// is kept at zero stock so the user's coupon history stays intact.
+func (r *couponRepositoryImpl) ConsumeUserCoupon(couponId uint, userId uint) error {
+	res := r.db.Model(&entity.UsersCoupon{}).Where("user_id = ? AND coupon_id = ? AND stock > 0", userId, couponId).Update("stock", gorm.Expr("stock - ?", 1))
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return domain.ErrUserCouponNotFound
+	}
+
+	return nil
+}
+
+// RestoreUserCoupon gives one coupon back to the user, reviving the
+// users_coupons row if it was removed when the stock ran out.
+func (r *couponRepositoryImpl) RestoreUserCoupon(couponId uint, userId uint) error {
+	var userCoupon entity.UsersCoupon
+	err := r.db.Unscoped().Where("user_id = ? AND coupon_id = ?", userId, couponId).Order("deleted_at IS NOT NULL, id desc").First(&userCoupon).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.db.Create(&entity.UsersCoupon{UserID: userId, CouponID: couponId, Stock: 1}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	if userCoupon.DeletedAt.Valid {
+		userCoupon.DeletedAt = gorm.DeletedAt{}
+		userCoupon.Stock = 0
+	}
+	userCoupon.Stock += 1
+
+	return r.db.Unscoped().Save(&userCoupon).Error
+}
+
+func (r *couponRepositoryImpl) GetCoupons(query dto.Query) ([]entity.Coupon, int64, error) {
 	var coupons []entity.Coupon
+	var total int64
+
+	err := r.db.Model(&entity.Coupon{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
 
-	err := r.db.Find(&coupons).Error
+	err = r.db.Order("id").Scopes(preloadCouponEligibility, paginate(query)).Find(&coupons).Error
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return coupons, nil
+	return coupons, total, nil
 }
 
 func (r *couponRepositoryImpl) GetCouponsByUserId(id uint) ([]entity.UsersCoupon, error) {
 	var coupons []entity.UsersCoupon
 
-	err := r.db.Preload("Coupon").Where("user_id = ?", id).Find(&coupons).Error
+	err := r.db.Preload("Coupon").Where("user_id = ? AND stock > 0", id).Find(&coupons).Error
 
 	if err != nil {
 		return nil, err
@@ -133,14 +203,27 @@ func (r *couponRepositoryImpl) DeleteUserCoupon(userCoupon entity.UsersCoupon) e
 	return nil
 }
 
+// UpdateCoupon saves the coupon and replaces its eligible categories and
+// menus; the categories and menus themselves are never written. It returns
+// domain.ErrRedeemCodeTaken when another coupon already has the redeem code.
 func (r *couponRepositoryImpl) UpdateCoupon(coupon entity.Coupon) (*entity.Coupon, error) {
-	err := r.db.Save(&coupon).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Save(&coupon).Error
+		if err != nil {
+			return err
+		}
 
+		return replaceCouponEligibility(tx, coupon)
+	})
+
+	if isUniqueViolation(err) {
+		return nil, domain.ErrRedeemCodeTaken
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &coupon, nil
+	return r.GetCouponById(coupon.ID)
 }
 
 func (r *couponRepositoryImpl) DeleteCoupon(coupon entity.Coupon) error {
@@ -152,3 +235,155 @@ func (r *couponRepositoryImpl) DeleteCoupon(coupon entity.Coupon) error {
 
 	return nil
 }
+
+// CountUserCouponOrders counts the orders the user placed with the coupon
+// that were not cancelled.
+func (r *couponRepositoryImpl) CountUserCouponOrders(couponId uint, userId uint) (int64, error) {
+	var total int64
+	err := r.db.Model(&entity.Order{}).Where("coupon_id = ? AND user_id = ? AND status <> ?", couponId, userId, "cancelled").
+		Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *couponRepositoryImpl) GetCouponByRedeemCode(code string) (*entity.Coupon, error) {
+	var coupon entity.Coupon
+
+	res := r.db.Where("redeem_code = ?", code).Find(&coupon)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return nil, domain.ErrRedeemCodeNotFound
+	}
+
+	return &coupon, nil
+}
+
+// LockCouponByRedeemCode finds the coupon and locks its row until the
+// transaction ends, so concurrent claims are counted one at a time.
+func (r *couponRepositoryImpl) LockCouponByRedeemCode(code string) (*entity.Coupon, error) {
+	var coupon entity.Coupon
+
+	res := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("redeem_code = ?", code).Find(&coupon)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return nil, domain.ErrRedeemCodeNotFound
+	}
+
+	return &coupon, nil
+}
+
+func (r *couponRepositoryImpl) CountCouponClaims(couponId uint) (int64, error) {
+	var total int64
+	err := r.db.Model(&entity.CouponClaim{}).Where("coupon_id = ?", couponId).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *couponRepositoryImpl) CountUserCouponClaims(couponId uint, userId uint) (int64, error) {
+	var total int64
+	err := r.db.Model(&entity.CouponClaim{}).Where("coupon_id = ? AND user_id = ?", couponId, userId).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *couponRepositoryImpl) CreateCouponClaim(claim entity.CouponClaim) error {
+	return r.db.Create(&claim).Error
+}
+
+// AssignCouponToUsers adds quantity to every user's stock of the coupon,
+// creating the users_coupons rows of users who do not hold it yet.
+func (r *couponRepositoryImpl) AssignCouponToUsers(couponId uint, userIds []uint, quantity int) error {
+	var holderIds []uint
+	err := r.db.Model(&entity.UsersCoupon{}).Where("coupon_id = ? AND user_id IN ?", couponId, userIds).Pluck("user_id", &holderIds).Error
+	if err != nil {
+		return err
+	}
+
+	if len(holderIds) > 0 {
+		err = r.db.Model(&entity.UsersCoupon{}).Where("coupon_id = ? AND user_id IN ?", couponId, holderIds).
+			Update("stock", gorm.Expr("stock + ?", quantity)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	holders := map[uint]bool{}
+	for _, userId := range holderIds {
+		holders[userId] = true
+	}
+
+	var userCoupons []entity.UsersCoupon
+	for _, userId := range userIds {
+		if !holders[userId] {
+			userCoupons = append(userCoupons, entity.UsersCoupon{UserID: userId, CouponID: couponId, Stock: quantity})
+		}
+	}
+	if len(userCoupons) == 0 {
+		return nil
+	}
+
+	return r.db.Omit(clause.Associations).Create(&userCoupons).Error
+}
+
+func (r *couponRepositoryImpl) CreateCouponRedemption(redemption entity.CouponRedemption) error {
+	return r.db.Create(&redemption).Error
+}
+
+// CancelCouponRedemption marks the redemption of the order's coupon as
+// cancelled, keeping it in the ledger.
+func (r *couponRepositoryImpl) CancelCouponRedemption(orderId uint) error {
+	return r.db.Model(&entity.CouponRedemption{}).Where("order_id = ? AND cancelled_at IS NULL", orderId).
+		Update("cancelled_at", time.Now()).Error
+}
+
+func preloadCouponEligibility(db *gorm.DB) *gorm.DB {
+	return db.Preload("EligibleCategories").Preload("EligibleMenus")
+}
+
+func replaceCouponEligibility(tx *gorm.DB, coupon entity.Coupon) error {
+	err := tx.Where("coupon_id = ?", coupon.ID).Delete(&entity.CouponCategory{}).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Where("coupon_id = ?", coupon.ID).Delete(&entity.CouponMenu{}).Error
+	if err != nil {
+		return err
+	}
+
+	var couponCategories []entity.CouponCategory
+	for _, category := range coupon.EligibleCategories {
+		couponCategories = append(couponCategories, entity.CouponCategory{CouponID: coupon.ID, CategoryID: category.ID})
+	}
+	if len(couponCategories) > 0 {
+		err = tx.Create(&couponCategories).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var couponMenus []entity.CouponMenu
+	for _, menu := range coupon.EligibleMenus {
+		couponMenus = append(couponMenus, entity.CouponMenu{CouponID: coupon.ID, MenuID: menu.ID})
+	}
+	if len(couponMenus) > 0 {
+		return tx.Create(&couponMenus).Error
+	}
+
+	return nil
+}
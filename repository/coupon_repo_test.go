@@ -0,0 +1,255 @@
+package repository_test
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"testing"
+	"time"
+)
+
+func TestUpdateCouponReplacesEligibility(t *testing.T) {
+	db := setupTestDB(t)
+	stock := 4
+	menu := entity.Menu{Name: "Burger", Price: 30000, MenuOptions: "[]", Stock: &stock}
+	category := entity.Category{Name: "Mains", Slug: "mains"}
+	for _, value := range []interface{}{&menu, &category} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	couponRepo := repository.NewCouponRepository(repository.CouponRepoConfig{DB: db})
+	coupon, err := couponRepo.CreateCoupon(entity.Coupon{
+		Description:   "burger deal",
+		Type:          entity.CouponTypePercentage,
+		Discount:      10,
+		Availability:  true,
+		EligibleMenus: []entity.Menu{{Model: menu.Model}},
+	})
+	if err != nil {
+		t.Fatalf("create coupon: %v", err)
+	}
+	if len(coupon.EligibleMenus) != 1 || len(coupon.EligibleCategories) != 0 {
+		t.Fatalf("expected only the burger to be eligible, got %+v", coupon)
+	}
+
+	coupon.EligibleMenus = nil
+	coupon.EligibleCategories = []entity.Category{category}
+	coupon, err = couponRepo.UpdateCoupon(*coupon)
+	if err != nil {
+		t.Fatalf("update coupon: %v", err)
+	}
+	if len(coupon.EligibleMenus) != 0 || len(coupon.EligibleCategories) != 1 {
+		t.Errorf("expected only the mains category to be eligible, got %+v", coupon)
+	}
+
+	if stored := menuStock(t, db, menu.ID); stored == nil || *stored != stock {
+		t.Errorf("expected the menu to be left alone, got stock %v", stored)
+	}
+}
+
+func TestCountUserCouponOrdersSkipsCancelled(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 1)
+
+	for _, status := range []string{"placed", "delivered", "cancelled"} {
+		order := f.order
+		order.Status = status
+		if err := db.Create(&order).Error; err != nil {
+			t.Fatalf("seed order: %v", err)
+		}
+	}
+
+	couponRepo := repository.NewCouponRepository(repository.CouponRepoConfig{DB: db})
+	used, err := couponRepo.CountUserCouponOrders(f.coupon.ID, f.user.ID)
+	if err != nil {
+		t.Fatalf("count coupon orders: %v", err)
+	}
+	if used != 2 {
+		t.Errorf("expected 2 orders using the coupon, got %d", used)
+	}
+}
+
+func TestCouponClaimsAreCountedPerCouponAndUser(t *testing.T) {
+	db := setupTestDB(t)
+	couponRepo := repository.NewCouponRepository(repository.CouponRepoConfig{DB: db})
+
+	coupon, err := couponRepo.CreateCoupon(entity.Coupon{
+		Description:     "ramadan",
+		Type:            entity.CouponTypeFixed,
+		Discount:        5000,
+		Availability:    true,
+		RedeemCode:      "RAMADAN25",
+		RedemptionLimit: 10,
+	})
+	if err != nil {
+		t.Fatalf("create coupon: %v", err)
+	}
+
+	unitOfWork := repository.NewUnitOfWork(repository.UnitOfWorkConfig{DB: db})
+	for _, userId := range []uint{1, 1, 2} {
+		err = unitOfWork.Do(func(repos repository.TxRepositories) error {
+			locked, err := repos.CouponRepo.LockCouponByRedeemCode("RAMADAN25")
+			if err != nil {
+				return err
+			}
+
+			return repos.CouponRepo.CreateCouponClaim(entity.CouponClaim{CouponID: locked.ID, UserID: userId})
+		})
+		if err != nil {
+			t.Fatalf("claim coupon: %v", err)
+		}
+	}
+
+	claimed, err := couponRepo.CountCouponClaims(coupon.ID)
+	if err != nil || claimed != 3 {
+		t.Errorf("expected 3 claims, got %d (%v)", claimed, err)
+	}
+
+	claimed, err = couponRepo.CountUserCouponClaims(coupon.ID, 1)
+	if err != nil || claimed != 2 {
+		t.Errorf("expected 2 claims by user 1, got %d (%v)", claimed, err)
+	}
+
+	_, err = couponRepo.GetCouponByRedeemCode("UNKNOWN1")
+	if !errors.Is(err, domain.ErrRedeemCodeNotFound) {
+		t.Errorf("expected ErrRedeemCodeNotFound, got %v", err)
+	}
+}
+
+func TestAssignCouponToUsersAddsStock(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 2)
+
+	other := entity.User{Username: "second_user", RoleID: f.user.RoleID}
+	if err := db.Create(&other).Error; err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	couponRepo := repository.NewCouponRepository(repository.CouponRepoConfig{DB: db})
+	err := couponRepo.AssignCouponToUsers(f.coupon.ID, []uint{f.user.ID, other.ID}, 3)
+	if err != nil {
+		t.Fatalf("assign coupon: %v", err)
+	}
+
+	for userId, want := range map[uint]int{f.user.ID: 5, other.ID: 3} {
+		userCoupon, err := couponRepo.GetUserCouponByFK(f.coupon.ID, userId)
+		if err != nil {
+			t.Fatalf("get user coupon: %v", err)
+		}
+		if userCoupon.Stock != want {
+			t.Errorf("expected user %d to hold %d coupons, got %d", userId, want, userCoupon.Stock)
+		}
+	}
+}
+
+func TestGetUserIDsBySegment(t *testing.T) {
+	db := setupTestDB(t)
+
+	customer := entity.Role{Name: "user"}
+	admin := entity.Role{Name: "admin"}
+	menu := entity.Menu{Name: "Burger", Price: 30000, MenuOptions: "[]"}
+	for _, value := range []interface{}{&customer, &admin, &menu} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	now := time.Now()
+	users := []entity.User{
+		{Username: "old_fan", RoleID: customer.ID, RegisteredAt: now.AddDate(0, -2, 0)},
+		{Username: "new_fan", RoleID: customer.ID, RegisteredAt: now},
+		{Username: "new_user", RoleID: customer.ID, RegisteredAt: now},
+		{Username: "new_admin", RoleID: admin.ID, RegisteredAt: now},
+	}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatalf("seed users: %v", err)
+	}
+	for _, user := range []entity.User{users[0], users[1], users[3]} {
+		if err := db.Create(&entity.MenuFavorite{MenuID: menu.ID, UserID: user.ID}).Error; err != nil {
+			t.Fatalf("seed favorite: %v", err)
+		}
+	}
+
+	userRepo := repository.NewUserRepository(repository.UserRepoConfig{DB: db})
+	registeredAfter := now.AddDate(0, -1, 0)
+	ids, err := userRepo.GetUserIDsBySegment(dto.UserSegment{RegisteredAfter: &registeredAfter, FavoriteMenuID: menu.ID})
+	if err != nil {
+		t.Fatalf("get segment: %v", err)
+	}
+
+	if len(ids) != 1 || ids[0] != users[1].ID {
+		t.Errorf("expected only new_fan in the segment, got %v", ids)
+	}
+}
+
+func TestConsumeUserCouponKeepsEmptyStock(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 1)
+
+	couponRepo := repository.NewCouponRepository(repository.CouponRepoConfig{DB: db})
+	if err := couponRepo.ConsumeUserCoupon(f.coupon.ID, f.user.ID); err != nil {
+		t.Fatalf("consume coupon: %v", err)
+	}
+
+	userCoupon, err := couponRepo.GetUserCouponByFK(f.coupon.ID, f.user.ID)
+	if err != nil {
+		t.Fatalf("expected the user coupon to be kept: %v", err)
+	}
+	if userCoupon.Stock != 0 {
+		t.Errorf("expected coupon stock 0, got %d", userCoupon.Stock)
+	}
+
+	userCoupons, err := couponRepo.GetCouponsByUserId(f.user.ID)
+	if err != nil {
+		t.Fatalf("get user coupons: %v", err)
+	}
+	if len(userCoupons) != 0 {
+		t.Errorf("expected used up coupons to be hidden, got %d", len(userCoupons))
+	}
+}
+
+func TestRedeemCodesAreUniqueAmongLiveCoupons(t *testing.T) {
+	db := setupTestDB(t)
+	couponRepo := repository.NewCouponRepository(repository.CouponRepoConfig{DB: db})
+
+	newCoupon := func(redeemCode string) entity.Coupon {
+		return entity.Coupon{Description: "deal", Type: entity.CouponTypeFixed, Discount: 5000, Availability: true, RedeemCode: redeemCode}
+	}
+
+	weekend, err := couponRepo.CreateCoupon(newCoupon("WEEKEND"))
+	if err != nil {
+		t.Fatalf("create coupon: %v", err)
+	}
+	_, err = couponRepo.CreateCoupon(newCoupon("WEEKEND"))
+	if !errors.Is(err, domain.ErrRedeemCodeTaken) {
+		t.Fatalf("expected a second WEEKEND code to be taken, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err = couponRepo.CreateCoupon(newCoupon("")); err != nil {
+			t.Fatalf("expected coupons without a code not to clash, got %v", err)
+		}
+	}
+
+	other, err := couponRepo.CreateCoupon(newCoupon("HOLIDAY"))
+	if err != nil {
+		t.Fatalf("create coupon: %v", err)
+	}
+	other.RedeemCode = "WEEKEND"
+	_, err = couponRepo.UpdateCoupon(*other)
+	if !errors.Is(err, domain.ErrRedeemCodeTaken) {
+		t.Fatalf("expected changing to a used code to be taken, got %v", err)
+	}
+
+	err = couponRepo.DeleteCoupon(*weekend)
+	if err != nil {
+		t.Fatalf("delete coupon: %v", err)
+	}
+	if _, err = couponRepo.CreateCoupon(newCoupon("WEEKEND")); err != nil {
+		t.Errorf("expected a deleted coupon's code to be free again, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+
+	"gorm.io/gorm"
+)
+
+// CouponReportRepository reads the coupon redemption ledger. Cancelled
+// redemptions are listed but never counted in the totals.
+type CouponReportRepository interface {
+	GetCouponRedemptions(couponId uint, query dto.Query) ([]entity.CouponRedemption, int64, error)
+	GetCouponRedemptionSummaries(query dto.Query) ([]dto.CouponRedemptionSummary, error)
+	GetCouponDiscountPeriods(period string, query dto.Query) ([]dto.CouponDiscountPeriod, error)
+}
+
+type couponReportRepositoryImpl struct {
+	db *gorm.DB
+}
+
+type CouponReportRepoConfig struct {
+	DB *gorm.DB
+}
+
+func NewCouponReportRepository(c CouponReportRepoConfig) CouponReportRepository {
+	return &couponReportRepositoryImpl{db: c.DB}
+}
+
+func (r *couponReportRepositoryImpl) GetCouponRedemptions(couponId uint, query dto.Query) ([]entity.CouponRedemption, int64, error) {
+	var redemptions []entity.CouponRedemption
+	var total int64
+
+	db, err := couponRedemptionQuerySpec.apply(r.db.Model(&entity.CouponRedemption{}).Where("coupon_id = ?", couponId), query)
+	if err != nil {
+		return nil, 0, err
+	}
+	db = db.Session(&gorm.Session{})
+
+	err = db.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = db.Scopes(paginate(query)).Find(&redemptions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return redemptions, total, nil
+}
+
+func (r *couponReportRepositoryImpl) GetCouponRedemptionSummaries(query dto.Query) ([]dto.CouponRedemptionSummary, error) {
+	summaries := []dto.CouponRedemptionSummary{}
+
+	db, err := r.redeemed(query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Joins("JOIN coupons ON coupons.id = coupon_redemptions.coupon_id").
+		Select(`coupons.id AS coupon_id, coupons.description, coupons.type,
+			count(*) AS redemptions,
+			count(DISTINCT coupon_redemptions.user_id) AS users,
+			sum(coupon_redemptions.discount + coupon_redemptions.delivery_discount) AS discount_given,
+			sum(coupon_redemptions.order_total) AS order_value`).
+		Group("coupons.id, coupons.description, coupons.type").
+		Order("discount_given DESC, coupons.id").
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// GetCouponDiscountPeriods totals the redemptions per day, week or month.
+// period must be one Postgres date_trunc accepts.
+func (r *couponReportRepositoryImpl) GetCouponDiscountPeriods(period string, query dto.Query) ([]dto.CouponDiscountPeriod, error) {
+	periods := []dto.CouponDiscountPeriod{}
+
+	db, err := r.redeemed(query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Select(`date_trunc(?, coupon_redemptions.redeemed_at) AS period,
+			count(*) AS redemptions,
+			sum(coupon_redemptions.discount + coupon_redemptions.delivery_discount) AS discount_given,
+			sum(coupon_redemptions.order_total) AS order_value`, period).
+		Group("period").
+		Order("period").
+		Scan(&periods).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return periods, nil
+}
+
+// redeemed selects the redemptions within the query's dates that were not
+// cancelled.
+func (r *couponReportRepositoryImpl) redeemed(query dto.Query) (*gorm.DB, error) {
+	db := r.db.Model(&entity.CouponRedemption{}).Where("coupon_redemptions.cancelled_at IS NULL")
+	return dateRangeFilter("coupon_redemptions.redeemed_at")(db, query)
+}
@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"testing"
+	"time"
+)
+
+func TestCouponReportsSkipCancelledRedemptions(t *testing.T) {
+	db := setupTestDB(t)
+	coupon := entity.Coupon{Description: "ramadan", Type: entity.CouponTypeFixed, Discount: 5000, Availability: true}
+	if err := db.Create(&coupon).Error; err != nil {
+		t.Fatalf("seed coupon: %v", err)
+	}
+
+	day := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	cancelledAt := day
+	redemptions := []entity.CouponRedemption{
+		{CouponID: coupon.ID, UserID: 1, OrderID: 1, Discount: 5000, OrderTotal: 20000, RedeemedAt: day},
+		{CouponID: coupon.ID, UserID: 1, OrderID: 2, Discount: 5000, DeliveryDiscount: 8000, OrderTotal: 30000, RedeemedAt: day},
+		{CouponID: coupon.ID, UserID: 2, OrderID: 3, Discount: 5000, OrderTotal: 15000, RedeemedAt: day.AddDate(0, 0, 1)},
+		{CouponID: coupon.ID, UserID: 3, OrderID: 4, Discount: 5000, OrderTotal: 99000, RedeemedAt: day, CancelledAt: &cancelledAt},
+	}
+	if err := db.Create(&redemptions).Error; err != nil {
+		t.Fatalf("seed redemptions: %v", err)
+	}
+
+	reportRepo := repository.NewCouponReportRepository(repository.CouponReportRepoConfig{DB: db})
+	summaries, err := reportRepo.GetCouponRedemptionSummaries(dto.Query{})
+	if err != nil {
+		t.Fatalf("get summaries: %v", err)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("expected one coupon, got %d", len(summaries))
+	}
+
+	summary := summaries[0]
+	if summary.Redemptions != 3 || summary.Users != 2 || summary.DiscountGiven != 23000 || summary.OrderValue != 65000 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	periods, err := reportRepo.GetCouponDiscountPeriods("day", dto.Query{StartDate: "2023-03-01", EndDate: "2023-03-01"})
+	if err != nil {
+		t.Fatalf("get periods: %v", err)
+	}
+	if len(periods) != 1 || periods[0].Redemptions != 2 || periods[0].DiscountGiven != 18000 {
+		t.Errorf("unexpected periods %+v", periods)
+	}
+
+	listed, total, err := reportRepo.GetCouponRedemptions(coupon.ID, dto.Query{})
+	if err != nil {
+		t.Fatalf("get redemptions: %v", err)
+	}
+	if total != 4 || len(listed) != 4 {
+		t.Errorf("expected every redemption to be listed, got %d of %d", len(listed), total)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 type DeliveryRepository interface {
 	GetDeliveryByID(id uint) (*entity.Delivery, error)
+	GetDeliveryByOrderID(orderId uint) (*entity.Delivery, error)
 	CreateDelivery(entity.Delivery) (*entity.Delivery, error)
 	UpdateDeliveryStatus(entity.Delivery) (*entity.Delivery, error)
 }
@@ -44,8 +45,19 @@ func (r *deliveryRepositoryImpl) GetDeliveryByID(id uint) (*entity.Delivery, err
 	return &delivery, nil
 }
 
+func (r *deliveryRepositoryImpl) GetDeliveryByOrderID(orderId uint) (*entity.Delivery, error) {
+	var delivery entity.Delivery
+	err := r.db.Where("order_id = ?", orderId).First(&delivery).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
 func (r *deliveryRepositoryImpl) UpdateDeliveryStatus(delivery entity.Delivery) (*entity.Delivery, error) {
-	err := r.db.Model(&delivery).Update("status", delivery.Status).Error
+	err := r.db.Model(&delivery).Select("status", "delivery_date").Updates(&delivery).Error
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgconn"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	duplicate := &pgconn.PgError{Code: "23505", ConstraintName: "idx_categories_slug"}
+
+	if !isUniqueViolation(duplicate) || !isUniqueViolation(fmt.Errorf("save category: %w", duplicate)) {
+		t.Error("expected a duplicate key to be a unique violation")
+	}
+	if isUniqueViolation(&pgconn.PgError{Code: "23503"}) || isUniqueViolation(errors.New("duplicate")) || isUniqueViolation(nil) {
+		t.Error("expected other errors not to be unique violations")
+	}
+}
@@ -15,7 +15,7 @@ type GameRepository interface {
 	GetGameByID(uint) (*entity.Game, error)
 	AnswerGameQuestion(entity.Game) (*entity.Game, error)
 	GetGameLeaderboard(uint) (*entity.GameLeaderboard, error)
-	GetGamesLeaderboard() ([]entity.GameLeaderboard, error)
+	GetGamesLeaderboard(dto.Query) ([]entity.GameLeaderboard, int64, error)
 	CreateGameLeaderboard(entity.GameLeaderboard) (*entity.GameLeaderboard, error)
 	UpdateGameLeaderboard(entity.GameLeaderboard) (*entity.GameLeaderboard, error)
 }
@@ -89,15 +89,21 @@ func (r *gameRepositoryImpl) GetGameLeaderboard(userId uint) (*entity.GameLeader
 	return gameLeaderboard, nil
 }
 
-func (r *gameRepositoryImpl) GetGamesLeaderboard() ([]entity.GameLeaderboard, error) {
+func (r *gameRepositoryImpl) GetGamesLeaderboard(query dto.Query) ([]entity.GameLeaderboard, int64, error) {
 	var gameLeaderboards []entity.GameLeaderboard
+	var total int64
 
-	err := r.db.Preload("User").Order("accumulated_score desc").Find(&gameLeaderboards).Error
+	err := r.db.Model(&entity.GameLeaderboard{}).Count(&total).Error
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	err = r.db.Preload("User").Order("accumulated_score desc, id").Scopes(paginate(query)).Find(&gameLeaderboards).Error
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return gameLeaderboards, nil
+	return gameLeaderboards, total, nil
 }
 
 func (r *gameRepositoryImpl) CreateGameLeaderboard(gameLeaderboard entity.GameLeaderboard) (*entity.GameLeaderboard, error) {
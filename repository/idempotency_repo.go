@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+
+	"gorm.io/gorm"
+)
+
+type IdempotencyRepository interface {
+	GetIdempotencyKey(userId uint, key string) (*entity.IdempotencyKey, error)
+	CreateIdempotencyKey(entity.IdempotencyKey) (*entity.IdempotencyKey, error)
+	UpdateIdempotencyKey(entity.IdempotencyKey) (*entity.IdempotencyKey, error)
+	DeleteIdempotencyKey(entity.IdempotencyKey) error
+}
+
+type idempotencyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+type IdempotencyRepoConfig struct {
+	DB *gorm.DB
+}
+
+func NewIdempotencyRepository(c IdempotencyRepoConfig) IdempotencyRepository {
+	return &idempotencyRepositoryImpl{db: c.DB}
+}
+
+func (r *idempotencyRepositoryImpl) GetIdempotencyKey(userId uint, key string) (*entity.IdempotencyKey, error) {
+	var idempotencyKey entity.IdempotencyKey
+	err := r.db.Where("user_id = ? AND key = ?", userId, key).First(&idempotencyKey).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
+
+// CreateIdempotencyKey reserves the key, returning
+// ErrIdempotencyKeyInProgress when the user already holds it.
+func (r *idempotencyRepositoryImpl) CreateIdempotencyKey(idempotencyKey entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	err := r.db.Create(&idempotencyKey).Error
+	if isUniqueViolation(err) {
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
+
+func (r *idempotencyRepositoryImpl) UpdateIdempotencyKey(idempotencyKey entity.IdempotencyKey) (*entity.IdempotencyKey, error) {
+	err := r.db.Save(&idempotencyKey).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
+
+// DeleteIdempotencyKey removes the row permanently so the same key can be
+// reserved again by a retry.
+func (r *idempotencyRepositoryImpl) DeleteIdempotencyKey(idempotencyKey entity.IdempotencyKey) error {
+	err := r.db.Unscoped().Delete(&idempotencyKey).Error
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"encoding/json"
+	"final-project-backend/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MenuOptionRepository interface {
+	GetMenuOptionsByMenuID(menuId uint) ([]entity.MenuOption, error)
+	GetMenuOptionByID(id uint) (*entity.MenuOption, error)
+	CreateMenuOption(option entity.MenuOption) (*entity.MenuOption, error)
+	UpdateMenuOption(option entity.MenuOption) (*entity.MenuOption, error)
+	DeleteMenuOption(option entity.MenuOption) error
+	GetMenuOptionListByID(id uint) (*entity.MenuOptionList, error)
+	CreateMenuOptionList(optionList entity.MenuOptionList) (*entity.MenuOptionList, error)
+	UpdateMenuOptionList(optionList entity.MenuOptionList) (*entity.MenuOptionList, error)
+	DeleteMenuOptionList(optionList entity.MenuOptionList) error
+	SaveMenuOptions(menuId uint, options []entity.MenuOption) ([]entity.MenuOption, error)
+}
+
+type menuOptionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+type MenuOptionRepoConfig struct {
+	DB *gorm.DB
+}
+
+func NewMenuOptionRepository(c MenuOptionRepoConfig) MenuOptionRepository {
+	return &menuOptionRepositoryImpl{db: c.DB}
+}
+
+func orderMenuOptionLists(db *gorm.DB) *gorm.DB {
+	return db.Order("menu_option_lists.id")
+}
+
+func preloadMenuOptionLists(db *gorm.DB) *gorm.DB {
+	return db.Preload("MenuOptionLists", orderMenuOptionLists)
+}
+
+// preloadOptionGroups loads a menu's option groups with their choices, both
+// in creation order.
+func preloadOptionGroups(db *gorm.DB) *gorm.DB {
+	return db.Preload("OptionGroups", func(db *gorm.DB) *gorm.DB {
+		return db.Order("menu_options.id")
+	}).Preload("OptionGroups.MenuOptionLists", orderMenuOptionLists)
+}
+
+func (r *menuOptionRepositoryImpl) GetMenuOptionsByMenuID(menuId uint) ([]entity.MenuOption, error) {
+	return getMenuOptionsByMenuID(r.db, menuId)
+}
+
+func getMenuOptionsByMenuID(db *gorm.DB, menuId uint) ([]entity.MenuOption, error) {
+	var options []entity.MenuOption
+	err := preloadMenuOptionLists(db).Where("menu_id = ?", menuId).Order("id").Find(&options).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+func (r *menuOptionRepositoryImpl) GetMenuOptionByID(id uint) (*entity.MenuOption, error) {
+	var option entity.MenuOption
+	err := preloadMenuOptionLists(r.db).First(&option, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &option, nil
+}
+
+func (r *menuOptionRepositoryImpl) CreateMenuOption(option entity.MenuOption) (*entity.MenuOption, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&option).Error
+		if err != nil {
+			return err
+		}
+
+		return syncMenuOptionsSnapshot(tx, option.MenuID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &option, nil
+}
+
+// UpdateMenuOption saves the group's own fields; its choices are changed
+// through the choice methods.
+func (r *menuOptionRepositoryImpl) UpdateMenuOption(option entity.MenuOption) (*entity.MenuOption, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Save(&option).Error
+		if err != nil {
+			return err
+		}
+
+		return syncMenuOptionsSnapshot(tx, option.MenuID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetMenuOptionByID(option.ID)
+}
+
+func (r *menuOptionRepositoryImpl) DeleteMenuOption(option entity.MenuOption) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("menu_option_id = ?", option.ID).Delete(&entity.MenuOptionList{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Delete(&option).Error
+		if err != nil {
+			return err
+		}
+
+		return syncMenuOptionsSnapshot(tx, option.MenuID)
+	})
+}
+
+func (r *menuOptionRepositoryImpl) GetMenuOptionListByID(id uint) (*entity.MenuOptionList, error) {
+	var optionList entity.MenuOptionList
+	err := r.db.First(&optionList, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &optionList, nil
+}
+
+func (r *menuOptionRepositoryImpl) CreateMenuOptionList(optionList entity.MenuOptionList) (*entity.MenuOptionList, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&optionList).Error
+		if err != nil {
+			return err
+		}
+
+		return syncMenuOptionListSnapshot(tx, optionList)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &optionList, nil
+}
+
+func (r *menuOptionRepositoryImpl) UpdateMenuOptionList(optionList entity.MenuOptionList) (*entity.MenuOptionList, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit("Stock").Save(&optionList).Error
+		if err != nil {
+			return err
+		}
+
+		return syncMenuOptionListSnapshot(tx, optionList)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &optionList, nil
+}
+
+func (r *menuOptionRepositoryImpl) DeleteMenuOptionList(optionList entity.MenuOptionList) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Delete(&optionList).Error
+		if err != nil {
+			return err
+		}
+
+		return syncMenuOptionListSnapshot(tx, optionList)
+	})
+}
+
+// SaveMenuOptions makes the given option groups, as submitted by the menu
+// form, the menu's only ones. Groups and choices sent with the ID of one the
+// menu has are updated in place, keeping their IDs and stock, others are
+// created, and the stored ones left out are deleted.
+func (r *menuOptionRepositoryImpl) SaveMenuOptions(menuId uint, options []entity.MenuOption) ([]entity.MenuOption, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		stored, err := getMenuOptionsByMenuID(tx, menuId)
+		if err != nil {
+			return err
+		}
+
+		storedLists := map[uint]map[uint]bool{}
+		for _, option := range stored {
+			storedLists[option.ID] = map[uint]bool{}
+			for _, optionList := range option.MenuOptionLists {
+				storedLists[option.ID][optionList.ID] = true
+			}
+		}
+
+		kept := map[uint]bool{}
+		keptLists := map[uint]bool{}
+		for _, option := range options {
+			option.MenuID = menuId
+			if _, ok := storedLists[option.ID]; !ok || kept[option.ID] {
+				option.ID = 0
+				err = tx.Omit(clause.Associations).Create(&option).Error
+			} else {
+				err = tx.Model(&entity.MenuOption{}).Where("id = ?", option.ID).
+					Select("title", "type", "min", "max", "available", "updated_at").Updates(&option).Error
+			}
+			if err != nil {
+				return err
+			}
+			kept[option.ID] = true
+
+			for _, optionList := range option.MenuOptionLists {
+				optionList.MenuOptionID = option.ID
+				if !storedLists[option.ID][optionList.ID] || keptLists[optionList.ID] {
+					optionList.ID = 0
+					err = tx.Create(&optionList).Error
+				} else {
+					err = tx.Model(&entity.MenuOptionList{}).Where("id = ?", optionList.ID).
+						Select("name", "price", "description", "available", "updated_at").Updates(&optionList).Error
+				}
+				if err != nil {
+					return err
+				}
+				keptLists[optionList.ID] = true
+			}
+		}
+
+		var removedLists []uint
+		var removed []uint
+		for _, option := range stored {
+			if !kept[option.ID] {
+				removed = append(removed, option.ID)
+			}
+			for _, optionList := range option.MenuOptionLists {
+				if !keptLists[optionList.ID] {
+					removedLists = append(removedLists, optionList.ID)
+				}
+			}
+		}
+
+		if len(removedLists) > 0 {
+			err = tx.Delete(&entity.MenuOptionList{}, removedLists).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(removed) > 0 {
+			err = tx.Delete(&entity.MenuOption{}, removed).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return syncMenuOptionsSnapshot(tx, menuId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetMenuOptionsByMenuID(menuId)
+}
+
+func syncMenuOptionListSnapshot(tx *gorm.DB, optionList entity.MenuOptionList) error {
+	var option entity.MenuOption
+	err := tx.Unscoped().Select("menu_id").First(&option, optionList.MenuOptionID).Error
+	if err != nil {
+		return err
+	}
+
+	return syncMenuOptionsSnapshot(tx, option.MenuID)
+}
+
+// syncMenuOptionsSnapshot rewrites menus.menu_options from the option tables,
+// keeping the JSON column readable for clients that still use it.
+func syncMenuOptionsSnapshot(tx *gorm.DB, menuId uint) error {
+	options, err := getMenuOptionsByMenuID(tx, menuId)
+	if err != nil {
+		return err
+	}
+
+	if options == nil {
+		options = []entity.MenuOption{}
+	}
+
+	// stock counts change with every order and are read from the table
+	for i := range options {
+		for j := range options[i].MenuOptionLists {
+			options[i].MenuOptionLists[j].Stock = nil
+		}
+	}
+
+	snapshot, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&entity.Menu{}).Where("id = ?", menuId).Update("menu_options", string(snapshot)).Error
+}
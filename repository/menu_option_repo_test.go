@@ -0,0 +1,144 @@
+package repository_test
+
+import (
+	"encoding/json"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"testing"
+)
+
+func TestSaveMenuOptionsSyncsSnapshot(t *testing.T) {
+	db := setupTestDB(t)
+	menu := entity.Menu{Name: "Burger", Price: 30000, MenuOptions: "[]"}
+	if err := db.Create(&menu).Error; err != nil {
+		t.Fatalf("seed menu: %v", err)
+	}
+	menuOptionRepo := repository.NewMenuOptionRepository(repository.MenuOptionRepoConfig{DB: db})
+
+	options := []entity.MenuOption{
+		{Title: "Size", Type: entity.MenuOptionTypeSingle, Min: 1, Max: 1, Available: true, MenuOptionLists: []entity.MenuOptionList{
+			{Name: "Regular", Available: true},
+			{Name: "Large", Price: 5000, Available: true},
+		}},
+		{Title: "Toppings", Type: entity.MenuOptionTypeMulti, Max: 2, Available: true, MenuOptionLists: []entity.MenuOptionList{
+			{Name: "Cheese", Price: 3000, Available: true},
+		}},
+	}
+
+	_, err := menuOptionRepo.SaveMenuOptions(menu.ID, options)
+	if err != nil {
+		t.Fatalf("save menu options: %v", err)
+	}
+
+	replaced, err := menuOptionRepo.SaveMenuOptions(menu.ID, options[1:])
+	if err != nil {
+		t.Fatalf("save menu options again: %v", err)
+	}
+	if len(replaced) != 1 || replaced[0].Title != "Toppings" || len(replaced[0].MenuOptionLists) != 1 {
+		t.Fatalf("expected only the toppings group, got %+v", replaced)
+	}
+
+	var stored entity.Menu
+	if err := db.First(&stored, menu.ID).Error; err != nil {
+		t.Fatalf("get menu: %v", err)
+	}
+
+	var snapshot []entity.MenuOption
+	if err := json.Unmarshal([]byte(stored.MenuOptions), &snapshot); err != nil {
+		t.Fatalf("parse menu options snapshot: %v", err)
+	}
+	if len(snapshot) != 1 || snapshot[0].ID != replaced[0].ID {
+		t.Errorf("expected the snapshot to match the tables, got %s", stored.MenuOptions)
+	}
+}
+
+func TestDeleteMenuOptionListSyncsSnapshot(t *testing.T) {
+	db := setupTestDB(t)
+	menu := entity.Menu{Name: "Burger", Price: 30000, MenuOptions: "[]"}
+	if err := db.Create(&menu).Error; err != nil {
+		t.Fatalf("seed menu: %v", err)
+	}
+	menuOptionRepo := repository.NewMenuOptionRepository(repository.MenuOptionRepoConfig{DB: db})
+
+	option, err := menuOptionRepo.CreateMenuOption(entity.MenuOption{
+		MenuID: menu.ID, Title: "Sauce", Type: entity.MenuOptionTypeMulti, Max: 2, Available: true,
+		MenuOptionLists: []entity.MenuOptionList{{Name: "Chili", Available: true}, {Name: "Mayo", Available: true}},
+	})
+	if err != nil {
+		t.Fatalf("create menu option: %v", err)
+	}
+
+	err = menuOptionRepo.DeleteMenuOptionList(option.MenuOptionLists[0])
+	if err != nil {
+		t.Fatalf("delete menu option choice: %v", err)
+	}
+
+	options, err := menuOptionRepo.GetMenuOptionsByMenuID(menu.ID)
+	if err != nil {
+		t.Fatalf("get menu options: %v", err)
+	}
+	if len(options) != 1 || len(options[0].MenuOptionLists) != 1 || options[0].MenuOptionLists[0].Name != "Mayo" {
+		t.Fatalf("expected only the mayo choice to be left, got %+v", options)
+	}
+
+	var stored entity.Menu
+	if err := db.First(&stored, menu.ID).Error; err != nil {
+		t.Fatalf("get menu: %v", err)
+	}
+
+	var snapshot []entity.MenuOption
+	if err := json.Unmarshal([]byte(stored.MenuOptions), &snapshot); err != nil {
+		t.Fatalf("parse menu options snapshot: %v", err)
+	}
+	if len(snapshot) != 1 || len(snapshot[0].MenuOptionLists) != 1 {
+		t.Errorf("expected the snapshot to drop the deleted choice, got %s", stored.MenuOptions)
+	}
+}
+
+func TestSaveMenuOptionsUpdatesStoredOptionsInPlace(t *testing.T) {
+	db := setupTestDB(t)
+	menu := entity.Menu{Name: "Burger", Price: 30000, MenuOptions: "[]"}
+	if err := db.Create(&menu).Error; err != nil {
+		t.Fatalf("seed menu: %v", err)
+	}
+	menuOptionRepo := repository.NewMenuOptionRepository(repository.MenuOptionRepoConfig{DB: db})
+
+	stock := 3
+	stored, err := menuOptionRepo.SaveMenuOptions(menu.ID, []entity.MenuOption{
+		{Title: "Size", Type: entity.MenuOptionTypeSingle, Min: 1, Max: 1, Available: true, MenuOptionLists: []entity.MenuOptionList{
+			{Name: "Regular", Available: true},
+			{Name: "Large", Price: 5000, Available: true, Stock: &stock},
+		}},
+		{Title: "Toppings", Type: entity.MenuOptionTypeMulti, Max: 2, Available: true, MenuOptionLists: []entity.MenuOptionList{
+			{Name: "Cheese", Price: 3000, Available: true},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("save menu options: %v", err)
+	}
+
+	// the form drops the toppings and the regular size, renames the large size
+	// without sending its stock and adds a new size
+	size := stored[0]
+	large := size.MenuOptionLists[1]
+	large.Name = "Big"
+	large.Stock = nil
+	large.Available = false
+	size.MenuOptionLists = []entity.MenuOptionList{large, {Name: "Small", Available: true}}
+
+	saved, err := menuOptionRepo.SaveMenuOptions(menu.ID, []entity.MenuOption{size})
+	if err != nil {
+		t.Fatalf("save menu options again: %v", err)
+	}
+
+	if len(saved) != 1 || saved[0].ID != size.ID || len(saved[0].MenuOptionLists) != 2 {
+		t.Fatalf("expected the size group to be kept with two choices, got %+v", saved)
+	}
+	big := saved[0].MenuOptionLists[0]
+	if big.ID != large.ID || big.Name != "Big" || big.Available || big.Stock == nil || *big.Stock != 3 {
+		t.Errorf("expected the large size to be renamed in place with its stock, got %+v", big)
+	}
+	if saved[0].MenuOptionLists[1].Name != "Small" || saved[0].MenuOptionLists[1].ID == 0 {
+		t.Errorf("expected the small size to be created, got %+v", saved[0].MenuOptionLists[1])
+	}
+}
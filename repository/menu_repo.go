@@ -1,28 +1,31 @@
 package repository
 
 import (
-	"final-project-backend/domain"
 	"final-project-backend/dto"
 	"final-project-backend/entity"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MenuRepository interface {
 	CreateMenu(entity.Menu) (*entity.Menu, error)
-	GetMenus(dto.Query) ([]entity.Menu, error)
+	GetMenus(dto.Query) ([]entity.Menu, int64, error)
+	GetAllMenus() ([]entity.Menu, error)
 	CreateMenuCategories([]entity.CategoriesMenu) error
 	UpdateMenu(entity.Menu) (*entity.Menu, error)
 	DeleteMenu(entity.Menu) error
 	GetMenuById(uint) (*entity.Menu, error)
 	DeleteMenuCategoriesByMenuId(uint) error
-	IsValidMenuOptions(uint, string) error
 	ToggleFavoriteMenu(uint, uint) (*entity.MenuFavorite, error)
 	CreateUserFavoriteMenu(entity.MenuFavorite) (*entity.MenuFavorite, error)
 	DeleteUserFavoriteMenu(entity.MenuFavorite) error
 	GetUserFavoriteMenu(uint, uint) (*entity.MenuFavorite, error)
 	GetUserFavoriteMenus(uint) ([]entity.Menu, error)
-	GetCategories() ([]entity.Category, error)
+	RefreshMenuSearchVector(menuId uint) error
+	SuggestMenus(search string, limit int) ([]entity.Menu, error)
+	UpdateMenuSoldOut(menuId uint, soldOut bool) error
+	ReplaceMenuAvailability(menuId uint, windows []entity.MenuAvailability) error
 }
 
 type menuRepositoryImpl struct {
@@ -44,22 +47,41 @@ func (r *menuRepositoryImpl) CreateMenu(menu entity.Menu) (*entity.Menu, error)
 		return nil, err
 	}
 
-	r.db.Preload("MenuOptions.MenuOptionLists").Preload("Categories").First(&menu)
+	r.db.Scopes(preloadOptionGroups).Preload("Categories").First(&menu)
 
 	return &menu, nil
 }
 
-func (r *menuRepositoryImpl) GetMenus(query dto.Query) ([]entity.Menu, error) {
+func (r *menuRepositoryImpl) GetMenus(query dto.Query) ([]entity.Menu, int64, error) {
 	var menus []entity.Menu
-	err := r.db.Raw("SELECT distinct m.* FROM menus m JOIN categories_menus cm ON cm.menu_id = m.id JOIN categories c ON c.id = cm.category_id WHERE c.name ILIKE ? and m.name ILIKE ?  and m.deleted_at IS NULL", "%"+query.Category+"%", "%"+query.Search+"%").Scan(&menus).Error
+	var total int64
 
+	db, err := menuQuerySpec.apply(r.db.Model(&entity.Menu{}), query)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	// a new session keeps the count from leaking into the page query
+	db = db.Session(&gorm.Session{})
 
-	// PRELOAD CATEGORIES from generated entry on menus
-	for i := range menus {
-		r.db.Preload("Categories").First(&menus[i])
+	err = db.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = db.Preload("Categories").Scopes(preloadOptionGroups, preloadAvailabilityWindows, paginate(query)).Find(&menus).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return menus, total, nil
+}
+
+// GetAllMenus returns every menu with its categories and option groups.
+func (r *menuRepositoryImpl) GetAllMenus() ([]entity.Menu, error) {
+	var menus []entity.Menu
+	err := r.db.Preload("Categories").Scopes(preloadOptionGroups).Order("id").Find(&menus).Error
+	if err != nil {
+		return nil, err
 	}
 
 	return menus, nil
@@ -86,7 +108,7 @@ func (r *menuRepositoryImpl) DeleteMenuCategoriesByMenuId(menuId uint) error {
 }
 
 func (r *menuRepositoryImpl) UpdateMenu(menu entity.Menu) (*entity.Menu, error) {
-	err := r.db.Save(&menu).Error
+	err := r.db.Omit(clause.Associations, "Stock").Save(&menu).Error
 
 	if err != nil {
 		return nil, err
@@ -114,7 +136,7 @@ func (r *menuRepositoryImpl) DeleteMenu(menu entity.Menu) error {
 
 func (r *menuRepositoryImpl) GetMenuById(id uint) (*entity.Menu, error) {
 	var menu entity.Menu
-	err := r.db.Preload("Categories").Where("id = ?", id).First(&menu).Error
+	err := r.db.Preload("Categories").Scopes(preloadAvailabilityWindows).Where("id = ?", id).First(&menu).Error
 
 	if err != nil {
 		return nil, err
@@ -123,16 +145,6 @@ func (r *menuRepositoryImpl) GetMenuById(id uint) (*entity.Menu, error) {
 	return &menu, nil
 }
 
-func (r *menuRepositoryImpl) IsValidMenuOptions(menuId uint, option string) error {
-	var menu *entity.Menu
-	res := r.db.Where("id = ? and menu_options @> ?", menuId, option).First(&menu)
-	if res.RowsAffected == 0 {
-		return domain.ErrMalformedRequest
-	}
-
-	return nil
-}
-
 func (r *menuRepositoryImpl) ToggleFavoriteMenu(userId uint, menuId uint) (*entity.MenuFavorite, error) {
 	favoriteMenu, err := r.GetUserFavoriteMenu(userId, menuId)
 	if favoriteMenu != nil {
@@ -187,20 +199,40 @@ func (r *menuRepositoryImpl) GetUserFavoriteMenu(userId uint, menuId uint) (*ent
 }
 
 func (r *menuRepositoryImpl) GetUserFavoriteMenus(userId uint) ([]entity.Menu, error) {
-	var favoriteMenus []entity.MenuFavorite
-	err := r.db.Where("user_id = ?", userId).Find(&favoriteMenus).Error
+	var menus []entity.Menu
+	err := r.db.Joins("JOIN menu_favorites mf ON mf.menu_id = menus.id AND mf.deleted_at IS NULL").
+		Where("mf.user_id = ?", userId).Preload("Categories").Order("mf.created_at desc, menus.id").Find(&menus).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	var menuIds []uint
-	for _, menu := range favoriteMenus {
-		menuIds = append(menuIds, menu.MenuID)
-	}
+	return menus, nil
+}
 
+// RefreshMenuSearchVector recomputes the menu's full-text search vector from
+// its current name, description and categories.
+func (r *menuRepositoryImpl) RefreshMenuSearchVector(menuId uint) error {
+	return r.db.Exec("UPDATE menus SET search_vector = menu_search_vector(id) WHERE id = ?", menuId).Error
+}
+
+// SuggestMenus returns the best matching menus for autocomplete, treating
+// every word of search as a prefix and tolerating typos in menu names.
+func (r *menuRepositoryImpl) SuggestMenus(search string, limit int) ([]entity.Menu, error) {
 	var menus []entity.Menu
-	err = r.db.Where("id in (?)", menuIds).Find(&menus).Error
+
+	tsQuery, ok := menuSearchQuery(search)
+	if !ok {
+		return menus, nil
+	}
+
+	rank := menuSearchRank(tsQuery, search)
+	err := r.db.Where(menuSearchMatch(tsQuery, search)).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:  rank.SQL + " DESC, menus.name, menus.id",
+			Vars: rank.Vars,
+		}}).
+		Limit(limit).Find(&menus).Error
 
 	if err != nil {
 		return nil, err
@@ -209,13 +241,34 @@ func (r *menuRepositoryImpl) GetUserFavoriteMenus(userId uint) ([]entity.Menu, e
 	return menus, nil
 }
 
-func (r *menuRepositoryImpl) GetCategories() ([]entity.Category, error) {
-	var categories []entity.Category
-	err := r.db.Find(&categories).Error
+func preloadAvailabilityWindows(db *gorm.DB) *gorm.DB {
+	return db.Preload("AvailabilityWindows", func(db *gorm.DB) *gorm.DB {
+		return db.Order("menu_availabilities.id")
+	})
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (r *menuRepositoryImpl) UpdateMenuSoldOut(menuId uint, soldOut bool) error {
+	return r.db.Model(&entity.Menu{}).Where("id = ?", menuId).Update("is_sold_out", soldOut).Error
+}
+
+// ReplaceMenuAvailability swaps the menu's availability windows for the given
+// ones. No windows means the menu is available all day.
+func (r *menuRepositoryImpl) ReplaceMenuAvailability(menuId uint, windows []entity.MenuAvailability) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("menu_id = ?", menuId).Delete(&entity.MenuAvailability{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(windows) == 0 {
+			return nil
+		}
+
+		for i := range windows {
+			windows[i].ID = 0
+			windows[i].MenuID = menuId
+		}
 
-	return categories, nil
+		return tx.Create(&windows).Error
+	})
 }
@@ -0,0 +1,278 @@
+package repository_test
+
+import (
+	"final-project-backend/db"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// countStatements counts every query issued through db from now on.
+func countStatements(t testing.TB, db *gorm.DB) *int64 {
+	t.Helper()
+
+	var statements int64
+	count := func(*gorm.DB) { atomic.AddInt64(&statements, 1) }
+
+	err := db.Callback().Query().After("gorm:query").Register("test:count_query", count)
+	if err != nil {
+		t.Fatalf("register query callback: %v", err)
+	}
+
+	err = db.Callback().Row().After("gorm:row").Register("test:count_row", count)
+	if err != nil {
+		t.Fatalf("register row callback: %v", err)
+	}
+
+	return &statements
+}
+
+// seedCatalogue creates menus in two categories, all favourited by one user,
+// and returns that user's ID.
+func seedCatalogue(t testing.TB, db *gorm.DB, menus int) uint {
+	t.Helper()
+
+	user := entity.User{Username: "catalogue_user"}
+	categories := []entity.Category{{Name: "Burger"}, {Name: "Drink"}}
+	for _, value := range []interface{}{&user, &categories} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	for i := 0; i < menus; i++ {
+		menu := entity.Menu{Name: fmt.Sprintf("Menu %d", i), Price: 10000 + i, MenuOptions: "[]"}
+		if err := db.Create(&menu).Error; err != nil {
+			t.Fatalf("seed menu: %v", err)
+		}
+
+		for _, value := range []interface{}{
+			&entity.CategoriesMenu{CategoryID: categories[0].ID, MenuID: menu.ID},
+			&entity.CategoriesMenu{CategoryID: categories[1].ID, MenuID: menu.ID},
+			&entity.MenuFavorite{UserID: user.ID, MenuID: menu.ID},
+		} {
+			if err := db.Create(value).Error; err != nil {
+				t.Fatalf("seed menu relations: %v", err)
+			}
+		}
+	}
+
+	return user.ID
+}
+
+func TestGetMenusStatementsStayConstant(t *testing.T) {
+	var counts []int64
+
+	for _, size := range []int{3, 30} {
+		db := setupTestDB(t)
+		seedCatalogue(t, db, size)
+		menuRepo := repository.NewMenuRepository(repository.MenuRepoConfig{DB: db})
+		statements := countStatements(t, db)
+
+		menus, total, err := menuRepo.GetMenus(dto.Query{Limit: dto.MaxLimit})
+		if err != nil {
+			t.Fatalf("get menus: %v", err)
+		}
+		if len(menus) != size || total != int64(size) {
+			t.Fatalf("expected %d menus, got %d of %d", size, len(menus), total)
+		}
+		if len(menus[0].Categories) != 2 {
+			t.Errorf("expected categories to be loaded, got %d", len(menus[0].Categories))
+		}
+
+		counts = append(counts, *statements)
+	}
+
+	if counts[0] != counts[1] {
+		t.Errorf("expected the same number of statements for every catalogue size, got %v", counts)
+	}
+}
+
+func TestGetUserFavoriteMenusStatementsStayConstant(t *testing.T) {
+	var counts []int64
+
+	for _, size := range []int{3, 30} {
+		db := setupTestDB(t)
+		userId := seedCatalogue(t, db, size)
+		menuRepo := repository.NewMenuRepository(repository.MenuRepoConfig{DB: db})
+		statements := countStatements(t, db)
+
+		menus, err := menuRepo.GetUserFavoriteMenus(userId)
+		if err != nil {
+			t.Fatalf("get favorite menus: %v", err)
+		}
+		if len(menus) != size {
+			t.Fatalf("expected %d favorite menus, got %d", size, len(menus))
+		}
+		if len(menus[0].Categories) != 2 {
+			t.Errorf("expected categories to be loaded, got %d", len(menus[0].Categories))
+		}
+
+		counts = append(counts, *statements)
+	}
+
+	if counts[0] != counts[1] {
+		t.Errorf("expected the same number of statements for every catalogue size, got %v", counts)
+	}
+}
+
+func BenchmarkGetMenus(b *testing.B) {
+	db := setupTestDB(b)
+	seedCatalogue(b, db, 100)
+	menuRepo := repository.NewMenuRepository(repository.MenuRepoConfig{DB: db})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, err := menuRepo.GetMenus(dto.Query{Limit: dto.MaxLimit})
+		if err != nil {
+			b.Fatalf("get menus: %v", err)
+		}
+	}
+}
+
+func BenchmarkGetUserFavoriteMenus(b *testing.B) {
+	db := setupTestDB(b)
+	userId := seedCatalogue(b, db, 100)
+	menuRepo := repository.NewMenuRepository(repository.MenuRepoConfig{DB: db})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := menuRepo.GetUserFavoriteMenus(userId)
+		if err != nil {
+			b.Fatalf("get favorite menus: %v", err)
+		}
+	}
+}
+
+func TestMenuAvailabilitySurvivesMenuUpdate(t *testing.T) {
+	db := setupTestDB(t)
+	menu := entity.Menu{Name: "Pancake", Price: 20000, MenuOptions: "[]"}
+	if err := db.Create(&menu).Error; err != nil {
+		t.Fatalf("seed menu: %v", err)
+	}
+	menuRepo := repository.NewMenuRepository(repository.MenuRepoConfig{DB: db})
+
+	monday := 1
+	err := menuRepo.ReplaceMenuAvailability(menu.ID, []entity.MenuAvailability{
+		{StartTime: "06:00", EndTime: "10:30"},
+		{DayOfWeek: &monday},
+	})
+	if err != nil {
+		t.Fatalf("replace availability: %v", err)
+	}
+
+	if err := menuRepo.UpdateMenuSoldOut(menu.ID, true); err != nil {
+		t.Fatalf("update sold out: %v", err)
+	}
+
+	stored, err := menuRepo.GetMenuById(menu.ID)
+	if err != nil {
+		t.Fatalf("get menu: %v", err)
+	}
+	if !stored.IsSoldOut || len(stored.AvailabilityWindows) != 2 {
+		t.Fatalf("expected a sold out menu with 2 windows, got %v and %d", stored.IsSoldOut, len(stored.AvailabilityWindows))
+	}
+
+	stored.Name = "Pancake Stack"
+	if _, err := menuRepo.UpdateMenu(*stored); err != nil {
+		t.Fatalf("update menu: %v", err)
+	}
+
+	var windows int64
+	db.Model(&entity.MenuAvailability{}).Where("menu_id = ?", menu.ID).Count(&windows)
+	if windows != 2 {
+		t.Errorf("expected updating the menu to keep its 2 windows, got %d", windows)
+	}
+}
+
+func TestGetMenusWithoutPaginationReturnsEveryMenu(t *testing.T) {
+	db := setupTestDB(t)
+	seedCatalogue(t, db, 12)
+	menuRepo := repository.NewMenuRepository(repository.MenuRepoConfig{DB: db})
+
+	menus, total, err := menuRepo.GetMenus(dto.Query{})
+	if err != nil {
+		t.Fatalf("get menus: %v", err)
+	}
+	if len(menus) != 12 || total != 12 {
+		t.Errorf("expected all 12 menus without limit or page, got %d of %d", len(menus), total)
+	}
+
+	menus, _, err = menuRepo.GetMenus(dto.Query{Page: 2})
+	if err != nil {
+		t.Fatalf("get menus page: %v", err)
+	}
+	if len(menus) != 2 {
+		t.Errorf("expected the second page of 10 to hold 2 menus, got %d", len(menus))
+	}
+}
+
+// setupSearchDB is setupTestDB with the menu search function and trigram
+// index created.
+func setupSearchDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	conn := setupTestDB(t)
+	if err := db.MigrateMenuSearch(conn); err != nil {
+		t.Fatalf("migrate menu search: %v", err)
+	}
+
+	return conn
+}
+
+func TestSearchMenusRanksNamesAndToleratesTypos(t *testing.T) {
+	db := setupSearchDB(t)
+
+	menus := []entity.Menu{
+		{Name: "Iced Tea", Description: "goes well with a burger", MenuOptions: "[]"},
+		{Name: "Cheese Burger", Description: "double patty", MenuOptions: "[]"},
+		{Name: "French Fries", Description: "crispy", MenuOptions: "[]"},
+	}
+	if err := db.Create(&menus).Error; err != nil {
+		t.Fatalf("seed menus: %v", err)
+	}
+	if err := db.Exec("UPDATE menus SET search_vector = menu_search_vector(id)").Error; err != nil {
+		t.Fatalf("compute search vectors: %v", err)
+	}
+
+	menuRepo := repository.NewMenuRepository(repository.MenuRepoConfig{DB: db})
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{"burger", []string{"Cheese Burger", "Iced Tea"}},
+		{"burgers", []string{"Cheese Burger", "Iced Tea"}},
+		{"burgr", []string{"Cheese Burger"}},
+		{"chese", []string{"Cheese Burger"}},
+		{"fri", []string{"French Fries"}},
+		{"pizza", nil},
+	}
+
+	for _, tt := range tests {
+		found, _, err := menuRepo.GetMenus(dto.Query{Search: tt.search})
+		if err != nil {
+			t.Fatalf("search %q: %v", tt.search, err)
+		}
+
+		var names []string
+		for _, menu := range found {
+			names = append(names, menu.Name)
+		}
+		if fmt.Sprint(names) != fmt.Sprint(tt.want) {
+			t.Errorf("search %q: expected %v, got %v", tt.search, tt.want, names)
+		}
+	}
+
+	suggestions, err := menuRepo.SuggestMenus("burgr", 8)
+	if err != nil {
+		t.Fatalf("suggest menus: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Name != "Cheese Burger" {
+		t.Errorf("expected the misspelt suggestion to find the burger, got %v", suggestions)
+	}
+}
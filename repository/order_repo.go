@@ -10,13 +10,18 @@ import (
 )
 
 type OrderRepository interface {
-	GetAllOrders(user dto.UserResponse, query dto.Query) ([]entity.Order, error)
+	GetAllOrders(user dto.UserResponse, query dto.Query) ([]entity.Order, int64, error)
 	GetOrderByID(id uint) (*entity.Order, error)
 	GetOrderDetailsByID(id uint) (*entity.OrderDetail, error)
 	GetTransactionTotalByDate(date time.Time) (int64, error)
 	CreateOrder(order entity.Order) (*entity.Order, error)
 	CreateOrderProcess(order entity.Order, details []entity.OrderDetail, delivery entity.Delivery) (*entity.Order, error)
 	CreateBatchOrderDetails([]entity.OrderDetail) (*[]entity.OrderDetail, error)
+	UpdateOrderStatus(order entity.Order, status string) error
+	RestoreOrderStock(orderId uint) error
+	CreateOrderStatusEvent(event entity.OrderStatusEvent) (*entity.OrderStatusEvent, error)
+	CreateOrderPromotions(promotions []entity.OrderPromotion) error
+	GetOrderStatusEvents(orderId uint) ([]entity.OrderStatusEvent, error)
 	UserHasOrdered(userId, menuId uint) (bool, error)
 	UserHasReviewed(userId uint, orderDetailId uint) (bool, error)
 	GetCustomerReviewsByMenuId(id uint) (*[]entity.CustomerReview, error)
@@ -36,24 +41,34 @@ func NewOrderRepository(c OrderRepoConfig) OrderRepository {
 	return &orderRepositoryImpl{db: c.DB}
 }
 
-func (o *orderRepositoryImpl) GetAllOrders(user dto.UserResponse, query dto.Query) ([]entity.Order, error) {
+func (o *orderRepositoryImpl) GetAllOrders(user dto.UserResponse, query dto.Query) ([]entity.Order, int64, error) {
 	var orders []entity.Order
-	var err error
-	if user.Role == "admin" {
-		err = o.db.Preload("OrderDetails.Menu.Categories").Preload("OrderDetails.Menu").Preload("OrderDetails").Preload("Delivery").
-			Where("ordered_menus ILIKE ?", "%"+query.Search+"%").Order(query.SortBy + " " + query.Sort).Find(&orders).Error
+	var total int64
+
+	db := o.db.Model(&entity.Order{})
+	if user.Role != "admin" {
+		db = db.Where("orders.user_id = ?", user.ID)
+	}
+
+	db, err := orderQuerySpec.apply(db, query)
+	if err != nil {
+		return nil, 0, err
 	}
+	// a new session keeps the count from leaking into the page query
+	db = db.Session(&gorm.Session{})
 
-	if user.Role == "user" {
-		err = o.db.Preload("OrderDetails.Menu.Categories").Preload("OrderDetails.Menu").Preload("OrderDetails").Preload("Delivery").
-			Where("ordered_menus ILIKE ? AND user_id = ?", "%"+query.Search+"%", user.ID).Order(query.SortBy + " " + query.Sort).Find(&orders).Error
+	err = db.Count(&total).Error
+	if err != nil {
+		return nil, 0, domain.ErrInvalidRequest
 	}
 
+	err = db.Preload("OrderDetails.Menu.Categories").Preload("OrderDetails.Menu").Preload("OrderDetails").Preload("Delivery").Preload("PaymentOption").Preload("Promotions").
+		Scopes(paginate(query)).Find(&orders).Error
 	if err != nil {
-		return nil, domain.ErrInvalidRequest
+		return nil, 0, domain.ErrInvalidRequest
 	}
 
-	return orders, nil
+	return orders, total, nil
 }
 
 func (o *orderRepositoryImpl) GetTransactionTotalByDate(dateStart time.Time) (int64, error) {
@@ -68,7 +83,7 @@ func (o *orderRepositoryImpl) GetTransactionTotalByDate(dateStart time.Time) (in
 
 func (o *orderRepositoryImpl) GetOrderByID(id uint) (*entity.Order, error) {
 	var order entity.Order
-	err := o.db.Preload("Delivery").Preload("OrderDetails").First(&order, id).Error
+	err := o.db.Preload("Delivery").Preload("PaymentOption").Preload("OrderDetails.Menu").Preload("Promotions").First(&order, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -96,23 +111,30 @@ func (o *orderRepositoryImpl) CreateOrder(order entity.Order) (*entity.Order, er
 }
 
 func (o *orderRepositoryImpl) CreateOrderProcess(order entity.Order, details []entity.OrderDetail, delivery entity.Delivery) (*entity.Order, error) {
-	tx := o.db.Begin()
-	err := tx.Create(&order).Association("OrderDetails").Append(&details)
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&order).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&order).Association("OrderDetails").Append(&details)
+		if err != nil {
+			return err
+		}
+
+		err = consumeStock(tx, details)
+		if err != nil {
+			return err
+		}
+
+		delivery.OrderID = order.ID
+		return tx.Create(&delivery).Error
+	})
 
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	delivery.OrderID = order.ID
-	err = tx.Create(&delivery).Error
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	tx.Commit()
-
 	o.db.Preload("Delivery").Preload("OrderDetails.Menu").Preload("OrderDetails").First(&order, order.ID)
 
 	return &order, nil
@@ -127,6 +149,32 @@ func (o *orderRepositoryImpl) CreateBatchOrderDetails(orderDetails []entity.Orde
 	return &orderDetails, nil
 }
 
+// UpdateOrderStatus only moves the order when it still has the status it was
+// read with, so two concurrent transitions cannot both succeed.
+func (o *orderRepositoryImpl) UpdateOrderStatus(order entity.Order, status string) error {
+	res := o.db.Model(&entity.Order{}).Where("id = ? AND status = ?", order.ID, order.Status).Update("status", status)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return domain.ErrInvalidStatusTransition
+	}
+
+	return nil
+}
+
+// RestoreOrderStock gives the stock taken by the order's details back.
+func (o *orderRepositoryImpl) RestoreOrderStock(orderId uint) error {
+	var details []entity.OrderDetail
+	err := o.db.Where("order_id = ?", orderId).Find(&details).Error
+	if err != nil {
+		return err
+	}
+
+	return restoreStock(o.db, details)
+}
+
 func (o *orderRepositoryImpl) UserHasOrdered(userId, menuId uint) (bool, error) {
 	var count int64
 	err := o.db.Model(&entity.Order{}).Where("user_id = ? AND order_details.menu_id = ?", userId, menuId).Joins("JOIN order_details ON orders.id = order_details.order_id").Count(&count).Error
@@ -177,7 +225,7 @@ func (o *orderRepositoryImpl) CreateCustomerReviewProcess(customerReview entity.
 
 	menu.AvgRating = float64((menu.AvgRating*float64(menu.UserRatingCount) + float64(customerReview.Rating)) / float64(menu.UserRatingCount+1))
 	menu.UserRatingCount = menu.UserRatingCount + 1
-	err = tx.Save(&menu).Error
+	err = tx.Omit("Stock").Save(&menu).Error
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -206,3 +254,30 @@ func (o *orderRepositoryImpl) CreateCustomerReview(customerReview entity.Custome
 
 	return &customerReview, nil
 }
+
+func (o *orderRepositoryImpl) CreateOrderPromotions(promotions []entity.OrderPromotion) error {
+	if len(promotions) == 0 {
+		return nil
+	}
+
+	return o.db.Create(&promotions).Error
+}
+
+func (o *orderRepositoryImpl) CreateOrderStatusEvent(event entity.OrderStatusEvent) (*entity.OrderStatusEvent, error) {
+	err := o.db.Create(&event).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (o *orderRepositoryImpl) GetOrderStatusEvents(orderId uint) ([]entity.OrderStatusEvent, error) {
+	var events []entity.OrderStatusEvent
+	err := o.db.Where("order_id = ?", orderId).Order("created_at asc, id asc").Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
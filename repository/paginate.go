@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"final-project-backend/dto"
+
+	"gorm.io/gorm"
+)
+
+// paginate limits a find to the page requested by query, if any.
+func paginate(query dto.Query) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !query.IsPaginated() {
+			return db
+		}
+
+		return db.Offset(query.GetOffset()).Limit(query.GetLimit())
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"final-project-backend/entity"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,6 +15,7 @@ type PromotionRepository interface {
 	DeletePromotion(entity.Promotion) error
 	DeletePromotionDetails(promotionID uint) error
 	DeletePaymentRequirements(promotionID uint) error
+	GetActivePromotionRules(now time.Time) ([]entity.Promotion, error)
 }
 
 type promotionRepositoryImpl struct {
@@ -121,3 +123,16 @@ func (r *promotionRepositoryImpl) DeletePaymentRequirements(promotionID uint) er
 
 	return nil
 }
+
+// GetActivePromotionRules returns the unexpired promotions that apply
+// automatically, highest priority first.
+func (r *promotionRepositoryImpl) GetActivePromotionRules(now time.Time) ([]entity.Promotion, error) {
+	var promotions []entity.Promotion
+	err := r.db.Preload("PromotionDetails").Where("rule_type <> '' AND expired_date > ?", now).
+		Order("priority desc, id").Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
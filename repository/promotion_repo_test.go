@@ -0,0 +1,61 @@
+package repository_test
+
+import (
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"testing"
+	"time"
+)
+
+func TestGetActivePromotionRules(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Now()
+
+	promotions := []entity.Promotion{
+		{Name: "bundle only", Price: 20000, ExpiredDate: now.AddDate(0, 0, 7)},
+		{Name: "expired", RuleType: entity.PromotionRuleCategoryPercentage, CategoryID: 1, Percentage: 10, ExpiredDate: now.AddDate(0, 0, -1)},
+		{Name: "low", RuleType: entity.PromotionRuleCategoryPercentage, CategoryID: 1, Percentage: 10, ExpiredDate: now.AddDate(0, 0, 7)},
+		{Name: "high", RuleType: entity.PromotionRuleBuyXGetY, BuyQuantity: 2, FreeQuantity: 1, Priority: 5, ExpiredDate: now.AddDate(0, 0, 7),
+			PromotionDetails: []entity.PromotionDetail{{MenuID: 1}}},
+	}
+	if err := db.Create(&promotions).Error; err != nil {
+		t.Fatalf("seed promotions: %v", err)
+	}
+
+	promotionRepo := repository.NewPromotionRepository(repository.PromotionRepoConfig{DB: db})
+	rules, err := promotionRepo.GetActivePromotionRules(now)
+	if err != nil {
+		t.Fatalf("get promotion rules: %v", err)
+	}
+
+	if len(rules) != 2 || rules[0].Name != "high" || rules[1].Name != "low" {
+		t.Fatalf("expected the high and low rules by priority, got %+v", rules)
+	}
+	if len(rules[0].PromotionDetails) != 1 {
+		t.Errorf("expected the promotion menus to be loaded, got %d", len(rules[0].PromotionDetails))
+	}
+}
+
+func TestOrderListsAppliedPromotions(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 1)
+	if err := db.Create(&f.order).Error; err != nil {
+		t.Fatalf("seed order: %v", err)
+	}
+
+	orderRepo := repository.NewOrderRepository(repository.OrderRepoConfig{DB: db})
+	err := orderRepo.CreateOrderPromotions([]entity.OrderPromotion{
+		{OrderID: f.order.ID, PromotionID: 1, Name: "buy 2 get 1", RuleType: entity.PromotionRuleBuyXGetY, Discount: 25000},
+	})
+	if err != nil {
+		t.Fatalf("create order promotions: %v", err)
+	}
+
+	order, err := orderRepo.GetOrderByID(f.order.ID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	if len(order.Promotions) != 1 || order.Promotions[0].Discount != 25000 {
+		t.Errorf("expected the applied promotion on the order, got %+v", order.Promotions)
+	}
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const queryDateLayout = "2006-01-02"
+
+// queryFilter narrows db using one typed filter of the query. It returns
+// ErrInvalidQuery when the filter value cannot be parsed.
+type queryFilter func(db *gorm.DB, query dto.Query) (*gorm.DB, error)
+
+// querySpec declares which sort keys and filters a list endpoint accepts.
+// Sort keys map to fixed column expressions so client input never reaches
+// the SQL text.
+type querySpec struct {
+	sorts       map[string]string
+	defaultSort string
+	defaultDir  string
+	tieBreaker  string
+	filters     []queryFilter
+	// rank orders searches by relevance when the client asks for the
+	// "relevance" sort key or gives no sort key at all
+	rank func(query dto.Query) (clause.Expr, bool)
+}
+
+// apply adds the spec's filters and ordering to db.
+func (s querySpec) apply(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+	var err error
+	for _, filter := range s.filters {
+		db, err = filter(db, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.rank != nil && (query.SortBy == "" || query.SortBy == "relevance") {
+		if rank, ok := s.rank(query); ok {
+			return db.Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:  rank.SQL + " DESC, " + s.tieBreaker,
+				Vars: rank.Vars,
+			}}), nil
+		}
+	}
+
+	orderBy, err := s.orderBy(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Order(orderBy), nil
+}
+
+func (s querySpec) orderBy(query dto.Query) (string, error) {
+	sortBy := query.SortBy
+	if sortBy == "" || (sortBy == "relevance" && s.rank != nil) {
+		sortBy = s.defaultSort
+	}
+
+	column, ok := s.sorts[sortBy]
+	if !ok {
+		return "", domain.ErrInvalidQuery
+	}
+
+	dir := strings.ToLower(query.Sort)
+	if dir == "" {
+		dir = s.defaultDir
+	}
+
+	if dir != "asc" && dir != "desc" {
+		return "", domain.ErrInvalidQuery
+	}
+
+	return column + " " + dir + ", " + s.tieBreaker, nil
+}
+
+func parseQueryDate(value string) (time.Time, error) {
+	date, err := time.Parse(queryDateLayout, value)
+	if err != nil {
+		return time.Time{}, domain.ErrInvalidQuery
+	}
+
+	return date, nil
+}
+
+// dateRangeFilter keeps rows whose column falls within the query's start and
+// end dates, both inclusive.
+func dateRangeFilter(column string) queryFilter {
+	return func(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+		if query.StartDate != "" {
+			start, err := parseQueryDate(query.StartDate)
+			if err != nil {
+				return nil, err
+			}
+			db = db.Where(column+" >= ?", start)
+		}
+
+		if query.EndDate != "" {
+			end, err := parseQueryDate(query.EndDate)
+			if err != nil {
+				return nil, err
+			}
+			db = db.Where(column+" < ?", end.AddDate(0, 0, 1))
+		}
+
+		return db, nil
+	}
+}
+
+// rangeFilter keeps rows whose column is within the min and max picked from
+// the query.
+func rangeFilter(column string, min func(dto.Query) *int, max func(dto.Query) *int) queryFilter {
+	return func(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+		minValue, maxValue := min(query), max(query)
+		if minValue != nil && maxValue != nil && *minValue > *maxValue {
+			return nil, domain.ErrInvalidQuery
+		}
+
+		if minValue != nil {
+			db = db.Where(column+" >= ?", *minValue)
+		}
+
+		if maxValue != nil {
+			db = db.Where(column+" <= ?", *maxValue)
+		}
+
+		return db, nil
+	}
+}
+
+var orderQuerySpec = querySpec{
+	sorts: map[string]string{
+		"order_date":  "orders.order_date",
+		"total_price": "orders.total_price",
+		"status":      "orders.status",
+	},
+	defaultSort: "order_date",
+	defaultDir:  "desc",
+	tieBreaker:  "orders.id",
+	filters: []queryFilter{
+		func(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+			return db.Where("orders.ordered_menus ILIKE ?", "%"+query.Search+"%"), nil
+		},
+		dateRangeFilter("orders.order_date"),
+		rangeFilter("orders.total_price",
+			func(q dto.Query) *int { return q.MinTotal },
+			func(q dto.Query) *int { return q.MaxTotal },
+		),
+		func(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+			if query.Status == "" {
+				return db, nil
+			}
+			return db.Where("orders.status = ?", query.Status), nil
+		},
+		func(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+			if query.PaymentOptionID == 0 {
+				return db, nil
+			}
+			return db.Where("orders.payment_option_id = ?", query.PaymentOptionID), nil
+		},
+	},
+}
+
+var couponRedemptionQuerySpec = querySpec{
+	sorts: map[string]string{
+		"redeemed_at": "coupon_redemptions.redeemed_at",
+		"discount":    "coupon_redemptions.discount",
+		"order_total": "coupon_redemptions.order_total",
+	},
+	defaultSort: "redeemed_at",
+	defaultDir:  "desc",
+	tieBreaker:  "coupon_redemptions.id",
+	filters: []queryFilter{
+		dateRangeFilter("coupon_redemptions.redeemed_at"),
+	},
+}
+
+var menuQuerySpec = querySpec{
+	sorts: map[string]string{
+		"name":       "menus.name",
+		"price":      "menus.price",
+		"rating":     "menus.avg_rating",
+		"created_at": "menus.created_at",
+		"popularity": "(SELECT COALESCE(SUM(od.quantity), 0) FROM order_details od WHERE od.menu_id = menus.id AND od.deleted_at IS NULL)",
+	},
+	defaultSort: "name",
+	defaultDir:  "asc",
+	tieBreaker:  "menus.id",
+	rank: func(query dto.Query) (clause.Expr, bool) {
+		tsQuery, ok := menuSearchQuery(query.Search)
+		if !ok {
+			return clause.Expr{}, false
+		}
+		return menuSearchRank(tsQuery, query.Search), true
+	},
+	filters: []queryFilter{
+		func(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+			tsQuery, ok := menuSearchQuery(query.Search)
+			if !ok {
+				return db.Where("menus.name ILIKE ?", "%"+query.Search+"%"), nil
+			}
+			return db.Where(menuSearchMatch(tsQuery, query.Search)), nil
+		},
+		// category filters use subqueries so uncategorised menus are kept
+		// when no category is asked for
+		func(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+			if query.Category == "" {
+				return db, nil
+			}
+			return db.Where("menus.id IN (SELECT cm.menu_id FROM categories_menus cm JOIN categories c ON c.id = cm.category_id WHERE c.name ILIKE ? AND cm.deleted_at IS NULL)", "%"+query.Category+"%"), nil
+		},
+		func(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+			if len(query.CategoryIDs) == 0 {
+				return db, nil
+			}
+			return db.Where("menus.id IN (SELECT cm.menu_id FROM categories_menus cm WHERE cm.category_id IN ? AND cm.deleted_at IS NULL)", query.CategoryIDs), nil
+		},
+		rangeFilter("menus.price",
+			func(q dto.Query) *int { return q.MinPrice },
+			func(q dto.Query) *int { return q.MaxPrice },
+		),
+		func(db *gorm.DB, query dto.Query) (*gorm.DB, error) {
+			if query.MinRating == nil {
+				return db, nil
+			}
+			if *query.MinRating < 0 || *query.MinRating > 5 {
+				return nil, domain.ErrInvalidQuery
+			}
+			return db.Where("menus.avg_rating >= ?", *query.MinRating), nil
+		},
+	},
+}
+
+// menuSearchQuery turns free text into a tsquery matching every word as a
+// prefix, either as typed or English-stemmed so "burgers" also finds
+// "burger". Anything but letters and digits is dropped so the words are
+// always valid tsquery syntax. It reports false when no word is left.
+func menuSearchQuery(search string) (clause.Expr, bool) {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(words) == 0 {
+		return clause.Expr{}, false
+	}
+
+	var tsQuery clause.Expr
+	for i, word := range words {
+		if i > 0 {
+			tsQuery.SQL += " && "
+		}
+		tsQuery.SQL += "(to_tsquery('simple', ?) || to_tsquery('english', ?))"
+		tsQuery.Vars = append(tsQuery.Vars, word+":*", word+":*")
+	}
+
+	return tsQuery, true
+}
+
+// menuSearchMatch keeps menus matching tsQuery, containing search in their
+// name, or whose name has a word close to search so typos such as "burgr"
+// still find burgers.
+func menuSearchMatch(tsQuery clause.Expr, search string) clause.Expr {
+	return clause.Expr{
+		SQL:  "(menus.search_vector @@ (" + tsQuery.SQL + ") OR menus.name ILIKE ? OR ? <% menus.name)",
+		Vars: append(append([]interface{}{}, tsQuery.Vars...), "%"+search+"%", search),
+	}
+}
+
+// menuSearchRank scores full-text matches first and adds how close the name
+// is to search, which is all typo matches get.
+func menuSearchRank(tsQuery clause.Expr, search string) clause.Expr {
+	return clause.Expr{
+		SQL:  "(ts_rank(menus.search_vector, " + tsQuery.SQL + ") + word_similarity(?, menus.name))",
+		Vars: append(append([]interface{}{}, tsQuery.Vars...), search),
+	}
+}
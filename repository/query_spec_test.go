@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// specSQL renders the find a spec builds for query without a database.
+func specSQL(t *testing.T, spec querySpec, model interface{}, query dto.Query) (string, []interface{}, error) {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run database: %v", err)
+	}
+
+	tx, err := spec.apply(db.Model(model), query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	stmt := tx.Find(model).Statement
+	return stmt.SQL.String(), stmt.Vars, nil
+}
+
+func intQuery(v int) *int {
+	return &v
+}
+
+func TestQuerySpecRejectsUnknownSort(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  querySpec
+		model interface{}
+		query dto.Query
+	}{
+		{"order sort key", orderQuerySpec, &[]entity.Order{}, dto.Query{SortBy: "user_id"}},
+		{"order sort injection", orderQuerySpec, &[]entity.Order{}, dto.Query{SortBy: "order_date; DROP TABLE orders"}},
+		{"order direction", orderQuerySpec, &[]entity.Order{}, dto.Query{SortBy: "order_date", Sort: "sideways"}},
+		{"menu sort key", menuQuerySpec, &[]entity.Menu{}, dto.Query{SortBy: "picture_url"}},
+		{"menu direction", menuQuerySpec, &[]entity.Menu{}, dto.Query{Sort: "asc, id"}},
+		{"relevance without ranking", couponRedemptionQuerySpec, &[]entity.CouponRedemption{}, dto.Query{SortBy: "relevance"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := specSQL(t, tt.spec, tt.model, tt.query)
+			if !errors.Is(err, domain.ErrInvalidQuery) {
+				t.Errorf("expected ErrInvalidQuery, got %v", err)
+			}
+		})
+	}
+}
+
+func TestQuerySpecSortsByWhitelistedColumn(t *testing.T) {
+	sql, _, err := specSQL(t, menuQuerySpec, &[]entity.Menu{}, dto.Query{SortBy: "price", Sort: "DESC"})
+	if err != nil {
+		t.Fatalf("apply menu spec: %v", err)
+	}
+
+	if !strings.Contains(sql, "ORDER BY menus.price desc, menus.id") {
+		t.Errorf("expected to sort by price with the id tie breaker, got %s", sql)
+	}
+}
+
+func TestMenuQuerySpecFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    dto.Query
+		wantSQL  string
+		wantVars []interface{}
+		wantErr  error
+	}{
+		{
+			name:     "category name",
+			query:    dto.Query{Category: "burg"},
+			wantSQL:  "menus.id IN (SELECT cm.menu_id FROM categories_menus cm JOIN categories c ON c.id = cm.category_id WHERE c.name ILIKE $2",
+			wantVars: []interface{}{"%burg%"},
+		},
+		{
+			name:    "category IDs",
+			query:   dto.Query{CategoryIDs: []uint{3, 4}},
+			wantSQL: "cm.category_id IN ($2,$3)",
+		},
+		{
+			name:     "price range",
+			query:    dto.Query{MinPrice: intQuery(10000), MaxPrice: intQuery(20000)},
+			wantSQL:  "menus.price >= $2 AND menus.price <= $3",
+			wantVars: []interface{}{10000, 20000},
+		},
+		{
+			name:    "inverted price range",
+			query:   dto.Query{MinPrice: intQuery(20000), MaxPrice: intQuery(10000)},
+			wantErr: domain.ErrInvalidQuery,
+		},
+		{
+			name:     "minimum rating",
+			query:    dto.Query{MinRating: func() *float64 { v := 4.5; return &v }()},
+			wantSQL:  "menus.avg_rating >= $2",
+			wantVars: []interface{}{4.5},
+		},
+		{
+			name:    "rating above five",
+			query:   dto.Query{MinRating: func() *float64 { v := 6.0; return &v }()},
+			wantErr: domain.ErrInvalidQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, vars, err := specSQL(t, menuQuerySpec, &[]entity.Menu{}, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !strings.Contains(sql, tt.wantSQL) {
+				t.Errorf("expected %q in %s", tt.wantSQL, sql)
+			}
+			for _, want := range tt.wantVars {
+				if !containsVar(vars, want) {
+					t.Errorf("expected %v in %v", want, vars)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderQuerySpecFilters(t *testing.T) {
+	sql, vars, err := specSQL(t, orderQuerySpec, &[]entity.Order{}, dto.Query{
+		StartDate: "2023-01-01",
+		EndDate:   "2023-01-31",
+		MinTotal:  intQuery(50000),
+		Status:    "delivered",
+	})
+	if err != nil {
+		t.Fatalf("apply order spec: %v", err)
+	}
+
+	for _, want := range []string{"orders.order_date >= ", "orders.order_date < ", "orders.total_price >= ", "orders.status = "} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in %s", want, sql)
+		}
+	}
+	if !containsVar(vars, "delivered") || !containsVar(vars, 50000) {
+		t.Errorf("expected the status and total in %v", vars)
+	}
+
+	_, _, err = specSQL(t, orderQuerySpec, &[]entity.Order{}, dto.Query{StartDate: "01/01/2023"})
+	if !errors.Is(err, domain.ErrInvalidQuery) {
+		t.Errorf("expected a malformed date to be rejected, got %v", err)
+	}
+}
+
+func containsVar(vars []interface{}, want interface{}) bool {
+	for _, v := range vars {
+		if v == want {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestMenuSearchQueryMatchesStemsAndPrefixes(t *testing.T) {
+	tsQuery, ok := menuSearchQuery("Cheese-BURGERS!")
+	if !ok {
+		t.Fatal("expected a query for two words")
+	}
+
+	want := "(to_tsquery('simple', ?) || to_tsquery('english', ?)) && (to_tsquery('simple', ?) || to_tsquery('english', ?))"
+	if tsQuery.SQL != want {
+		t.Errorf("expected %s, got %s", want, tsQuery.SQL)
+	}
+	if fmt.Sprint(tsQuery.Vars) != "[cheese:* cheese:* burgers:* burgers:*]" {
+		t.Errorf("expected every word as a prefix, got %v", tsQuery.Vars)
+	}
+
+	if _, ok := menuSearchQuery(" !? "); ok {
+		t.Error("expected no query without words")
+	}
+}
@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"final-project-backend/entity"
+	"os"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB connects to the Postgres pointed at by TEST_DATABASE_DSN,
+// migrates the tables the tests need and truncates them. Tests are skipped
+// when the variable is not set.
+func setupTestDB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("connect test database: %v", err)
+	}
+
+	err = db.AutoMigrate(
+		&entity.Role{},
+		&entity.UsersCoupon{},
+		&entity.User{},
+		&entity.Coupon{},
+		&entity.CategoriesMenu{},
+		&entity.Category{},
+		&entity.Menu{},
+		&entity.MenuOption{},
+		&entity.MenuOptionList{},
+		&entity.MenuAvailability{},
+		&entity.MenuFavorite{},
+		&entity.PaymentOption{},
+		&entity.Order{},
+		&entity.OrderDetail{},
+		&entity.Delivery{},
+		&entity.UserCartItem{},
+		&entity.OrderStatusEvent{},
+		&entity.CouponCategory{},
+		&entity.CouponMenu{},
+		&entity.CouponClaim{},
+		&entity.CouponRedemption{},
+		&entity.OrderPromotion{},
+		&entity.Promotion{},
+		&entity.PromotionDetail{},
+		&entity.PaymentRequirement{},
+	)
+	if err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	err = db.Exec("TRUNCATE roles, users, coupons, users_coupons, categories, categories_menus, menus, menu_options, menu_option_lists, menu_availabilities, menu_favorites, payment_options, orders, order_details, deliveries, user_cart_items, order_status_events, coupon_categories, coupon_menus, coupon_claims, coupon_redemptions, order_promotions, promotions, promotion_details, payment_requirements RESTART IDENTITY CASCADE").Error
+	if err != nil {
+		t.Fatalf("truncate test database: %v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"encoding/json"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+
+	"gorm.io/gorm"
+)
+
+// StockRepository manages the stock counts of menus and option choices. A
+// NULL stock means the item is not tracked and never runs out.
+type StockRepository interface {
+	SetMenuStock(menuId uint, stock *int) error
+	RestockMenu(menuId uint, quantity int) error
+	SetMenuOptionListStock(listId uint, stock *int) error
+	RestockMenuOptionList(listId uint, quantity int) error
+	GetLowStockMenus(threshold int) ([]dto.LowStockItem, error)
+	GetLowStockMenuOptionLists(threshold int) ([]dto.LowStockItem, error)
+}
+
+type stockRepositoryImpl struct {
+	db *gorm.DB
+}
+
+type StockRepoConfig struct {
+	DB *gorm.DB
+}
+
+func NewStockRepository(c StockRepoConfig) StockRepository {
+	return &stockRepositoryImpl{db: c.DB}
+}
+
+func (r *stockRepositoryImpl) SetMenuStock(menuId uint, stock *int) error {
+	return r.db.Model(&entity.Menu{}).Where("id = ?", menuId).Update("stock", stock).Error
+}
+
+// RestockMenu adds to the menu's stock, starting to track it when it was not.
+func (r *stockRepositoryImpl) RestockMenu(menuId uint, quantity int) error {
+	return r.db.Model(&entity.Menu{}).Where("id = ?", menuId).
+		Update("stock", gorm.Expr("COALESCE(stock, 0) + ?", quantity)).Error
+}
+
+func (r *stockRepositoryImpl) SetMenuOptionListStock(listId uint, stock *int) error {
+	return r.db.Model(&entity.MenuOptionList{}).Where("id = ?", listId).Update("stock", stock).Error
+}
+
+func (r *stockRepositoryImpl) RestockMenuOptionList(listId uint, quantity int) error {
+	return r.db.Model(&entity.MenuOptionList{}).Where("id = ?", listId).
+		Update("stock", gorm.Expr("COALESCE(stock, 0) + ?", quantity)).Error
+}
+
+func (r *stockRepositoryImpl) GetLowStockMenus(threshold int) ([]dto.LowStockItem, error) {
+	items := []dto.LowStockItem{}
+	err := r.db.Model(&entity.Menu{}).Select("menus.id AS menu_id, menus.name AS menu_name, menus.stock").
+		Where("menus.stock IS NOT NULL AND menus.stock <= ?", threshold).
+		Order("menus.stock, menus.id").Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// GetLowStockMenuOptionLists only reports choices of option groups and menus
+// that still exist.
+func (r *stockRepositoryImpl) GetLowStockMenuOptionLists(threshold int) ([]dto.LowStockItem, error) {
+	items := []dto.LowStockItem{}
+	err := r.db.Model(&entity.MenuOptionList{}).
+		Select("m.id AS menu_id, m.name AS menu_name, mo.id AS option_id, mo.title AS option_title, menu_option_lists.id AS choice_id, menu_option_lists.name AS choice_name, menu_option_lists.stock").
+		Joins("JOIN menu_options mo ON mo.id = menu_option_lists.menu_option_id AND mo.deleted_at IS NULL").
+		Joins("JOIN menus m ON m.id = mo.menu_id AND m.deleted_at IS NULL").
+		Where("menu_option_lists.stock IS NOT NULL AND menu_option_lists.stock <= ?", threshold).
+		Order("menu_option_lists.stock, menu_option_lists.id").Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// consumeStock takes the ordered quantities off the stock of the menus and
+// checked option choices. Each update only applies while enough stock is
+// left, so concurrent orders can never take it below zero. UpdateColumn
+// leaves updated_at alone since an order does not edit the item.
+func consumeStock(tx *gorm.DB, details []entity.OrderDetail) error {
+	for _, detail := range details {
+		res := tx.Model(&entity.Menu{}).Where("id = ? AND (stock IS NULL OR stock >= ?)", detail.MenuID, detail.Quantity).
+			UpdateColumn("stock", gorm.Expr("stock - ?", detail.Quantity))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrOutOfStock
+		}
+
+		listIds, err := checkedMenuOptionListIDs(detail)
+		if err != nil {
+			return err
+		}
+
+		for _, listId := range listIds {
+			res = tx.Model(&entity.MenuOptionList{}).Where("id = ? AND (stock IS NULL OR stock >= ?)", listId, detail.Quantity).
+				UpdateColumn("stock", gorm.Expr("stock - ?", detail.Quantity))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return domain.ErrOutOfStock
+			}
+		}
+	}
+
+	return nil
+}
+
+// restoreStock puts the quantities of the order back on the stock of tracked
+// menus and option choices.
+func restoreStock(tx *gorm.DB, details []entity.OrderDetail) error {
+	for _, detail := range details {
+		err := tx.Model(&entity.Menu{}).Unscoped().Where("id = ? AND stock IS NOT NULL", detail.MenuID).
+			UpdateColumn("stock", gorm.Expr("stock + ?", detail.Quantity)).Error
+		if err != nil {
+			return err
+		}
+
+		listIds, err := checkedMenuOptionListIDs(detail)
+		if err != nil {
+			return err
+		}
+
+		for _, listId := range listIds {
+			err = tx.Model(&entity.MenuOptionList{}).Unscoped().Where("id = ? AND stock IS NOT NULL", listId).
+				UpdateColumn("stock", gorm.Expr("stock + ?", detail.Quantity)).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkedMenuOptionListIDs(detail entity.OrderDetail) ([]uint, error) {
+	if detail.MenuOptions == "" {
+		return nil, nil
+	}
+
+	var options []entity.MenuOption
+	err := json.Unmarshal([]byte(detail.MenuOptions), &options)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	for _, option := range options {
+		for _, optionList := range option.MenuOptionLists {
+			if optionList.Checked && optionList.ID != 0 {
+				ids = append(ids, optionList.ID)
+			}
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,76 @@
+package repository_test
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func menuStock(t *testing.T, db *gorm.DB, menuId uint) *int {
+	t.Helper()
+
+	var menu entity.Menu
+	if err := db.First(&menu, menuId).Error; err != nil {
+		t.Fatalf("get menu: %v", err)
+	}
+
+	return menu.Stock
+}
+
+func TestCheckoutRejectsOrderOverStock(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 1)
+	db.Model(&f.menu).Update("stock", 1)
+	uow := repository.NewUnitOfWork(repository.UnitOfWorkConfig{DB: db})
+
+	err := checkout(uow, f, false)
+	if !errors.Is(err, domain.ErrOutOfStock) {
+		t.Fatalf("expected ErrOutOfStock, got %v", err)
+	}
+
+	if total := count(t, db, &entity.Order{}); total != 0 {
+		t.Errorf("expected no orders, got %d", total)
+	}
+	if stock := menuStock(t, db, f.menu.ID); stock == nil || *stock != 1 {
+		t.Errorf("expected menu stock 1, got %v", stock)
+	}
+
+	var userCoupon entity.UsersCoupon
+	db.Where("user_id = ? AND coupon_id = ?", f.user.ID, f.coupon.ID).First(&userCoupon)
+	if userCoupon.Stock != 1 {
+		t.Errorf("expected coupon stock 1, got %d", userCoupon.Stock)
+	}
+}
+
+func TestRestoreOrderStockAfterCheckout(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 1)
+	db.Model(&f.menu).Update("stock", 3)
+	uow := repository.NewUnitOfWork(repository.UnitOfWorkConfig{DB: db})
+
+	err := checkout(uow, f, false)
+	if err != nil {
+		t.Fatalf("expected checkout to succeed, got %v", err)
+	}
+	if stock := menuStock(t, db, f.menu.ID); stock == nil || *stock != 1 {
+		t.Fatalf("expected menu stock 1 after checkout, got %v", stock)
+	}
+
+	var order entity.Order
+	if err := db.First(&order).Error; err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+
+	orderRepo := repository.NewOrderRepository(repository.OrderRepoConfig{DB: db})
+	err = orderRepo.RestoreOrderStock(order.ID)
+	if err != nil {
+		t.Fatalf("restore order stock: %v", err)
+	}
+	if stock := menuStock(t, db, f.menu.ID); stock == nil || *stock != 3 {
+		t.Errorf("expected menu stock 3 after restoring, got %v", stock)
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// TxRepositories holds repositories bound to the transaction of a UnitOfWork.
+type TxRepositories struct {
+	OrderRepo      OrderRepository
+	CouponRepo     CouponRepository
+	CartRepo       CartRepository
+	DeliveryRepo   DeliveryRepository
+	MenuRepo       MenuRepository
+	MenuOptionRepo MenuOptionRepository
+}
+
+// UnitOfWork runs fn in a single database transaction, committing when fn
+// returns nil and rolling everything back otherwise.
+type UnitOfWork interface {
+	Do(fn func(repos TxRepositories) error) error
+}
+
+type unitOfWorkImpl struct {
+	db *gorm.DB
+}
+
+type UnitOfWorkConfig struct {
+	DB *gorm.DB
+}
+
+func NewUnitOfWork(c UnitOfWorkConfig) UnitOfWork {
+	return &unitOfWorkImpl{db: c.DB}
+}
+
+func (u *unitOfWorkImpl) Do(fn func(repos TxRepositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(TxRepositories{
+			OrderRepo:      NewOrderRepository(OrderRepoConfig{DB: tx}),
+			CouponRepo:     NewCouponRepository(CouponRepoConfig{DB: tx}),
+			CartRepo:       NewCartRepository(CartRepoConfig{DB: tx}),
+			DeliveryRepo:   NewDeliveryRepository(DeliveryRepoConfig{DB: tx}),
+			MenuRepo:       NewMenuRepository(MenuRepoConfig{DB: tx}),
+			MenuOptionRepo: NewMenuOptionRepository(MenuOptionRepoConfig{DB: tx}),
+		})
+	})
+}
@@ -0,0 +1,169 @@
+package repository_test
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type checkoutFixture struct {
+	user   entity.User
+	coupon entity.Coupon
+	menu   entity.Menu
+	order  entity.Order
+}
+
+func seedCheckout(t *testing.T, db *gorm.DB, couponStock int) checkoutFixture {
+	t.Helper()
+
+	f := checkoutFixture{
+		user:   entity.User{Username: "checkout_user"},
+		coupon: entity.Coupon{Description: "test coupon", Discount: 1000, Availability: true},
+		menu:   entity.Menu{Name: "Burger", Price: 25000},
+	}
+
+	for _, value := range []interface{}{&f.user, &f.coupon, &f.menu, &entity.PaymentOption{Name: "Cash"}} {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	if couponStock > 0 {
+		err := db.Create(&entity.UsersCoupon{UserID: f.user.ID, CouponID: f.coupon.ID, Stock: couponStock}).Error
+		if err != nil {
+			t.Fatalf("seed user coupon: %v", err)
+		}
+	}
+
+	err := db.Create(&entity.UserCartItem{UserID: f.user.ID, MenuID: f.menu.ID, Quantity: 2, MenuOptions: "[]"}).Error
+	if err != nil {
+		t.Fatalf("seed cart: %v", err)
+	}
+
+	f.order = entity.Order{
+		UserID:          f.user.ID,
+		CouponID:        &f.coupon.ID,
+		PaymentOptionID: 1,
+		OrderedMenus:    f.menu.Name,
+		TotalPrice:      49000,
+	}
+
+	return f
+}
+
+func checkout(uow repository.UnitOfWork, f checkoutFixture, failAfterInsert bool) error {
+	return uow.Do(func(repos repository.TxRepositories) error {
+		err := repos.CouponRepo.ConsumeUserCoupon(f.coupon.ID, f.user.ID)
+		if err != nil {
+			return err
+		}
+
+		details := []entity.OrderDetail{{MenuID: f.menu.ID, Quantity: 2, MenuOptions: "[]"}}
+		_, err = repos.OrderRepo.CreateOrderProcess(f.order, details, entity.Delivery{Address: "Jl. Test", Status: "pending"})
+		if err != nil {
+			return err
+		}
+
+		if failAfterInsert {
+			return errors.New("forced failure")
+		}
+
+		return repos.CartRepo.EmptyCart(int(f.user.ID))
+	})
+}
+
+func count(t *testing.T, db *gorm.DB, model interface{}) int64 {
+	t.Helper()
+
+	var total int64
+	if err := db.Model(model).Count(&total).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+
+	return total
+}
+
+func TestUnitOfWorkCommitsCheckout(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 2)
+	uow := repository.NewUnitOfWork(repository.UnitOfWorkConfig{DB: db})
+
+	err := checkout(uow, f, false)
+	if err != nil {
+		t.Fatalf("expected checkout to succeed, got %v", err)
+	}
+
+	if total := count(t, db, &entity.Order{}); total != 1 {
+		t.Errorf("expected 1 order, got %d", total)
+	}
+	if total := count(t, db, &entity.OrderDetail{}); total != 1 {
+		t.Errorf("expected 1 order detail, got %d", total)
+	}
+	if total := count(t, db, &entity.Delivery{}); total != 1 {
+		t.Errorf("expected 1 delivery, got %d", total)
+	}
+	if total := count(t, db, &entity.UserCartItem{}); total != 0 {
+		t.Errorf("expected cart to be empty, got %d items", total)
+	}
+
+	var userCoupon entity.UsersCoupon
+	db.Where("user_id = ? AND coupon_id = ?", f.user.ID, f.coupon.ID).First(&userCoupon)
+	if userCoupon.Stock != 1 {
+		t.Errorf("expected coupon stock 1, got %d", userCoupon.Stock)
+	}
+}
+
+func TestUnitOfWorkRollsBackOnFailure(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 1)
+	uow := repository.NewUnitOfWork(repository.UnitOfWorkConfig{DB: db})
+
+	err := checkout(uow, f, true)
+	if err == nil {
+		t.Fatal("expected checkout to fail")
+	}
+
+	if total := count(t, db, &entity.Order{}); total != 0 {
+		t.Errorf("expected no orders, got %d", total)
+	}
+	if total := count(t, db, &entity.OrderDetail{}); total != 0 {
+		t.Errorf("expected no order details, got %d", total)
+	}
+	if total := count(t, db, &entity.Delivery{}); total != 0 {
+		t.Errorf("expected no deliveries, got %d", total)
+	}
+	if total := count(t, db, &entity.UserCartItem{}); total != 1 {
+		t.Errorf("expected cart to be kept, got %d items", total)
+	}
+
+	var userCoupon entity.UsersCoupon
+	err = db.Where("user_id = ? AND coupon_id = ?", f.user.ID, f.coupon.ID).First(&userCoupon).Error
+	if err != nil {
+		t.Fatalf("expected user coupon to be kept, got %v", err)
+	}
+	if userCoupon.Stock != 1 {
+		t.Errorf("expected coupon stock 1, got %d", userCoupon.Stock)
+	}
+}
+
+func TestUnitOfWorkRejectsCouponWithoutStock(t *testing.T) {
+	db := setupTestDB(t)
+	f := seedCheckout(t, db, 0)
+	uow := repository.NewUnitOfWork(repository.UnitOfWorkConfig{DB: db})
+
+	err := checkout(uow, f, false)
+	if !errors.Is(err, domain.ErrUserCouponNotFound) {
+		t.Fatalf("expected ErrUserCouponNotFound, got %v", err)
+	}
+
+	if total := count(t, db, &entity.Order{}); total != 0 {
+		t.Errorf("expected no orders, got %d", total)
+	}
+	if total := count(t, db, &entity.UserCartItem{}); total != 1 {
+		t.Errorf("expected cart to be kept, got %d items", total)
+	}
+}
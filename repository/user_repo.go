@@ -20,6 +20,8 @@ type UserRepository interface {
 	HasValidToken(id uint, token string) bool
 	ReduceGamesAttempt(userId uint) error
 	ResetGamesAttempt() error
+	GetExistingUserIDs(ids []uint) ([]uint, error)
+	GetUserIDsBySegment(segment dto.UserSegment) ([]uint, error)
 }
 
 type userRepositoryImpl struct {
@@ -135,3 +137,43 @@ func (r *userRepositoryImpl) ResetGamesAttempt() error {
 	return nil
 
 }
+
+func (r *userRepositoryImpl) GetExistingUserIDs(ids []uint) ([]uint, error) {
+	var existingIds []uint
+	err := r.db.Model(&entity.User{}).Where("id IN ?", ids).Order("id").Pluck("id", &existingIds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return existingIds, nil
+}
+
+// GetUserIDsBySegment returns the customers matching every filter of the
+// segment. Admins are never part of a segment.
+func (r *userRepositoryImpl) GetUserIDsBySegment(segment dto.UserSegment) ([]uint, error) {
+	db := r.db.Model(&entity.User{}).Joins("JOIN roles ON roles.id = users.role_id").Where("roles.name <> ?", "admin")
+
+	if segment.RegisteredAfter != nil {
+		db = db.Where("users.registered_at > ?", *segment.RegisteredAfter)
+	}
+	if segment.MinOrders > 0 {
+		db = db.Where(`(SELECT count(*) FROM orders WHERE orders.user_id = users.id
+			AND orders.deleted_at IS NULL AND orders.status <> ?) >= ?`, "cancelled", segment.MinOrders)
+	}
+	if segment.TopPlayers > 0 {
+		db = db.Where(`users.id IN (SELECT user_id FROM game_leaderboards WHERE deleted_at IS NULL
+			ORDER BY accumulated_score DESC, user_id LIMIT ?)`, segment.TopPlayers)
+	}
+	if segment.FavoriteMenuID != 0 {
+		db = db.Where(`EXISTS (SELECT 1 FROM menu_favorites WHERE menu_favorites.user_id = users.id
+			AND menu_favorites.menu_id = ? AND menu_favorites.deleted_at IS NULL)`, segment.FavoriteMenuID)
+	}
+
+	var ids []uint
+	err := db.Order("users.id").Pluck("users.id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -12,16 +12,23 @@ import (
 )
 
 type RouterConfig struct {
-	AuthUsecase      usecase.AuthUsecase
-	UserUsecase      usecase.UserUsecase
-	CouponUsecase    usecase.CouponUsecase
-	MenuUsecase      usecase.MenuUsecase
-	MediaUsecase     usecase.MediaUsecase
-	CartUsecase      usecase.CartUsecase
-	OrderUsecase     usecase.OrderUsecase
-	DeliveryUsecase  usecase.DeliveryUsecase
-	GameUsecase      usecase.GameUsecase
-	PromotionUsecase usecase.PromotionUsecase
+	AuthUsecase         usecase.AuthUsecase
+	UserUsecase         usecase.UserUsecase
+	CouponUsecase       usecase.CouponUsecase
+	CouponReportUsecase usecase.CouponReportUsecase
+	MenuUsecase         usecase.MenuUsecase
+	MenuOptionUsecase   usecase.MenuOptionUsecase
+	StockUsecase        usecase.StockUsecase
+	CategoryUsecase     usecase.CategoryUsecase
+	MenuImportUsecase   usecase.MenuImportUsecase
+	MediaUsecase        usecase.MediaUsecase
+	CartUsecase         usecase.CartUsecase
+	OrderUsecase        usecase.OrderUsecase
+	DeliveryUsecase     usecase.DeliveryUsecase
+	GameUsecase         usecase.GameUsecase
+	PromotionUsecase    usecase.PromotionUsecase
+	CheckoutUsecase     usecase.CheckoutUsecase
+	IdempotencyUsecase  usecase.IdempotencyUsecase
 }
 
 func NewRouter(c RouterConfig) *gin.Engine {
@@ -33,25 +40,33 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowMethods:     []string{"GET, POST, PUT, DELETE"},
-		AllowHeaders:     []string{"Content-Type", "Content-Length", "Accept-Encoding", "Authorization", "Cache-Control"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Content-Type", "Content-Length", "Accept-Encoding", "Authorization", "Cache-Control", "Idempotency-Key"},
+		ExposeHeaders:    []string{"Content-Length", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	h := handler.New(handler.HandlerConfig{
-		AuthUsecase:      c.AuthUsecase,
-		UserUsecase:      c.UserUsecase,
-		CouponUsecase:    c.CouponUsecase,
-		MenuUsecase:      c.MenuUsecase,
-		MediaUsecase:     c.MediaUsecase,
-		CartUsecase:      c.CartUsecase,
-		OrderUsecase:     c.OrderUsecase,
-		DeliveryUsecase:  c.DeliveryUsecase,
-		GameUsecase:      c.GameUsecase,
-		PromotionUsecase: c.PromotionUsecase,
+		AuthUsecase:         c.AuthUsecase,
+		UserUsecase:         c.UserUsecase,
+		CouponUsecase:       c.CouponUsecase,
+		CouponReportUsecase: c.CouponReportUsecase,
+		MenuUsecase:         c.MenuUsecase,
+		MenuOptionUsecase:   c.MenuOptionUsecase,
+		StockUsecase:        c.StockUsecase,
+		CategoryUsecase:     c.CategoryUsecase,
+		MenuImportUsecase:   c.MenuImportUsecase,
+		MediaUsecase:        c.MediaUsecase,
+		CartUsecase:         c.CartUsecase,
+		OrderUsecase:        c.OrderUsecase,
+		DeliveryUsecase:     c.DeliveryUsecase,
+		GameUsecase:         c.GameUsecase,
+		PromotionUsecase:    c.PromotionUsecase,
+		CheckoutUsecase:     c.CheckoutUsecase,
 	})
 
+	idempotency := middleware.Idempotency(c.IdempotencyUsecase)
+
 	v1 := r.Group("/api/v1")
 
 	v1.POST("/login", h.Login)
@@ -60,8 +75,11 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	v1.GET("/promotions", h.GetPromotions)
 	v1.GET("/promotions/:id", h.GetPromotionById)
 	v1.GET("/menus", h.GetMenus)
+	v1.GET("/menus/suggest", h.SuggestMenus)
 	v1.GET("/menus/:id", h.GetMenuById)
+	v1.GET("/menus/:id/options", h.GetMenuOptions)
 	v1.GET("categories", h.GetCategories)
+	v1.GET("/categories/:id", h.GetCategoryById)
 	v1.POST("/upload", h.UploadImage)
 
 	v1.Use(middleware.Authorize, h.HasValidToken)
@@ -74,28 +92,58 @@ func NewRouter(c RouterConfig) *gin.Engine {
 	v1.DELETE("/carts/:id", h.DeleteCartItem)
 	v1.PUT("/carts/:id", h.UpdateCartItem)
 	v1.GET("/user-coupons", h.GetUserCoupons)
-	v1.POST("/orders", h.CreateOrder)
+	v1.POST("/user-coupons/redeem", h.RedeemCoupon)
+	v1.POST("/orders", idempotency, h.CreateOrder)
+	v1.POST("/orders/quote", h.QuoteOrder)
 	v1.GET("/orders", h.GetAllOrders)
+	v1.GET("/orders/:id", h.GetOrderById)
+	v1.POST("/orders/:id/cancel", h.CancelOrder)
+	v1.GET("/orders/:id/timeline", h.GetOrderTimeline)
+	v1.GET("/orders/:id/events", h.StreamOrderEvents)
 	v1.PUT("/menus/:id/favorites", h.ToggleFavoriteMenu)
 	v1.GET("/menus/favorites", h.GetFavoriteMenus)
 	v1.POST("/customer-reviews", h.CreateCustomerReview)
 	v1.POST("/games", h.CreateGame)
 	v1.PUT("/games/:id", h.AnswerGameQuestion)
 	v1.GET("/game-leaderboards", h.GetGameLeaderboard)
-	v1.POST("/promotions/:id/orders", h.CreatePromotionOrder)
+	v1.POST("/promotions/:id/orders", idempotency, h.CreatePromotionOrder)
 	v1.DELETE("/carts", h.EmptyCart)
+	v1.POST("/carts/checkout", idempotency, h.CheckoutCart)
 
 	v1.Use(middleware.AuthorizeAdmin)
 	v1.GET("/users/:id", h.GetUserByID)
 	v1.POST("/reset-game", h.ResetGamesAttempt)
 	v1.GET("/coupons", h.GetCoupons)
+	v1.GET("/coupons/redemption-report", h.GetCouponRedemptionReport)
+	v1.GET("/coupons/discount-report", h.GetCouponDiscountReport)
+	v1.GET("/coupons/:id/redemptions", h.GetCouponRedemptions)
 	v1.POST("/coupons", h.CreateCoupon)
 	v1.PUT("/coupons/:id", h.UpdateCoupon)
+	v1.POST("/coupons/:id/assignments", h.AssignCoupon)
 	v1.DELETE("/coupons/:id", h.DeleteCouponById)
+	v1.POST("/categories", h.CreateCategory)
+	v1.PUT("/categories/:id", h.UpdateCategory)
+	v1.DELETE("/categories/:id", h.DeleteCategory)
 	v1.POST("/menus", h.CreateMenu)
+	v1.POST("/menus/import", h.ImportMenus)
+	v1.GET("/menus/export", h.ExportMenus)
 	v1.PUT("/menus/:id", h.UpdateMenu)
 	v1.DELETE("/menus/:id", h.DeleteMenu)
+	v1.PUT("/menus/:id/availability", h.UpdateMenuAvailability)
+	v1.PUT("/menus/:id/sold-out", h.UpdateMenuSoldOut)
+	v1.POST("/menus/:id/options", h.CreateMenuOption)
+	v1.PUT("/menus/:id/options/:optionId", h.UpdateMenuOption)
+	v1.DELETE("/menus/:id/options/:optionId", h.DeleteMenuOption)
+	v1.POST("/menus/:id/options/:optionId/choices", h.CreateMenuOptionList)
+	v1.PUT("/menus/:id/options/:optionId/choices/:choiceId", h.UpdateMenuOptionList)
+	v1.DELETE("/menus/:id/options/:optionId/choices/:choiceId", h.DeleteMenuOptionList)
+	v1.GET("/menus/low-stock", h.GetLowStockReport)
+	v1.PUT("/menus/:id/stock", h.UpdateMenuStock)
+	v1.POST("/menus/:id/restock", h.RestockMenu)
+	v1.PUT("/menus/:id/options/:optionId/choices/:choiceId/stock", h.UpdateMenuOptionListStock)
+	v1.POST("/menus/:id/options/:optionId/choices/:choiceId/restock", h.RestockMenuOptionList)
 	v1.PUT("/deliveries/:id", h.UpdateDelivery)
+	v1.PUT("/orders/:id/status", h.UpdateOrderStatus)
 	v1.POST("promotions", h.CreatePromotion)
 	v1.PUT("/promotions/:id", h.UpdatePromotion)
 	v1.DELETE("/promotions/:id", h.DeletePromotion)
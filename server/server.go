@@ -1,11 +1,13 @@
 package server
 
 import (
+	"final-project-backend/config"
 	"final-project-backend/db"
 	"final-project-backend/repository"
 	"final-project-backend/usecase"
 	"final-project-backend/util"
 	"fmt"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -24,6 +26,19 @@ func initRouter() *gin.Engine {
 		DB: db.Get(),
 	})
 
+	menuOptionRepo := repository.NewMenuOptionRepository(repository.MenuOptionRepoConfig{
+		DB: db.Get(),
+	})
+	stockRepo := repository.NewStockRepository(repository.StockRepoConfig{
+		DB: db.Get(),
+	})
+	categoryRepo := repository.NewCategoryRepository(repository.CategoryRepoConfig{
+		DB: db.Get(),
+	})
+	couponReportRepo := repository.NewCouponReportRepository(repository.CouponReportRepoConfig{
+		DB: db.Get(),
+	})
+
 	cartRepo := repository.NewCartRepository(repository.CartRepoConfig{
 		DB: db.Get(),
 	})
@@ -48,6 +63,14 @@ func initRouter() *gin.Engine {
 		DB: db.Get(),
 	})
 
+	idempotencyRepo := repository.NewIdempotencyRepository(repository.IdempotencyRepoConfig{
+		DB: db.Get(),
+	})
+
+	unitOfWork := repository.NewUnitOfWork(repository.UnitOfWorkConfig{
+		DB: db.Get(),
+	})
+
 	mediaUploader := util.NewMediaUploaderUtil()
 	gcsUploader := util.NewGCSUploader()
 	mediaUsecase := usecase.NewMediaUsecase(usecase.MediaUsecaseConfig{
@@ -67,17 +90,55 @@ func initRouter() *gin.Engine {
 	})
 
 	couponUsecase := usecase.NewCouponUsecase(usecase.CouponUsecaseConfig{
-		UserRepo:   userRepo,
-		CouponRepo: couponRepo,
+		UserRepo:     userRepo,
+		CouponRepo:   couponRepo,
+		CategoryRepo: categoryRepo,
+		MenuRepo:     menuRepo,
+		UnitOfWork:   unitOfWork,
 	})
 
 	menuUsecase := usecase.NewMenuUsecase(usecase.MenuUsecaseConfig{
-		MenuRepo:  menuRepo,
-		OrderRepo: orderRepo,
+		MenuRepo:   menuRepo,
+		OrderRepo:  orderRepo,
+		UnitOfWork: unitOfWork,
+	})
+
+	menuOptionUsecase := usecase.NewMenuOptionUsecase(usecase.MenuOptionUsecaseConfig{
+		MenuRepo:       menuRepo,
+		MenuOptionRepo: menuOptionRepo,
+	})
+
+	categoryUsecase := usecase.NewCategoryUsecase(usecase.CategoryUsecaseConfig{
+		CategoryRepo: categoryRepo,
+		MediaUsecase: mediaUsecase,
+	})
+
+	couponReportUsecase := usecase.NewCouponReportUsecase(usecase.CouponReportUsecaseConfig{
+		CouponReportRepo: couponReportRepo,
+		CouponRepo:       couponRepo,
+	})
+
+	stockUsecase := usecase.NewStockUsecase(usecase.StockUsecaseConfig{
+		StockRepo:      stockRepo,
+		MenuRepo:       menuRepo,
+		MenuOptionRepo: menuOptionRepo,
+	})
+
+	menuImportUsecase := usecase.NewMenuImportUsecase(usecase.MenuImportUsecaseConfig{
+		UnitOfWork:        unitOfWork,
+		MenuRepo:          menuRepo,
+		CategoryRepo:      categoryRepo,
+		MenuOptionUsecase: menuOptionUsecase,
+	})
+
+	menuOptionValidator := usecase.NewMenuOptionValidator(usecase.MenuOptionValidatorConfig{
+		MenuOptionRepo: menuOptionRepo,
 	})
 
 	cartUsecase := usecase.NewCartUsecase(usecase.CartUsecaseConfig{
-		CartRepo: cartRepo,
+		CartRepo:            cartRepo,
+		MenuRepo:            menuRepo,
+		MenuOptionValidator: menuOptionValidator,
 	})
 
 	orderUsecase := usecase.NewOrderUsecase(usecase.OrderUsecaseConfig{
@@ -86,10 +147,13 @@ func initRouter() *gin.Engine {
 		MenuRepo:       menuRepo,
 		PaymentOptRepo: paymentOptRepo,
 		DeliveryRepo:   deliveryRepo,
+		UnitOfWork:     unitOfWork,
+		EventHub:       usecase.NewOrderEventHub(),
 	})
 
 	deliveryUsecase := usecase.NewDeliveryUsecase(usecase.DeliveryUsecaseConfig{
 		DeliveryRepo: deliveryRepo,
+		OrderUsecase: orderUsecase,
 	})
 
 	gameUsecase := usecase.NewGameUsecase(usecase.GameUsecaseConfig{
@@ -100,27 +164,61 @@ func initRouter() *gin.Engine {
 		CouponUsecase: couponUsecase,
 	})
 
+	deliveryFeeValue := config.InitConfig().ENVConfig.DeliveryFee
+	deliveryFee, err := strconv.Atoi(deliveryFeeValue)
+	if err != nil || deliveryFee < 0 {
+		panic(fmt.Sprintf("DELIVERY_FEE must be a whole number of at least 0, got %q", deliveryFeeValue))
+	}
+	pricingEngine := usecase.NewPricingEngine(usecase.PricingEngineConfig{
+		MenuRepo:            menuRepo,
+		PromotionRepo:       promotionRepo,
+		MenuOptionValidator: menuOptionValidator,
+		CouponUsecase:       couponUsecase,
+		DeliveryFee:         deliveryFee,
+	})
+
+	checkoutUsecase := usecase.NewCheckoutUsecase(usecase.CheckoutUsecaseConfig{
+		UnitOfWork:     unitOfWork,
+		CartRepo:       cartRepo,
+		CouponRepo:     couponRepo,
+		PaymentOptRepo: paymentOptRepo,
+		PricingEngine:  pricingEngine,
+	})
+
 	promotionUsecase := usecase.NewPromotionUsecase(usecase.PromotionUsecaseConfig{
 		PromotionRepo:     promotionRepo,
 		PaymentOptionRepo: paymentOptRepo,
+		CategoryRepo:      categoryRepo,
 		MediaUsecase:      mediaUsecase,
 		MenuUsecase:       menuUsecase,
 		CartUsecase:       cartUsecase,
 		CouponUsecase:     couponUsecase,
 		OrderUsecase:      orderUsecase,
+		CheckoutUsecase:   checkoutUsecase,
+	})
+
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(usecase.IdempotencyUsecaseConfig{
+		IdempotencyRepo: idempotencyRepo,
 	})
 
 	r := NewRouter(RouterConfig{
-		AuthUsecase:      authUsecase,
-		UserUsecase:      userUsecase,
-		CouponUsecase:    couponUsecase,
-		MenuUsecase:      menuUsecase,
-		MediaUsecase:     mediaUsecase,
-		CartUsecase:      cartUsecase,
-		OrderUsecase:     orderUsecase,
-		DeliveryUsecase:  deliveryUsecase,
-		GameUsecase:      gameUsecase,
-		PromotionUsecase: promotionUsecase,
+		AuthUsecase:         authUsecase,
+		UserUsecase:         userUsecase,
+		CouponUsecase:       couponUsecase,
+		CouponReportUsecase: couponReportUsecase,
+		MenuUsecase:         menuUsecase,
+		MenuOptionUsecase:   menuOptionUsecase,
+		StockUsecase:        stockUsecase,
+		CategoryUsecase:     categoryUsecase,
+		MenuImportUsecase:   menuImportUsecase,
+		MediaUsecase:        mediaUsecase,
+		CartUsecase:         cartUsecase,
+		OrderUsecase:        orderUsecase,
+		DeliveryUsecase:     deliveryUsecase,
+		GameUsecase:         gameUsecase,
+		PromotionUsecase:    promotionUsecase,
+		CheckoutUsecase:     checkoutUsecase,
+		IdempotencyUsecase:  idempotencyUsecase,
 	})
 
 	return r
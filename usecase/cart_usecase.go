@@ -1,10 +1,12 @@
 package usecase
 
 import (
+	"encoding/json"
 	"final-project-backend/domain"
 	"final-project-backend/dto"
 	"final-project-backend/entity"
 	"final-project-backend/repository"
+	"time"
 )
 
 type CartUsecase interface {
@@ -18,16 +20,22 @@ type CartUsecase interface {
 }
 
 type cartUsecaseImpl struct {
-	cartRepo repository.CartRepository
+	cartRepo            repository.CartRepository
+	menuRepo            repository.MenuRepository
+	menuOptionValidator MenuOptionValidator
 }
 
 type CartUsecaseConfig struct {
-	CartRepo repository.CartRepository
+	CartRepo            repository.CartRepository
+	MenuRepo            repository.MenuRepository
+	MenuOptionValidator MenuOptionValidator
 }
 
 func NewCartUsecase(c CartUsecaseConfig) CartUsecase {
 	return &cartUsecaseImpl{
-		cartRepo: c.CartRepo,
+		cartRepo:            c.CartRepo,
+		menuRepo:            c.MenuRepo,
+		menuOptionValidator: c.MenuOptionValidator,
 	}
 }
 
@@ -69,6 +77,21 @@ func (u *cartUsecaseImpl) GetCartItemsByDto(item dto.CartItemData) (*entity.User
 }
 
 func (u *cartUsecaseImpl) AddToCart(item dto.CartItemData) (*entity.UserCartItem, error) {
+	menu, _ := u.menuRepo.GetMenuById(item.MenuID)
+	if menu == nil {
+		return nil, domain.ErrMenuNotFound
+	}
+
+	if !IsMenuAvailable(*menu, time.Now()) {
+		return nil, domain.ErrMenuUnavailable
+	}
+
+	menuOptions, err := u.validateMenuOptions(item.MenuID, item.MenuOptions)
+	if err != nil {
+		return nil, err
+	}
+	item.MenuOptions = menuOptions
+
 	cartRes, err := u.cartRepo.AddToCart(item)
 
 	if err != nil {
@@ -79,6 +102,12 @@ func (u *cartUsecaseImpl) AddToCart(item dto.CartItemData) (*entity.UserCartItem
 }
 
 func (u *cartUsecaseImpl) UpdateCartItem(item entity.UserCartItem) (*entity.UserCartItem, error) {
+	menuOptions, err := u.validateMenuOptions(item.MenuID, item.MenuOptions)
+	if err != nil {
+		return nil, err
+	}
+	item.MenuOptions = menuOptions
+
 	cartRes, err := u.cartRepo.UpdateCartItem(item)
 
 	if err != nil {
@@ -113,3 +142,28 @@ func (u *cartUsecaseImpl) EmptyCart(userId int) error {
 
 	return nil
 }
+
+// validateMenuOptions checks the picked options of a cart line and returns
+// them as the menu's option groups with the picked choices checked, so equal
+// selections are stored the same way and merge into one cart line.
+func (u *cartUsecaseImpl) validateMenuOptions(menuId uint, menuOptions string) (string, error) {
+	var requested []entity.MenuOption
+	if menuOptions != "" {
+		err := json.Unmarshal([]byte(menuOptions), &requested)
+		if err != nil {
+			return "", domain.ErrInvalidMenuOption
+		}
+	}
+
+	selected, _, err := u.menuOptionValidator.Validate(menuId, requested)
+	if err != nil {
+		return "", err
+	}
+
+	snapshot, err := json.Marshal(selected)
+	if err != nil {
+		return "", domain.ErrInternalServer
+	}
+
+	return string(snapshot), nil
+}
@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"final-project-backend/util"
+	"fmt"
+	"strings"
+)
+
+type CategoryUsecase interface {
+	GetCategories(query dto.CategoryQuery) ([]entity.Category, error)
+	GetCategoryByID(id uint) (*entity.Category, error)
+	CreateCategory(input dto.CategoryFormRequest) (*entity.Category, error)
+	UpdateCategory(id uint, input dto.CategoryFormRequest) (*entity.Category, error)
+	DeleteCategory(id uint) error
+	ValidateCategoryIDs(ids []uint) error
+}
+
+type categoryUsecaseImpl struct {
+	categoryRepo repository.CategoryRepository
+	mediaUsecase MediaUsecase
+}
+
+type CategoryUsecaseConfig struct {
+	CategoryRepo repository.CategoryRepository
+	MediaUsecase MediaUsecase
+}
+
+func NewCategoryUsecase(c CategoryUsecaseConfig) CategoryUsecase {
+	return &categoryUsecaseImpl{
+		categoryRepo: c.CategoryRepo,
+		mediaUsecase: c.MediaUsecase,
+	}
+}
+
+func (u *categoryUsecaseImpl) GetCategories(query dto.CategoryQuery) ([]entity.Category, error) {
+	categories, err := u.categoryRepo.GetCategories(query.WithMenus)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return categories, nil
+}
+
+func (u *categoryUsecaseImpl) GetCategoryByID(id uint) (*entity.Category, error) {
+	category, _ := u.categoryRepo.GetCategoryByID(id)
+	if category == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+
+	return category, nil
+}
+
+func (u *categoryUsecaseImpl) CreateCategory(input dto.CategoryFormRequest) (*entity.Category, error) {
+	slug, err := u.categorySlug(0, input)
+	if err != nil {
+		return nil, err
+	}
+
+	category := entity.Category{
+		Name:         strings.TrimSpace(input.Name),
+		Slug:         slug,
+		DisplayOrder: input.DisplayOrder,
+	}
+
+	if input.Icon.Size != 0 {
+		category.IconUrl, category.IconPublicID, _ = u.mediaUsecase.FileUpload(input.Icon)
+		if category.IconUrl == "" {
+			return nil, domain.ErrUploadImage
+		}
+	}
+
+	categoryRes, err := u.categoryRepo.CreateCategory(category)
+	if err != nil {
+		u.deleteIcon(category.IconPublicID)
+	}
+	if errors.Is(err, domain.ErrCategorySlugTaken) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return categoryRes, nil
+}
+
+// UpdateCategory saves the category, replacing its icon when a new one is
+// sent. The old icon is only deleted once the category no longer points at
+// it, and the new one is deleted when the category could not be saved.
+func (u *categoryUsecaseImpl) UpdateCategory(id uint, input dto.CategoryFormRequest) (*entity.Category, error) {
+	category, err := u.GetCategoryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	slug, err := u.categorySlug(id, input)
+	if err != nil {
+		return nil, err
+	}
+
+	oldIconPublicId := category.IconPublicID
+	newIconPublicId := ""
+	if input.Icon.Size != 0 {
+		iconUrl, iconPublicId, _ := u.mediaUsecase.FileUpload(input.Icon)
+		if iconUrl == "" {
+			return nil, domain.ErrUploadImage
+		}
+
+		category.IconUrl = iconUrl
+		category.IconPublicID = iconPublicId
+		newIconPublicId = iconPublicId
+	}
+
+	category.Name = strings.TrimSpace(input.Name)
+	category.Slug = slug
+	category.DisplayOrder = input.DisplayOrder
+
+	categoryRes, err := u.categoryRepo.UpdateCategory(*category)
+	if err != nil {
+		u.deleteIcon(newIconPublicId)
+	}
+	if errors.Is(err, domain.ErrCategorySlugTaken) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	if newIconPublicId != "" {
+		u.deleteIcon(oldIconPublicId)
+	}
+
+	return categoryRes, nil
+}
+
+func (u *categoryUsecaseImpl) DeleteCategory(id uint) error {
+	category, err := u.GetCategoryByID(id)
+	if err != nil {
+		return err
+	}
+
+	err = u.categoryRepo.DeleteCategory(*category)
+	if err != nil {
+		return domain.ErrInternalServer
+	}
+
+	u.deleteIcon(category.IconPublicID)
+
+	return nil
+}
+
+// deleteIcon removes an uploaded icon, if any. A failed delete only leaves an
+// unused file behind, so it is not reported.
+func (u *categoryUsecaseImpl) deleteIcon(publicId string) {
+	if publicId != "" {
+		u.mediaUsecase.FileDelete(publicId)
+	}
+}
+
+// ValidateCategoryIDs returns an error wrapping domain.ErrInvalidCategory
+// that lists the IDs without a category.
+func (u *categoryUsecaseImpl) ValidateCategoryIDs(ids []uint) error {
+	ids = util.UniqueUint(ids)
+
+	categories, err := u.categoryRepo.GetCategoriesByIDs(ids)
+	if err != nil {
+		return domain.ErrInternalServer
+	}
+	if len(categories) == len(ids) {
+		return nil
+	}
+
+	found := map[uint]bool{}
+	for _, category := range categories {
+		found[category.ID] = true
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, fmt.Sprint(id))
+		}
+	}
+
+	return fmt.Errorf("%w: no category with id %s", domain.ErrInvalidCategory, strings.Join(missing, ", "))
+}
+
+// categorySlug validates the requested slug, or derives one from the name, and
+// makes sure no other category uses it.
+func (u *categoryUsecaseImpl) categorySlug(id uint, input dto.CategoryFormRequest) (string, error) {
+	slug := strings.TrimSpace(input.Slug)
+	if slug == "" {
+		slug = util.Slugify(input.Name)
+	}
+
+	isSlug, _ := util.IsSlug(slug)
+	if !isSlug {
+		return "", domain.ErrInvalidCategorySlug
+	}
+
+	existing, _ := u.categoryRepo.GetCategoryBySlug(slug)
+	if existing != nil && existing.ID != id {
+		return "", domain.ErrCategorySlugTaken
+	}
+
+	return slug, nil
+}
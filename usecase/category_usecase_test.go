@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"mime/multipart"
+	"reflect"
+	"testing"
+)
+
+func TestCategoryIconsAreDeletedOnlyWhenUnused(t *testing.T) {
+	icon := multipart.FileHeader{Filename: "burger.png", Size: 1}
+	stored := entity.Category{Name: "Burgers", Slug: "burgers", IconUrl: "https://example.com/old", IconPublicID: "old"}
+	stored.ID = 1
+
+	tests := []struct {
+		name        string
+		saveErr     error
+		update      bool
+		input       dto.CategoryFormRequest
+		wantErr     error
+		wantDeleted []string
+	}{
+		{
+			name:  "created",
+			input: dto.CategoryFormRequest{Name: "Drinks", Icon: icon},
+		},
+		{
+			name:        "create failed",
+			saveErr:     errors.New("connection reset"),
+			input:       dto.CategoryFormRequest{Name: "Drinks", Icon: icon},
+			wantErr:     domain.ErrInternalServer,
+			wantDeleted: []string{"upload-1"},
+		},
+		{
+			name:        "icon replaced",
+			update:      true,
+			input:       dto.CategoryFormRequest{Name: "Burgers", Icon: icon},
+			wantDeleted: []string{"old"},
+		},
+		{
+			name:   "updated without an icon",
+			update: true,
+			input:  dto.CategoryFormRequest{Name: "Burgers"},
+		},
+		{
+			name:        "update failed",
+			saveErr:     domain.ErrCategorySlugTaken,
+			update:      true,
+			input:       dto.CategoryFormRequest{Name: "Burgers", Icon: icon},
+			wantErr:     domain.ErrCategorySlugTaken,
+			wantDeleted: []string{"upload-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mediaUsecase := &fakeMediaUsecase{}
+			categoryRepo := &fakeCategoryRepo{categories: []entity.Category{stored}, saveErr: tt.saveErr}
+			categoryUsecase := NewCategoryUsecase(CategoryUsecaseConfig{CategoryRepo: categoryRepo, MediaUsecase: mediaUsecase})
+
+			var err error
+			if tt.update {
+				_, err = categoryUsecase.UpdateCategory(stored.ID, tt.input)
+			} else {
+				_, err = categoryUsecase.CreateCategory(tt.input)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			if !reflect.DeepEqual(mediaUsecase.deleted, tt.wantDeleted) {
+				t.Errorf("expected %v to be deleted, got %v", tt.wantDeleted, mediaUsecase.deleted)
+			}
+			if tt.wantErr != nil && categoryRepo.categories[0].IconPublicID != "old" {
+				t.Errorf("expected the stored category to keep its icon, got %+v", categoryRepo.categories[0])
+			}
+		})
+	}
+}
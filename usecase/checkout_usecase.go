@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"encoding/json"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"final-project-backend/util"
+	"strings"
+	"time"
+)
+
+type CheckoutUsecase interface {
+	Quote(user dto.UserResponse, input dto.OrderRequest) (*dto.OrderQuote, error)
+	Checkout(user dto.UserResponse, input dto.OrderRequest) (*entity.Order, error)
+	CheckoutCart(user dto.UserResponse, input dto.CartCheckoutRequest) (*entity.Order, error)
+	CheckoutPromotion(promotion entity.Promotion, input dto.PromotionOrderRequest) (*entity.Order, error)
+}
+
+type checkoutUsecaseImpl struct {
+	unitOfWork     repository.UnitOfWork
+	cartRepo       repository.CartRepository
+	couponRepo     repository.CouponRepository
+	paymentOptRepo repository.PaymentOptionRepository
+	pricingEngine  PricingEngine
+}
+
+type CheckoutUsecaseConfig struct {
+	UnitOfWork     repository.UnitOfWork
+	CartRepo       repository.CartRepository
+	CouponRepo     repository.CouponRepository
+	PaymentOptRepo repository.PaymentOptionRepository
+	PricingEngine  PricingEngine
+}
+
+func NewCheckoutUsecase(c CheckoutUsecaseConfig) CheckoutUsecase {
+	return &checkoutUsecaseImpl{
+		unitOfWork:     c.UnitOfWork,
+		cartRepo:       c.CartRepo,
+		couponRepo:     c.CouponRepo,
+		paymentOptRepo: c.PaymentOptRepo,
+		pricingEngine:  c.PricingEngine,
+	}
+}
+
+func (u *checkoutUsecaseImpl) Quote(user dto.UserResponse, input dto.OrderRequest) (*dto.OrderQuote, error) {
+	coupon, err := u.getUserCoupon(input.CouponID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.pricingEngine.QuoteOrder(user.ID, input.OrderDetailRequest, coupon)
+}
+
+// Checkout places the order in the request body. Like ordering always did, it
+// then empties the caller's cart.
+func (u *checkoutUsecaseImpl) Checkout(user dto.UserResponse, input dto.OrderRequest) (*entity.Order, error) {
+	return u.checkout(user, input, func(repos repository.TxRepositories) error {
+		return repos.CartRepo.EmptyCart(int(user.ID))
+	})
+}
+
+// checkout prices and places the order, running clearCart in the order's
+// transaction.
+func (u *checkoutUsecaseImpl) checkout(user dto.UserResponse, input dto.OrderRequest, clearCart func(repos repository.TxRepositories) error) (*entity.Order, error) {
+	paymentOption, _ := u.paymentOptRepo.GetPaymentOptionById(input.PaymentOptionID)
+	if paymentOption == nil {
+		return nil, domain.ErrPaymentOptionNotFound
+	}
+
+	quote, err := u.Quote(user, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderedMenus []string
+	for _, line := range quote.Lines {
+		orderedMenus = append(orderedMenus, line.MenuName)
+	}
+
+	order := entity.Order{
+		CouponID:        quote.CouponID,
+		PaymentOptionID: input.PaymentOptionID,
+		OrderedMenus:    strings.Join(util.UniqueString(orderedMenus), ","),
+		TotalPrice:      quote.TotalPrice,
+		UserID:          user.ID,
+		OrderDate:       time.Now(),
+		Status:          OrderStatusPlaced,
+	}
+
+	return u.placeOrder(order, quote, input.DeliveryAddress, clearCart)
+}
+
+// CheckoutCart builds the order lines from the caller's cart items instead of
+// the request body and then checks out like Checkout. Only the checked-out
+// items are removed from the cart, and the order is rolled back with
+// ErrCartChanged when one of them was already removed, for example by a
+// concurrent checkout.
+func (u *checkoutUsecaseImpl) CheckoutCart(user dto.UserResponse, input dto.CartCheckoutRequest) (*entity.Order, error) {
+	cartItems, err := u.cartRepo.GetCartItems(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cartItems) == 0 {
+		return nil, domain.ErrEmptyCart
+	}
+
+	var orderDetailRequest []*dto.OrderDetailRequest
+	var cartItemIds []uint
+	for _, cartItem := range cartItems {
+		if cartItem.Menu.ID == 0 {
+			return nil, domain.ErrCartMenuDeleted
+		}
+
+		var menuOptions []entity.MenuOption
+		if cartItem.MenuOptions != "" {
+			err = json.Unmarshal([]byte(cartItem.MenuOptions), &menuOptions)
+			if err != nil {
+				return nil, domain.ErrInvalidMenuOption
+			}
+		}
+
+		orderDetailRequest = append(orderDetailRequest, &dto.OrderDetailRequest{
+			MenuID:      cartItem.MenuID,
+			Quantity:    cartItem.Quantity,
+			MenuOptions: menuOptions,
+		})
+		cartItemIds = append(cartItemIds, cartItem.ID)
+	}
+
+	clearCart := func(repos repository.TxRepositories) error {
+		deleted, err := repos.CartRepo.DeleteCartItems(user.ID, cartItemIds)
+		if err != nil {
+			return err
+		}
+		if deleted != int64(len(cartItemIds)) {
+			return domain.ErrCartChanged
+		}
+
+		return nil
+	}
+
+	return u.checkout(user, dto.OrderRequest{
+		CouponID:           input.CouponID,
+		PaymentOptionID:    input.PaymentOptionID,
+		DeliveryAddress:    input.DeliveryAddress,
+		OrderDetailRequest: orderDetailRequest,
+	}, clearCart)
+}
+
+func (u *checkoutUsecaseImpl) CheckoutPromotion(promotion entity.Promotion, input dto.PromotionOrderRequest) (*entity.Order, error) {
+	paymentOption, _ := u.paymentOptRepo.GetPaymentOptionById(input.PaymentOptionID)
+	if paymentOption == nil {
+		return nil, domain.ErrPaymentOptionNotFound
+	}
+
+	coupon, err := u.getUserCoupon(input.CouponID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := u.pricingEngine.QuotePromotionOrder(input.UserID, promotion, input.OrderDetailRequest, coupon)
+	if err != nil {
+		return nil, err
+	}
+
+	order := entity.Order{
+		CouponID:        quote.CouponID,
+		PaymentOptionID: input.PaymentOptionID,
+		OrderedMenus:    promotion.Name,
+		TotalPrice:      quote.TotalPrice,
+		UserID:          input.UserID,
+		OrderDate:       time.Now(),
+		Status:          OrderStatusPlaced,
+	}
+
+	return u.placeOrder(order, quote, input.DeliveryAddress, nil)
+}
+
+// placeOrder consumes the coupon, inserts the order with its details, delivery,
+// applied promotions, coupon redemption and first timeline event and clears the
+// cart when clearCart is set, all in one transaction. Consuming the coupon locks
+// the user's coupon row, so the per-user usage limit is checked again behind
+// that lock in case a concurrent checkout used the coupon after the quote.
+func (u *checkoutUsecaseImpl) placeOrder(order entity.Order, quote *dto.OrderQuote, address string, clearCart func(repos repository.TxRepositories) error) (*entity.Order, error) {
+	var orderDetails []entity.OrderDetail
+	for _, line := range quote.Lines {
+		menuOptionStr, _ := json.Marshal(line.MenuOptions)
+		orderDetails = append(orderDetails, entity.OrderDetail{
+			MenuID:      line.MenuID,
+			Quantity:    line.Quantity,
+			MenuOptions: string(menuOptionStr),
+		})
+	}
+
+	delivery := entity.Delivery{
+		Address: address,
+		Status:  "pending",
+	}
+
+	var orderRes *entity.Order
+	err := u.unitOfWork.Do(func(repos repository.TxRepositories) error {
+		if order.CouponID != nil {
+			err := repos.CouponRepo.ConsumeUserCoupon(*order.CouponID, order.UserID)
+			if err != nil {
+				return err
+			}
+
+			err = checkCouponUsageLimit(repos.CouponRepo, *order.CouponID, order.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		orderRes, err = repos.OrderRepo.CreateOrderProcess(order, orderDetails, delivery)
+		if err != nil {
+			return err
+		}
+
+		var orderPromotions []entity.OrderPromotion
+		for _, promotion := range quote.Promotions {
+			orderPromotions = append(orderPromotions, entity.OrderPromotion{
+				OrderID:     orderRes.ID,
+				PromotionID: promotion.PromotionID,
+				Name:        promotion.Name,
+				RuleType:    promotion.RuleType,
+				Discount:    promotion.Discount,
+			})
+		}
+
+		err = repos.OrderRepo.CreateOrderPromotions(orderPromotions)
+		if err != nil {
+			return err
+		}
+		orderRes.Promotions = orderPromotions
+
+		if order.CouponID != nil {
+			err = repos.CouponRepo.CreateCouponRedemption(entity.CouponRedemption{
+				CouponID:         *order.CouponID,
+				UserID:           order.UserID,
+				OrderID:          orderRes.ID,
+				Discount:         quote.CouponDiscount,
+				DeliveryDiscount: quote.DeliveryDiscount,
+				OrderTotal:       quote.TotalPrice,
+				RedeemedAt:       order.OrderDate,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = repos.OrderRepo.CreateOrderStatusEvent(entity.OrderStatusEvent{
+			OrderID:  orderRes.ID,
+			ToStatus: OrderStatusPlaced,
+			ActorID:  order.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if clearCart != nil {
+			return clearCart(repos)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return orderRes, nil
+}
+
+// checkCouponUsageLimit returns domain.ErrCouponUsageLimit when the user's
+// orders with the coupon already reach its per-user usage limit.
+func checkCouponUsageLimit(couponRepo repository.CouponRepository, couponId uint, userId uint) error {
+	coupon, err := couponRepo.GetCouponById(couponId)
+	if err != nil {
+		return err
+	}
+	if coupon.UsageLimitPerUser == 0 {
+		return nil
+	}
+
+	used, err := couponRepo.CountUserCouponOrders(couponId, userId)
+	if err != nil {
+		return domain.ErrInternalServer
+	}
+	if used >= int64(coupon.UsageLimitPerUser) {
+		return domain.ErrCouponUsageLimit
+	}
+
+	return nil
+}
+
+func (u *checkoutUsecaseImpl) getUserCoupon(couponId *uint, userId uint) (*entity.Coupon, error) {
+	if couponId == nil {
+		return nil, nil
+	}
+
+	coupon, _ := u.couponRepo.GetCouponById(*couponId)
+	if coupon == nil {
+		return nil, domain.ErrCouponNotFound
+	}
+
+	userCoupon, _ := u.couponRepo.GetUserCouponByFK(*couponId, userId)
+	if userCoupon == nil || userCoupon.Stock <= 0 {
+		return nil, domain.ErrUserCouponNotFound
+	}
+
+	return coupon, nil
+}
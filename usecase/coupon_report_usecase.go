@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"math"
+)
+
+// couponReportPeriods are the periods the discount report can be grouped by.
+var couponReportPeriods = map[string]bool{"day": true, "week": true, "month": true}
+
+type CouponReportUsecase interface {
+	GetCouponRedemptions(couponId uint, query dto.Query) ([]entity.CouponRedemption, int64, error)
+	GetRedemptionReport(query dto.Query) (*dto.CouponRedemptionReport, error)
+	GetDiscountReport(query dto.Query) (*dto.CouponDiscountReport, error)
+}
+
+type couponReportUsecaseImpl struct {
+	couponReportRepo repository.CouponReportRepository
+	couponRepo       repository.CouponRepository
+}
+
+type CouponReportUsecaseConfig struct {
+	CouponReportRepo repository.CouponReportRepository
+	CouponRepo       repository.CouponRepository
+}
+
+func NewCouponReportUsecase(c CouponReportUsecaseConfig) CouponReportUsecase {
+	return &couponReportUsecaseImpl{
+		couponReportRepo: c.CouponReportRepo,
+		couponRepo:       c.CouponRepo,
+	}
+}
+
+func (u *couponReportUsecaseImpl) GetCouponRedemptions(couponId uint, query dto.Query) ([]entity.CouponRedemption, int64, error) {
+	coupon, _ := u.couponRepo.GetCouponById(couponId)
+	if coupon == nil {
+		return nil, 0, domain.ErrCouponNotFound
+	}
+
+	redemptions, total, err := u.couponReportRepo.GetCouponRedemptions(couponId, query)
+	if errors.Is(err, domain.ErrInvalidQuery) {
+		return nil, 0, err
+	}
+	if err != nil {
+		return nil, 0, domain.ErrInternalServer
+	}
+
+	return redemptions, total, nil
+}
+
+func (u *couponReportUsecaseImpl) GetRedemptionReport(query dto.Query) (*dto.CouponRedemptionReport, error) {
+	summaries, err := u.couponReportRepo.GetCouponRedemptionSummaries(query)
+	if errors.Is(err, domain.ErrInvalidQuery) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	report := dto.CouponRedemptionReport{
+		StartDate: query.StartDate,
+		EndDate:   query.EndDate,
+		Coupons:   summaries,
+	}
+	for i := range report.Coupons {
+		summary := &report.Coupons[i]
+		summary.ROI = couponROI(summary.OrderValue, summary.DiscountGiven)
+		report.DiscountGiven += summary.DiscountGiven
+		report.OrderValue += summary.OrderValue
+	}
+	report.ROI = couponROI(report.OrderValue, report.DiscountGiven)
+
+	return &report, nil
+}
+
+func (u *couponReportUsecaseImpl) GetDiscountReport(query dto.Query) (*dto.CouponDiscountReport, error) {
+	period := query.Period
+	if period == "" {
+		period = "day"
+	}
+	if !couponReportPeriods[period] {
+		return nil, domain.ErrInvalidQuery
+	}
+
+	periods, err := u.couponReportRepo.GetCouponDiscountPeriods(period, query)
+	if errors.Is(err, domain.ErrInvalidQuery) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return &dto.CouponDiscountReport{
+		Period:    period,
+		StartDate: query.StartDate,
+		EndDate:   query.EndDate,
+		Periods:   periods,
+	}, nil
+}
+
+// couponROI is the order value earned per unit of discount, rounded to two
+// decimals. It is zero when no discount was given.
+func couponROI(orderValue int64, discount int64) float64 {
+	if discount == 0 {
+		return 0
+	}
+
+	return math.Round(float64(orderValue)/float64(discount)*100) / 100
+}
@@ -1,9 +1,15 @@
 package usecase
 
 import (
+	"errors"
+	"final-project-backend/domain"
 	"final-project-backend/dto"
 	"final-project-backend/entity"
 	"final-project-backend/repository"
+	"final-project-backend/util"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
 )
@@ -11,40 +17,61 @@ import (
 type CouponUsecase interface {
 	CreateCoupon(dto.CouponRequest) (*entity.Coupon, error)
 	AssignCouponToUser(entity.Coupon, dto.UserResponse) (*entity.UsersCoupon, error)
-	GetCoupons() ([]entity.Coupon, error)
+	GetCoupons(dto.Query) ([]entity.Coupon, int64, error)
 	GetCouponsByUserId(id uint) ([]entity.UsersCoupon, error)
 	GetCouponByDiscount(discount uint) (*entity.Coupon, error)
 	GetCouponById(id uint) (*entity.Coupon, error)
 	GetUserCouponByFK(uint, uint) (*entity.UsersCoupon, error)
-	UpdateCoupon(entity.Coupon) (*entity.Coupon, error)
+	UpdateCoupon(coupon entity.Coupon, request dto.CouponUpdateRequest) (*entity.Coupon, error)
 	DeleteCoupon(entity.Coupon) error
 	UpdateUserCoupon(entity.UsersCoupon) (*entity.UsersCoupon, error)
+	Evaluate(coupon entity.Coupon, userId uint, quote dto.OrderQuote) (*dto.CouponEvaluation, error)
+	RedeemCoupon(userId uint, code string) (*entity.UsersCoupon, error)
+	AssignCouponToUsers(coupon entity.Coupon, request dto.CouponAssignmentRequest) (*dto.CouponAssignmentReport, error)
 }
 
 type couponUsecaseImpl struct {
-	userRepo   repository.UserRepository
-	couponRepo repository.CouponRepository
+	userRepo     repository.UserRepository
+	couponRepo   repository.CouponRepository
+	categoryRepo repository.CategoryRepository
+	menuRepo     repository.MenuRepository
+	unitOfWork   repository.UnitOfWork
 }
 
 type CouponUsecaseConfig struct {
-	UserRepo   repository.UserRepository
-	CouponRepo repository.CouponRepository
+	UserRepo     repository.UserRepository
+	CouponRepo   repository.CouponRepository
+	CategoryRepo repository.CategoryRepository
+	MenuRepo     repository.MenuRepository
+	UnitOfWork   repository.UnitOfWork
 }
 
 func NewCouponUsecase(c CouponUsecaseConfig) CouponUsecase {
 	return &couponUsecaseImpl{
-		userRepo:   c.UserRepo,
-		couponRepo: c.CouponRepo,
+		userRepo:     c.UserRepo,
+		couponRepo:   c.CouponRepo,
+		categoryRepo: c.CategoryRepo,
+		menuRepo:     c.MenuRepo,
+		unitOfWork:   c.UnitOfWork,
 	}
 }
 
+// redeemCodeLength is the length of generated redeem codes.
+const redeemCodeLength = 8
+
+// couponAssignmentBatchSize is how many users are given a coupon per query
+// when assigning it in bulk.
+const couponAssignmentBatchSize = 500
+
 func (c *couponUsecaseImpl) CreateCoupon(b dto.CouponRequest) (*entity.Coupon, error) {
 	coupon := entity.Coupon{
-		Code:         uuid.Must(uuid.NewV4()),
-		IssuerID:     b.IssuerID,
-		Discount:     b.Discount,
-		Description:  b.Description,
-		Availability: true,
+		Code:     uuid.Must(uuid.NewV4()),
+		IssuerID: b.IssuerID,
+	}
+
+	err := c.applyCouponRequest(&coupon, b)
+	if err != nil {
+		return nil, err
 	}
 
 	resCoupon, err := c.couponRepo.CreateCoupon(coupon)
@@ -57,19 +84,25 @@ func (c *couponUsecaseImpl) CreateCoupon(b dto.CouponRequest) (*entity.Coupon, e
 }
 
 func (c *couponUsecaseImpl) AssignCouponToUser(coupon entity.Coupon, user dto.UserResponse) (*entity.UsersCoupon, error) {
-	userCoupon, err := c.couponRepo.GetUserCouponByFK(coupon.ID, user.ID)
+	return assignCouponToUser(c.couponRepo, coupon.ID, user.ID)
+}
+
+// assignCouponToUser adds one coupon to the user's stock through couponRepo,
+// which may be bound to a transaction.
+func assignCouponToUser(couponRepo repository.CouponRepository, couponId uint, userId uint) (*entity.UsersCoupon, error) {
+	userCoupon, _ := couponRepo.GetUserCouponByFK(couponId, userId)
 	if userCoupon != nil {
 		userCoupon.Stock += 1
-		return c.couponRepo.AssignCouponToUser(*userCoupon)
+		return couponRepo.UpdateUserCoupon(*userCoupon)
 	}
 
 	userCoupon = &entity.UsersCoupon{
-		CouponID: coupon.ID,
-		UserID:   user.ID,
+		CouponID: couponId,
+		UserID:   userId,
 		Stock:    1,
 	}
 
-	userRes, err := c.couponRepo.AssignCouponToUser(*userCoupon)
+	userRes, err := couponRepo.AssignCouponToUser(*userCoupon)
 
 	if err != nil {
 		return nil, err
@@ -78,14 +111,14 @@ func (c *couponUsecaseImpl) AssignCouponToUser(coupon entity.Coupon, user dto.Us
 	return userRes, nil
 }
 
-func (c *couponUsecaseImpl) GetCoupons() ([]entity.Coupon, error) {
-	coupons, err := c.couponRepo.GetCoupons()
+func (c *couponUsecaseImpl) GetCoupons(query dto.Query) ([]entity.Coupon, int64, error) {
+	coupons, total, err := c.couponRepo.GetCoupons(query)
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return coupons, nil
+	return coupons, total, nil
 }
 
 func (c *couponUsecaseImpl) GetCouponById(id uint) (*entity.Coupon, error) {
@@ -138,7 +171,14 @@ func (c *couponUsecaseImpl) DeleteUserCoupon(userCoupon entity.UsersCoupon) erro
 	return nil
 }
 
-func (c *couponUsecaseImpl) UpdateCoupon(coupon entity.Coupon) (*entity.Coupon, error) {
+// UpdateCoupon applies the fields present in the request on top of the
+// coupon's current values and validates the result like CreateCoupon does.
+func (c *couponUsecaseImpl) UpdateCoupon(coupon entity.Coupon, request dto.CouponUpdateRequest) (*entity.Coupon, error) {
+	err := c.applyCouponRequest(&coupon, mergeCouponUpdate(coupon, request))
+	if err != nil {
+		return nil, err
+	}
+
 	resCoupon, err := c.couponRepo.UpdateCoupon(coupon)
 
 	if err != nil {
@@ -159,19 +199,443 @@ func (c *couponUsecaseImpl) DeleteCoupon(coupon entity.Coupon) error {
 }
 
 func (c *couponUsecaseImpl) UpdateUserCoupon(userCoupon entity.UsersCoupon) (*entity.UsersCoupon, error) {
-	if userCoupon.Stock == 0 {
-		err := c.couponRepo.DeleteUserCoupon(userCoupon)
+	resUserCoupon, err := c.couponRepo.UpdateUserCoupon(userCoupon)
+
+	if err != nil {
+		return nil, err
+	}
 
+	return resUserCoupon, nil
+}
+
+// Evaluate checks the coupon against the quoted order and returns the
+// discount it gives, or the reason it cannot be used. The minimum subtotal and
+// the discount are both measured on what is left after the promotions.
+func (c *couponUsecaseImpl) Evaluate(coupon entity.Coupon, userId uint, quote dto.OrderQuote) (*dto.CouponEvaluation, error) {
+	if !coupon.Availability {
+		return nil, domain.ErrCouponUnavailable
+	}
+
+	now := time.Now()
+	if coupon.ValidFrom != nil && now.Before(*coupon.ValidFrom) {
+		return nil, domain.ErrCouponNotStarted
+	}
+	if coupon.ValidUntil != nil && now.After(*coupon.ValidUntil) {
+		return nil, domain.ErrCouponExpired
+	}
+
+	if quote.Subtotal-quote.PromotionDiscount < coupon.MinSubtotal {
+		return nil, fmt.Errorf("%w: spend at least %d", domain.ErrCouponMinSubtotal, coupon.MinSubtotal)
+	}
+
+	base, ok := couponEligibleSubtotal(coupon, quote)
+	if !ok {
+		return nil, domain.ErrCouponNotEligible
+	}
+
+	if coupon.UsageLimitPerUser > 0 {
+		used, err := c.couponRepo.CountUserCouponOrders(coupon.ID, userId)
 		if err != nil {
-			return nil, err
+			return nil, domain.ErrInternalServer
+		}
+		// checked again when the order is placed, see placeOrder
+		if used >= int64(coupon.UsageLimitPerUser) {
+			return nil, domain.ErrCouponUsageLimit
 		}
 	}
 
-	resUserCoupon, err := c.couponRepo.UpdateUserCoupon(userCoupon)
+	evaluation := dto.CouponEvaluation{CouponID: coupon.ID}
+	switch coupon.Type {
+	case entity.CouponTypeFreeDelivery:
+		evaluation.DeliveryDiscount = quote.DeliveryFee
+	case entity.CouponTypePercentage:
+		evaluation.Discount = base * coupon.Discount / 100
+		if coupon.MaxDiscount > 0 && evaluation.Discount > coupon.MaxDiscount {
+			evaluation.Discount = coupon.MaxDiscount
+		}
+	default:
+		evaluation.Discount = coupon.Discount
+	}
+
+	if evaluation.Discount > base {
+		evaluation.Discount = base
+	}
+
+	return &evaluation, nil
+}
+
+// AssignCouponToUsers gives quantity of the coupon to the listed users or to
+// the users of the segment. All batches run in one transaction, so either
+// every user receives the coupon or none does.
+func (c *couponUsecaseImpl) AssignCouponToUsers(coupon entity.Coupon, request dto.CouponAssignmentRequest) (*dto.CouponAssignmentReport, error) {
+	if !coupon.Availability {
+		return nil, domain.ErrCouponUnavailable
+	}
 
+	if request.Quantity == 0 {
+		request.Quantity = 1
+	}
+	if request.Quantity < 0 {
+		return nil, fmt.Errorf("%w: quantity must be greater than 0", domain.ErrInvalidCouponAssignment)
+	}
+
+	report := dto.CouponAssignmentReport{
+		CouponID:       coupon.ID,
+		Quantity:       request.Quantity,
+		MissingUserIDs: []uint{},
+	}
+
+	var userIds []uint
+	switch {
+	case len(request.UserIDs) > 0 && request.Segment != nil:
+		return nil, fmt.Errorf("%w: use either user_ids or segment", domain.ErrInvalidCouponAssignment)
+	case len(request.UserIDs) > 0:
+		requestedIds := util.UniqueUint(request.UserIDs)
+		existingIds, err := c.userRepo.GetExistingUserIDs(requestedIds)
+		if err != nil {
+			return nil, domain.ErrInternalServer
+		}
+
+		existing := map[uint]bool{}
+		for _, userId := range existingIds {
+			existing[userId] = true
+		}
+		for _, userId := range requestedIds {
+			if !existing[userId] {
+				report.MissingUserIDs = append(report.MissingUserIDs, userId)
+			}
+		}
+
+		userIds = existingIds
+	case request.Segment != nil && !request.Segment.IsEmpty():
+		if request.Segment.MinOrders < 0 || request.Segment.TopPlayers < 0 {
+			return nil, fmt.Errorf("%w: segment filters cannot be negative", domain.ErrInvalidCouponAssignment)
+		}
+
+		var err error
+		userIds, err = c.userRepo.GetUserIDsBySegment(*request.Segment)
+		if err != nil {
+			return nil, domain.ErrInternalServer
+		}
+	default:
+		return nil, fmt.Errorf("%w: user_ids or a segment filter is required", domain.ErrInvalidCouponAssignment)
+	}
+	report.Matched = len(userIds) + len(report.MissingUserIDs)
+
+	err := c.unitOfWork.Do(func(repos repository.TxRepositories) error {
+		for start := 0; start < len(userIds); start += couponAssignmentBatchSize {
+			end := start + couponAssignmentBatchSize
+			if end > len(userIds) {
+				end = len(userIds)
+			}
+
+			err := repos.CouponRepo.AssignCouponToUsers(coupon.ID, userIds[start:end], request.Quantity)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	report.Assigned = len(userIds)
+	return &report, nil
+}
+
+// RedeemCoupon claims the coupon with the redeem code for the user. The coupon
+// row stays locked while the claims are counted, so the limits hold when many
+// users redeem the same code at once.
+func (c *couponUsecaseImpl) RedeemCoupon(userId uint, code string) (*entity.UsersCoupon, error) {
+	code = normalizeRedeemCode(code)
+	if code == "" {
+		return nil, domain.ErrRedeemCodeNotFound
+	}
+
+	var userCoupon *entity.UsersCoupon
+	err := c.unitOfWork.Do(func(repos repository.TxRepositories) error {
+		coupon, err := repos.CouponRepo.LockCouponByRedeemCode(code)
+		if err != nil {
+			return err
+		}
+
+		if !coupon.Availability {
+			return domain.ErrCouponUnavailable
+		}
+		if coupon.ValidUntil != nil && time.Now().After(*coupon.ValidUntil) {
+			return domain.ErrCouponExpired
+		}
+
+		if coupon.RedemptionLimit > 0 {
+			claimed, err := repos.CouponRepo.CountCouponClaims(coupon.ID)
+			if err != nil {
+				return err
+			}
+			if claimed >= int64(coupon.RedemptionLimit) {
+				return domain.ErrCouponFullyRedeemed
+			}
+		}
+
+		if coupon.ClaimLimitPerUser > 0 {
+			claimed, err := repos.CouponRepo.CountUserCouponClaims(coupon.ID, userId)
+			if err != nil {
+				return err
+			}
+			if claimed >= int64(coupon.ClaimLimitPerUser) {
+				return domain.ErrCouponClaimLimit
+			}
+		}
+
+		err = repos.CouponRepo.CreateCouponClaim(entity.CouponClaim{CouponID: coupon.ID, UserID: userId})
+		if err != nil {
+			return err
+		}
+
+		userCoupon, err = assignCouponToUser(repos.CouponRepo, coupon.ID, userId)
+		if err != nil {
+			return err
+		}
+		userCoupon.Coupon = *coupon
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return resUserCoupon, nil
+	return userCoupon, nil
+}
+
+func normalizeRedeemCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// couponRedeemCode returns the redeem code the request asks for, checking it
+// is not used by another coupon.
+func (c *couponUsecaseImpl) couponRedeemCode(coupon entity.Coupon, request dto.CouponRequest) (string, error) {
+	code := normalizeRedeemCode(request.RedeemCode)
+	if code == "" && !request.GenerateRedeemCode {
+		return "", nil
+	}
+
+	if code == "" {
+		for attempt := 0; attempt < 5; attempt++ {
+			generated, err := util.RandomCode(redeemCodeLength)
+			if err != nil {
+				return "", domain.ErrInternalServer
+			}
+
+			_, err = c.couponRepo.GetCouponByRedeemCode(generated)
+			if errors.Is(err, domain.ErrRedeemCodeNotFound) {
+				return generated, nil
+			}
+			if err != nil {
+				return "", domain.ErrInternalServer
+			}
+		}
+
+		return "", domain.ErrInternalServer
+	}
+
+	isRedeemCode, _ := util.IsRedeemCode(code)
+	if !isRedeemCode {
+		return "", fmt.Errorf("%w: redeem_code must be 4 to 20 letters or numbers", domain.ErrInvalidCoupon)
+	}
+
+	existing, err := c.couponRepo.GetCouponByRedeemCode(code)
+	if err != nil && !errors.Is(err, domain.ErrRedeemCodeNotFound) {
+		return "", domain.ErrInternalServer
+	}
+	if existing != nil && existing.ID != coupon.ID {
+		return "", domain.ErrRedeemCodeTaken
+	}
+
+	return code, nil
+}
+
+// couponEligibleSubtotal sums what the lines the coupon applies to have left
+// to pay after the promotions. Without restrictions the whole subtotal,
+// including a promotion price, is eligible.
+func couponEligibleSubtotal(coupon entity.Coupon, quote dto.OrderQuote) (int, bool) {
+	if len(coupon.EligibleCategories) == 0 && len(coupon.EligibleMenus) == 0 {
+		return quote.Subtotal - quote.PromotionDiscount, true
+	}
+
+	eligible := false
+	base := 0
+	for _, line := range quote.Lines {
+		if isCouponEligibleLine(coupon, line) {
+			eligible = true
+			base += line.Subtotal - line.PromotionDiscount
+		}
+	}
+
+	return base, eligible
+}
+
+func isCouponEligibleLine(coupon entity.Coupon, line dto.OrderQuoteLine) bool {
+	for _, menu := range coupon.EligibleMenus {
+		if menu.ID == line.MenuID {
+			return true
+		}
+	}
+
+	for _, category := range coupon.EligibleCategories {
+		for _, categoryId := range line.CategoryIDs {
+			if category.ID == categoryId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// mergeCouponUpdate returns the coupon as a CouponRequest with the fields of
+// the update that are set.
+func mergeCouponUpdate(coupon entity.Coupon, update dto.CouponUpdateRequest) dto.CouponRequest {
+	request := dto.CouponRequest{
+		Description:        coupon.Description,
+		Type:               coupon.Type,
+		Discount:           coupon.Discount,
+		MaxDiscount:        coupon.MaxDiscount,
+		MinSubtotal:        coupon.MinSubtotal,
+		ValidFrom:          coupon.ValidFrom,
+		ValidUntil:         coupon.ValidUntil,
+		UsageLimitPerUser:  coupon.UsageLimitPerUser,
+		RedeemCode:         coupon.RedeemCode,
+		GenerateRedeemCode: update.GenerateRedeemCode,
+		RedemptionLimit:    coupon.RedemptionLimit,
+		ClaimLimitPerUser:  coupon.ClaimLimitPerUser,
+		Availability:       &coupon.Availability,
+	}
+	for _, category := range coupon.EligibleCategories {
+		request.CategoryIDs = append(request.CategoryIDs, category.ID)
+	}
+	for _, menu := range coupon.EligibleMenus {
+		request.MenuIDs = append(request.MenuIDs, menu.ID)
+	}
+
+	if update.Description != nil {
+		request.Description = *update.Description
+	}
+	if update.Type != nil {
+		request.Type = *update.Type
+	}
+	if update.Discount != nil {
+		request.Discount = *update.Discount
+	}
+	if update.MaxDiscount != nil {
+		request.MaxDiscount = *update.MaxDiscount
+	}
+	if update.MinSubtotal != nil {
+		request.MinSubtotal = *update.MinSubtotal
+	}
+	if update.ValidFrom != nil {
+		request.ValidFrom = update.ValidFrom
+	}
+	if update.ValidUntil != nil {
+		request.ValidUntil = update.ValidUntil
+	}
+	if update.UsageLimitPerUser != nil {
+		request.UsageLimitPerUser = *update.UsageLimitPerUser
+	}
+	if update.RedeemCode != nil {
+		request.RedeemCode = *update.RedeemCode
+	}
+	if update.GenerateRedeemCode && update.RedeemCode == nil {
+		request.RedeemCode = ""
+	}
+	if update.RedemptionLimit != nil {
+		request.RedemptionLimit = *update.RedemptionLimit
+	}
+	if update.ClaimLimitPerUser != nil {
+		request.ClaimLimitPerUser = *update.ClaimLimitPerUser
+	}
+	if update.CategoryIDs != nil {
+		request.CategoryIDs = *update.CategoryIDs
+	}
+	if update.MenuIDs != nil {
+		request.MenuIDs = *update.MenuIDs
+	}
+	if update.Availability != nil {
+		request.Availability = update.Availability
+	}
+
+	return request
+}
+
+// applyCouponRequest validates the request and copies it onto the coupon.
+func (c *couponUsecaseImpl) applyCouponRequest(coupon *entity.Coupon, request dto.CouponRequest) error {
+	if strings.TrimSpace(request.Description) == "" {
+		return fmt.Errorf("%w: description is required", domain.ErrInvalidCoupon)
+	}
+	if request.Type == "" {
+		request.Type = entity.CouponTypeFixed
+	}
+
+	switch request.Type {
+	case entity.CouponTypeFixed:
+		if request.Discount <= 0 {
+			return fmt.Errorf("%w: discount must be greater than 0", domain.ErrInvalidCoupon)
+		}
+	case entity.CouponTypePercentage:
+		if request.Discount <= 0 || request.Discount > 100 {
+			return fmt.Errorf("%w: percentage discount must be between 1 and 100", domain.ErrInvalidCoupon)
+		}
+	case entity.CouponTypeFreeDelivery:
+		request.Discount = 0
+	default:
+		return fmt.Errorf("%w: type must be fixed, percentage or free_delivery", domain.ErrInvalidCoupon)
+	}
+
+	if request.MaxDiscount < 0 || request.MinSubtotal < 0 || request.UsageLimitPerUser < 0 ||
+		request.RedemptionLimit < 0 || request.ClaimLimitPerUser < 0 {
+		return fmt.Errorf("%w: limits cannot be negative", domain.ErrInvalidCoupon)
+	}
+	if request.ValidFrom != nil && request.ValidUntil != nil && !request.ValidUntil.After(*request.ValidFrom) {
+		return fmt.Errorf("%w: valid_until must be after valid_from", domain.ErrInvalidCoupon)
+	}
+
+	categoryIds := util.UniqueUint(request.CategoryIDs)
+	categories, err := c.categoryRepo.GetCategoriesByIDs(categoryIds)
+	if err != nil {
+		return domain.ErrInternalServer
+	}
+	if len(categories) != len(categoryIds) {
+		return fmt.Errorf("%w: unknown category in category_ids", domain.ErrInvalidCoupon)
+	}
+
+	redeemCode, err := c.couponRedeemCode(*coupon, request)
+	if err != nil {
+		return err
+	}
+
+	var menus []entity.Menu
+	for _, menuId := range util.UniqueUint(request.MenuIDs) {
+		menu, _ := c.menuRepo.GetMenuById(menuId)
+		if menu == nil {
+			return fmt.Errorf("%w: no menu with id %d", domain.ErrInvalidCoupon, menuId)
+		}
+		menus = append(menus, entity.Menu{Model: menu.Model})
+	}
+
+	coupon.Description = request.Description
+	coupon.Type = request.Type
+	coupon.Discount = request.Discount
+	coupon.MaxDiscount = request.MaxDiscount
+	coupon.MinSubtotal = request.MinSubtotal
+	coupon.ValidFrom = request.ValidFrom
+	coupon.ValidUntil = request.ValidUntil
+	coupon.UsageLimitPerUser = request.UsageLimitPerUser
+	coupon.RedeemCode = redeemCode
+	coupon.RedemptionLimit = request.RedemptionLimit
+	coupon.ClaimLimitPerUser = request.ClaimLimitPerUser
+	coupon.Availability = isAvailable(request.Availability)
+	coupon.EligibleCategories = categories
+	coupon.EligibleMenus = menus
+
+	return nil
 }
@@ -0,0 +1,304 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"testing"
+	"time"
+)
+
+// couponQuote has a 60000 burger in category 1 and a 40000 drink in category 2,
+// delivered for 10000.
+func couponQuote() dto.OrderQuote {
+	return dto.OrderQuote{
+		Lines: []dto.OrderQuoteLine{
+			{MenuID: 1, Subtotal: 60000, CategoryIDs: []uint{1}},
+			{MenuID: 2, Subtotal: 40000, CategoryIDs: []uint{2}},
+		},
+		Subtotal:    100000,
+		DeliveryFee: 10000,
+	}
+}
+
+func testCategory(id uint) entity.Category {
+	category := entity.Category{}
+	category.ID = id
+	return category
+}
+
+func TestEvaluateCoupon(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name                 string
+		coupon               entity.Coupon
+		usedOrders           int64
+		wantDiscount         int
+		wantDeliveryDiscount int
+		wantErr              error
+	}{
+		{
+			name:         "fixed",
+			coupon:       entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000},
+			wantDiscount: 15000,
+		},
+		{
+			name:    "unavailable",
+			coupon:  entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, Availability: false},
+			wantErr: domain.ErrCouponUnavailable,
+		},
+		{
+			name:    "not started",
+			coupon:  entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, ValidFrom: &future},
+			wantErr: domain.ErrCouponNotStarted,
+		},
+		{
+			name:    "expired",
+			coupon:  entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, ValidUntil: &past},
+			wantErr: domain.ErrCouponExpired,
+		},
+		{
+			name:         "inside the validity window",
+			coupon:       entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, ValidFrom: &past, ValidUntil: &future},
+			wantDiscount: 15000,
+		},
+		{
+			name:    "below the minimum subtotal",
+			coupon:  entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, MinSubtotal: 100001},
+			wantErr: domain.ErrCouponMinSubtotal,
+		},
+		{
+			name:         "at the minimum subtotal",
+			coupon:       entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, MinSubtotal: 100000},
+			wantDiscount: 15000,
+		},
+		{
+			name:         "percentage of the eligible category",
+			coupon:       entity.Coupon{Type: entity.CouponTypePercentage, Discount: 10, EligibleCategories: []entity.Category{testCategory(2)}},
+			wantDiscount: 4000,
+		},
+		{
+			name:         "percentage of the eligible menu",
+			coupon:       entity.Coupon{Type: entity.CouponTypePercentage, Discount: 10, EligibleMenus: []entity.Menu{*testMenu(1, "", 0)}},
+			wantDiscount: 6000,
+		},
+		{
+			name:    "nothing eligible",
+			coupon:  entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, EligibleMenus: []entity.Menu{*testMenu(9, "", 0)}},
+			wantErr: domain.ErrCouponNotEligible,
+		},
+		{
+			name:         "percentage cap",
+			coupon:       entity.Coupon{Type: entity.CouponTypePercentage, Discount: 50, MaxDiscount: 20000},
+			wantDiscount: 20000,
+		},
+		{
+			name:                 "free delivery",
+			coupon:               entity.Coupon{Type: entity.CouponTypeFreeDelivery},
+			wantDeliveryDiscount: 10000,
+		},
+		{
+			name:         "fixed capped at the eligible base",
+			coupon:       entity.Coupon{Type: entity.CouponTypeFixed, Discount: 50000, EligibleCategories: []entity.Category{testCategory(2)}},
+			wantDiscount: 40000,
+		},
+		{
+			name:         "under the usage limit",
+			coupon:       entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, UsageLimitPerUser: 2},
+			usedOrders:   1,
+			wantDiscount: 15000,
+		},
+		{
+			name:       "usage limit reached",
+			coupon:     entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, UsageLimitPerUser: 2},
+			usedOrders: 2,
+			wantErr:    domain.ErrCouponUsageLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr != domain.ErrCouponUnavailable {
+				tt.coupon.Availability = true
+			}
+			couponUsecase := NewCouponUsecase(CouponUsecaseConfig{
+				CouponRepo: &fakeCouponRepo{usedOrders: tt.usedOrders},
+			})
+
+			evaluation, err := couponUsecase.Evaluate(tt.coupon, 1, couponQuote())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if evaluation.Discount != tt.wantDiscount || evaluation.DeliveryDiscount != tt.wantDeliveryDiscount {
+				t.Errorf("expected %d off the menus and %d off the delivery, got %+v", tt.wantDiscount, tt.wantDeliveryDiscount, evaluation)
+			}
+		})
+	}
+}
+
+func TestCheckCouponUsageLimit(t *testing.T) {
+	couponRepo := &fakeCouponRepo{coupons: map[uint]*entity.Coupon{
+		1: {UsageLimitPerUser: 1},
+		2: {},
+	}}
+
+	couponRepo.usedOrders = 1
+	if err := checkCouponUsageLimit(couponRepo, 1, 1); !errors.Is(err, domain.ErrCouponUsageLimit) {
+		t.Errorf("expected a used up coupon to be rejected, got %v", err)
+	}
+	if err := checkCouponUsageLimit(couponRepo, 2, 1); err != nil {
+		t.Errorf("expected a coupon without a limit to pass, got %v", err)
+	}
+
+	couponRepo.usedOrders = 0
+	if err := checkCouponUsageLimit(couponRepo, 1, 1); err != nil {
+		t.Errorf("expected an unused coupon to pass, got %v", err)
+	}
+}
+
+func TestUpdateCouponKeepsOmittedFields(t *testing.T) {
+	validUntil := time.Now().Add(24 * time.Hour)
+	stored := &entity.Coupon{
+		Description:        "Weekend deal",
+		Type:               entity.CouponTypePercentage,
+		Discount:           20,
+		MaxDiscount:        25000,
+		MinSubtotal:        50000,
+		ValidUntil:         &validUntil,
+		UsageLimitPerUser:  2,
+		RedeemCode:         "WEEKEND20",
+		RedemptionLimit:    100,
+		Availability:       true,
+		EligibleCategories: []entity.Category{testCategory(1)},
+	}
+	stored.ID = 5
+
+	newCouponUsecase := func() CouponUsecase {
+		coupon := *stored
+		return NewCouponUsecase(CouponUsecaseConfig{
+			CouponRepo:   &fakeCouponRepo{coupons: map[uint]*entity.Coupon{5: &coupon}},
+			CategoryRepo: &fakeCategoryRepo{categories: stored.EligibleCategories},
+			MenuRepo:     &fakeMenuRepo{},
+		})
+	}
+
+	couponUsecase := newCouponUsecase()
+	discount := 30
+	coupon, err := couponUsecase.UpdateCoupon(*stored, dto.CouponUpdateRequest{Discount: &discount})
+	if err != nil {
+		t.Fatalf("update coupon: %v", err)
+	}
+
+	if coupon.Discount != 30 || coupon.Description != stored.Description || coupon.Type != stored.Type ||
+		coupon.MaxDiscount != stored.MaxDiscount || coupon.MinSubtotal != stored.MinSubtotal ||
+		coupon.ValidUntil != stored.ValidUntil || coupon.UsageLimitPerUser != stored.UsageLimitPerUser ||
+		coupon.RedeemCode != stored.RedeemCode || coupon.RedemptionLimit != stored.RedemptionLimit ||
+		!coupon.Availability || len(coupon.EligibleCategories) != 1 {
+		t.Errorf("expected only the discount to change, got %+v", coupon)
+	}
+
+	couponUsecase = newCouponUsecase()
+	noCode := ""
+	noCategories := []uint{}
+	coupon, err = couponUsecase.UpdateCoupon(*stored, dto.CouponUpdateRequest{RedeemCode: &noCode, CategoryIDs: &noCategories})
+	if err != nil {
+		t.Fatalf("clear redeem code: %v", err)
+	}
+	if coupon.RedeemCode != "" || len(coupon.EligibleCategories) != 0 {
+		t.Errorf("expected the redeem code and categories to be removed, got %q and %+v", coupon.RedeemCode, coupon.EligibleCategories)
+	}
+
+	couponUsecase = newCouponUsecase()
+	empty := ""
+	_, err = couponUsecase.UpdateCoupon(*stored, dto.CouponUpdateRequest{Description: &empty})
+	if !errors.Is(err, domain.ErrInvalidCoupon) {
+		t.Errorf("expected an empty description to be rejected, got %v", err)
+	}
+}
+
+func TestEvaluateCouponAfterPromotions(t *testing.T) {
+	quote := couponQuote()
+	quote.Lines[0].PromotionDiscount = 20000
+	quote.PromotionDiscount = 20000
+
+	tests := []struct {
+		name         string
+		coupon       entity.Coupon
+		wantDiscount int
+		wantErr      error
+	}{
+		{
+			name:         "percentage of what is left",
+			coupon:       entity.Coupon{Type: entity.CouponTypePercentage, Discount: 10},
+			wantDiscount: 8000,
+		},
+		{
+			name:         "percentage of what the eligible menu has left",
+			coupon:       entity.Coupon{Type: entity.CouponTypePercentage, Discount: 10, EligibleMenus: []entity.Menu{*testMenu(1, "", 0)}},
+			wantDiscount: 4000,
+		},
+		{
+			name:         "fixed capped at what the eligible menu has left",
+			coupon:       entity.Coupon{Type: entity.CouponTypeFixed, Discount: 50000, EligibleMenus: []entity.Menu{*testMenu(1, "", 0)}},
+			wantDiscount: 40000,
+		},
+		{
+			name:    "minimum subtotal after promotions",
+			coupon:  entity.Coupon{Type: entity.CouponTypeFixed, Discount: 15000, MinSubtotal: 90000},
+			wantErr: domain.ErrCouponMinSubtotal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.coupon.Availability = true
+			couponUsecase := NewCouponUsecase(CouponUsecaseConfig{CouponRepo: &fakeCouponRepo{}})
+
+			evaluation, err := couponUsecase.Evaluate(tt.coupon, 1, quote)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && evaluation.Discount != tt.wantDiscount {
+				t.Errorf("expected %d off, got %d", tt.wantDiscount, evaluation.Discount)
+			}
+		})
+	}
+}
+
+func TestCreateCouponKeepsAvailability(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name         string
+		availability *bool
+		want         bool
+	}{
+		{"left out", nil, true},
+		{"disabled", &disabled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			couponUsecase := NewCouponUsecase(CouponUsecaseConfig{
+				CouponRepo:   &fakeCouponRepo{},
+				CategoryRepo: &fakeCategoryRepo{},
+				MenuRepo:     &fakeMenuRepo{},
+			})
+
+			coupon, err := couponUsecase.CreateCoupon(dto.CouponRequest{Description: "Welcome", Discount: 10000, Availability: tt.availability})
+			if err != nil {
+				t.Fatalf("create coupon: %v", err)
+			}
+			if coupon.Availability != tt.want {
+				t.Errorf("expected availability %v, got %v", tt.want, coupon.Availability)
+			}
+		})
+	}
+}
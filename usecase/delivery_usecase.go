@@ -2,34 +2,48 @@ package usecase
 
 import (
 	"final-project-backend/domain"
+	"final-project-backend/dto"
 	"final-project-backend/entity"
 	"final-project-backend/repository"
-	"time"
 )
 
 var DeliveryStatus = map[string]string{
 	"pending":    "Pending",
 	"on the way": "On the way",
 	"delivered":  "Delivered",
+	"cancelled":  "Cancelled",
+}
+
+// deliveryOrderStatusPath is the way an order goes through the kitchen and out
+// for delivery. Delivery statuses move the order along it.
+var deliveryOrderStatusPath = []string{
+	OrderStatusPlaced,
+	OrderStatusConfirmed,
+	OrderStatusPreparing,
+	OrderStatusOnTheWay,
+	OrderStatusDelivered,
 }
 
 type DeliveryUsecase interface {
 	CreateDelivery(entity.Delivery) (*entity.Delivery, error)
-	UpdateDeliveryStatus(entity.Delivery) (*entity.Delivery, error)
+	UpdateDeliveryStatus(delivery entity.Delivery, actor dto.UserResponse, note string) (*entity.Delivery, error)
 	GetDeliveryById(id uint) (*entity.Delivery, error)
 }
 
 type deliveryUsecaseImpl struct {
 	deliveryRepo repository.DeliveryRepository
+	orderUsecase OrderUsecase
 }
 
 type DeliveryUsecaseConfig struct {
 	DeliveryRepo repository.DeliveryRepository
+	OrderUsecase OrderUsecase
 }
 
 func NewDeliveryUsecase(c DeliveryUsecaseConfig) DeliveryUsecase {
 	return &deliveryUsecaseImpl{
 		deliveryRepo: c.DeliveryRepo,
+		orderUsecase: c.OrderUsecase,
 	}
 }
 
@@ -51,19 +65,63 @@ func (d *deliveryUsecaseImpl) GetDeliveryById(id uint) (*entity.Delivery, error)
 	return deliveryRes, nil
 }
 
-func (d *deliveryUsecaseImpl) UpdateDeliveryStatus(delivery entity.Delivery) (*entity.Delivery, error) {
+// UpdateDeliveryStatus moves the delivery's order through the order status
+// state machine. Besides order statuses, the delivery statuses the endpoint
+// always took are accepted: "pending" keeps an order that has not left the
+// kitchen as it is, and "on the way" or "delivered" first take the order
+// through the statuses it skipped, recording each on its timeline.
+func (d *deliveryUsecaseImpl) UpdateDeliveryStatus(delivery entity.Delivery, actor dto.UserResponse, note string) (*entity.Delivery, error) {
 	if _, ok := DeliveryStatus[delivery.Status]; !ok {
-		return nil, domain.ErrInvalidDeliveryStatus
+		if _, ok := OrderStatusTransitions[delivery.Status]; !ok {
+			return nil, domain.ErrInvalidDeliveryStatus
+		}
+	}
+
+	order, err := d.orderUsecase.GetOrderByID(delivery.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := currentOrderStatus(order.Status)
+	if delivery.Status == "pending" {
+		if deliveryStatusByOrderStatus[current] != "pending" {
+			return nil, domain.ErrInvalidStatusTransition
+		}
+
+		return d.deliveryRepo.GetDeliveryByID(delivery.ID)
 	}
 
-	if delivery.Status == "on the way" {
-		delivery.DeliveryDate = time.Now()
+	for _, status := range skippedOrderStatuses(current, delivery.Status) {
+		_, err = d.orderUsecase.UpdateOrderStatus(delivery.OrderID, status, actor, "")
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	deliveryRes, err := d.deliveryRepo.UpdateDeliveryStatus(delivery)
+	_, err = d.orderUsecase.UpdateOrderStatus(delivery.OrderID, delivery.Status, actor, note)
 	if err != nil {
 		return nil, err
 	}
 
-	return deliveryRes, nil
+	return d.deliveryRepo.GetDeliveryByID(delivery.ID)
+}
+
+// skippedOrderStatuses returns the statuses between from and to on the
+// delivery path, or none when to does not lie ahead of from.
+func skippedOrderStatuses(from string, to string) []string {
+	fromIndex, toIndex := -1, -1
+	for i, status := range deliveryOrderStatusPath {
+		if status == from {
+			fromIndex = i
+		}
+		if status == to {
+			toIndex = i
+		}
+	}
+
+	if fromIndex < 0 || toIndex <= fromIndex+1 {
+		return nil
+	}
+
+	return deliveryOrderStatusPath[fromIndex+1 : toIndex]
 }
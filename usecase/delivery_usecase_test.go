@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"testing"
+)
+
+func TestUpdateDeliveryStatusAcceptsDeliveryStatuses(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       string
+		status     string
+		wantStatus string
+		wantEvents int
+		wantErr    error
+	}{
+		{"pending keeps a placed order", OrderStatusPlaced, "pending", OrderStatusPlaced, 0, nil},
+		{"pending keeps a preparing order", OrderStatusPreparing, "pending", OrderStatusPreparing, 0, nil},
+		{"pending cannot bring an order back", OrderStatusOnTheWay, "pending", OrderStatusOnTheWay, 0, domain.ErrInvalidStatusTransition},
+		{"on the way walks a placed order", OrderStatusPlaced, "on the way", OrderStatusOnTheWay, 3, nil},
+		{"delivered walks a confirmed order", OrderStatusConfirmed, "delivered", OrderStatusDelivered, 3, nil},
+		{"order statuses still work", OrderStatusPlaced, OrderStatusConfirmed, OrderStatusConfirmed, 1, nil},
+		{"cancelled orders stay cancelled", OrderStatusCancelled, "delivered", OrderStatusCancelled, 0, domain.ErrInvalidStatusTransition},
+		{"unknown statuses are rejected", OrderStatusPlaced, "lost", OrderStatusPlaced, 0, domain.ErrInvalidDeliveryStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOrderStatusFixture(tt.from, false)
+			deliveryUsecase := NewDeliveryUsecase(DeliveryUsecaseConfig{
+				DeliveryRepo: f.deliveryRepo,
+				OrderUsecase: f.orderUsecase,
+			})
+
+			delivery := *f.deliveryRepo.deliveries[1]
+			delivery.Status = tt.status
+			_, err := deliveryUsecase.UpdateDeliveryStatus(delivery, dto.UserResponse{ID: 9}, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			if status := f.orderRepo.orders[1].Status; status != tt.wantStatus {
+				t.Errorf("expected the order to be %q, got %q", tt.wantStatus, status)
+			}
+			if len(f.orderRepo.events) != tt.wantEvents {
+				t.Errorf("expected %d timeline events, got %+v", tt.wantEvents, f.orderRepo.events)
+			}
+		})
+	}
+}
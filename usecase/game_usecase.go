@@ -18,7 +18,7 @@ var (
 type GameUsecase interface {
 	CreateGame(entity.Game) (*entity.Game, *dto.TriviaAPIResponse, error)
 	AnswerGameQuestion(dto.GameRequest) (*entity.Game, error)
-	GetGamesLeaderboard() ([]entity.GameLeaderboard, error)
+	GetGamesLeaderboard(dto.Query) ([]entity.GameLeaderboard, int64, error)
 }
 
 type gameUsecaseImpl struct {
@@ -105,6 +105,7 @@ func (u *gameUsecaseImpl) AnswerGameQuestion(input dto.GameRequest) (*entity.Gam
 	coupon := entity.Coupon{
 		Code:         uuid.Must(uuid.NewV4()),
 		Description:  "Game Prize Coupon",
+		Type:         entity.CouponTypeFixed,
 		Discount:     *game.Score,
 		IssuerID:     user.ID,
 		Availability: true,
@@ -146,11 +147,11 @@ func (u *gameUsecaseImpl) AnswerGameQuestion(input dto.GameRequest) (*entity.Gam
 	return gameRes, nil
 }
 
-func (u *gameUsecaseImpl) GetGamesLeaderboard() ([]entity.GameLeaderboard, error) {
-	gamesLeaderboard, err := u.gameRepo.GetGamesLeaderboard()
+func (u *gameUsecaseImpl) GetGamesLeaderboard(query dto.Query) ([]entity.GameLeaderboard, int64, error) {
+	gamesLeaderboard, total, err := u.gameRepo.GetGamesLeaderboard(query)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return gamesLeaderboard, nil
+	return gamesLeaderboard, total, nil
 }
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"fmt"
+	"net/http"
+)
+
+type IdempotencyUsecase interface {
+	Begin(userId uint, key string, requestHash string) (*entity.IdempotencyKey, error)
+	Complete(idempotencyKey entity.IdempotencyKey, statusCode int, responseBody string) error
+}
+
+type idempotencyUsecaseImpl struct {
+	idempotencyRepo repository.IdempotencyRepository
+}
+
+type IdempotencyUsecaseConfig struct {
+	IdempotencyRepo repository.IdempotencyRepository
+}
+
+func NewIdempotencyUsecase(c IdempotencyUsecaseConfig) IdempotencyUsecase {
+	return &idempotencyUsecaseImpl{
+		idempotencyRepo: c.IdempotencyRepo,
+	}
+}
+
+// Begin reserves the key for the user. A returned key with a zero StatusCode
+// is a fresh reservation and the request should run; otherwise it holds the
+// stored response to replay.
+func (u *idempotencyUsecaseImpl) Begin(userId uint, key string, requestHash string) (*entity.IdempotencyKey, error) {
+	if key == "" || len(key) > 255 {
+		return nil, domain.ErrInvalidIdempotencyKey
+	}
+
+	idempotencyKey, _ := u.idempotencyRepo.GetIdempotencyKey(userId, key)
+	if idempotencyKey != nil {
+		if idempotencyKey.RequestHash != requestHash {
+			return nil, domain.ErrIdempotencyKeyMismatch
+		}
+
+		if idempotencyKey.StatusCode == 0 {
+			return nil, domain.ErrIdempotencyKeyInProgress
+		}
+
+		return idempotencyKey, nil
+	}
+
+	idempotencyKey, err := u.idempotencyRepo.CreateIdempotencyKey(entity.IdempotencyKey{
+		Key:         key,
+		UserID:      userId,
+		RequestHash: requestHash,
+	})
+	// another request reserved the same key between the lookup and insert
+	if errors.Is(err, domain.ErrIdempotencyKeyInProgress) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return idempotencyKey, nil
+}
+
+// Complete stores the response for replay. Server errors release the key
+// instead so the client can retry the request, and so does a response that
+// could not be stored, since the key would otherwise stay in progress.
+func (u *idempotencyUsecaseImpl) Complete(idempotencyKey entity.IdempotencyKey, statusCode int, responseBody string) error {
+	if statusCode >= http.StatusInternalServerError {
+		return u.idempotencyRepo.DeleteIdempotencyKey(idempotencyKey)
+	}
+
+	idempotencyKey.StatusCode = statusCode
+	idempotencyKey.ResponseBody = responseBody
+
+	_, err := u.idempotencyRepo.UpdateIdempotencyKey(idempotencyKey)
+	if err != nil {
+		deleteErr := u.idempotencyRepo.DeleteIdempotencyKey(idempotencyKey)
+		if deleteErr != nil {
+			return fmt.Errorf("store response: %v, release key: %w", err, deleteErr)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"final-project-backend/config"
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+	"sync"
+	"time"
+)
+
+const availabilityTimeLayout = "15:04"
+
+var (
+	scheduleLocation     *time.Location
+	scheduleLocationOnce sync.Once
+)
+
+// menuScheduleLocation returns the time zone availability windows are written
+// in. It is read on first use, after the environment has been loaded.
+func menuScheduleLocation() *time.Location {
+	scheduleLocationOnce.Do(func() {
+		location, err := time.LoadLocation(config.InitConfig().ENVConfig.TimeZone)
+		if err != nil {
+			location = time.Local
+		}
+		scheduleLocation = location
+	})
+
+	return scheduleLocation
+}
+
+// IsMenuAvailable reports whether the menu can be ordered at t. A menu that is
+// not sold out, has stock left and has no availability windows is always
+// available.
+func IsMenuAvailable(menu entity.Menu, t time.Time) bool {
+	if menu.IsSoldOut || (menu.Stock != nil && *menu.Stock <= 0) {
+		return false
+	}
+
+	if len(menu.AvailabilityWindows) == 0 {
+		return true
+	}
+
+	t = t.In(menuScheduleLocation())
+	for _, window := range menu.AvailabilityWindows {
+		if isInAvailabilityWindow(window, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// availabilityWindowClock returns the window's start and end, a window
+// without them lasting the whole day.
+func availabilityWindowClock(window entity.MenuAvailability) (string, string) {
+	start, end := window.StartTime, window.EndTime
+	if start == "" {
+		start = "00:00"
+	}
+	if end == "" {
+		end = "24:00"
+	}
+
+	return start, end
+}
+
+func isInAvailabilityWindow(window entity.MenuAvailability, t time.Time) bool {
+	start, end := availabilityWindowClock(window)
+
+	onDay := func(weekday time.Weekday) bool {
+		return window.DayOfWeek == nil || *window.DayOfWeek == int(weekday)
+	}
+
+	// zero-padded clock times compare correctly as strings
+	clock := t.Format(availabilityTimeLayout)
+	if start < end {
+		return onDay(t.Weekday()) && clock >= start && clock < end
+	}
+
+	// the window runs past midnight into the next day
+	yesterday := t.AddDate(0, 0, -1).Weekday()
+	return (onDay(t.Weekday()) && clock >= start) || (onDay(yesterday) && clock < end)
+}
+
+func validateAvailabilityWindow(window entity.MenuAvailability) error {
+	if window.DayOfWeek != nil && (*window.DayOfWeek < 0 || *window.DayOfWeek > 6) {
+		return domain.ErrInvalidAvailabilityWindow
+	}
+
+	// a window either has both ends or lasts the whole day
+	if window.StartTime == "" && window.EndTime == "" {
+		return nil
+	}
+
+	if !isClockTime(window.StartTime) || (window.EndTime != "24:00" && !isClockTime(window.EndTime)) {
+		return domain.ErrInvalidAvailabilityWindow
+	}
+
+	// a window ending when it starts would be open all day
+	start, end := availabilityWindowClock(window)
+	if start == end {
+		return domain.ErrInvalidAvailabilityWindow
+	}
+
+	return nil
+}
+
+// isClockTime only accepts zero-padded times such as "07:30".
+func isClockTime(value string) bool {
+	clock, err := time.Parse(availabilityTimeLayout, value)
+	return err == nil && clock.Format(availabilityTimeLayout) == value
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+	"testing"
+)
+
+func TestValidateAvailabilityWindow(t *testing.T) {
+	monday, sunday := 1, 7
+
+	tests := []struct {
+		name   string
+		window entity.MenuAvailability
+		valid  bool
+	}{
+		{"whole day", entity.MenuAvailability{DayOfWeek: &monday}, true},
+		{"lunch", entity.MenuAvailability{StartTime: "11:00", EndTime: "14:00"}, true},
+		{"past midnight", entity.MenuAvailability{StartTime: "22:00", EndTime: "02:00"}, true},
+		{"until the end of the day", entity.MenuAvailability{StartTime: "18:00", EndTime: "24:00"}, true},
+		{"midnight to midnight", entity.MenuAvailability{StartTime: "00:00", EndTime: "24:00"}, true},
+		{"start only", entity.MenuAvailability{StartTime: "11:00"}, false},
+		{"end only", entity.MenuAvailability{EndTime: "14:00"}, false},
+		{"end at midnight only", entity.MenuAvailability{EndTime: "00:00"}, false},
+		{"ends when it starts", entity.MenuAvailability{StartTime: "09:00", EndTime: "09:00"}, false},
+		{"not zero padded", entity.MenuAvailability{StartTime: "9:00", EndTime: "14:00"}, false},
+		{"starts at the end of the day", entity.MenuAvailability{StartTime: "24:00", EndTime: "02:00"}, false},
+		{"unknown day", entity.MenuAvailability{DayOfWeek: &sunday}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAvailabilityWindow(tt.window)
+			if tt.valid && err != nil {
+				t.Errorf("expected the window to be valid, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, domain.ErrInvalidAvailabilityWindow) {
+				t.Errorf("expected ErrInvalidAvailabilityWindow, got %v", err)
+			}
+		})
+	}
+}
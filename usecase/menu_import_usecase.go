@@ -0,0 +1,430 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	MenuFileFormatCSV  = "csv"
+	MenuFileFormatJSON = "json"
+)
+
+// menuFileColumns is the CSV header written by exports. Imports only need the
+// name, description and price columns, in any order.
+var menuFileColumns = []string{"name", "description", "price", "categories", "options", "picture_url"}
+
+// menuFileCategorySeparator joins the category names of a CSV row. A separator
+// or backslash inside a name is escaped with a backslash.
+const menuFileCategorySeparator = '|'
+
+type MenuImportUsecase interface {
+	ImportMenus(file io.Reader, format string, dryRun bool) (*dto.MenuImportReport, error)
+	ExportMenus(format string) ([]byte, error)
+}
+
+type menuImportUsecaseImpl struct {
+	unitOfWork        repository.UnitOfWork
+	menuRepo          repository.MenuRepository
+	categoryRepo      repository.CategoryRepository
+	menuOptionUsecase MenuOptionUsecase
+}
+
+type MenuImportUsecaseConfig struct {
+	UnitOfWork        repository.UnitOfWork
+	MenuRepo          repository.MenuRepository
+	CategoryRepo      repository.CategoryRepository
+	MenuOptionUsecase MenuOptionUsecase
+}
+
+func NewMenuImportUsecase(c MenuImportUsecaseConfig) MenuImportUsecase {
+	return &menuImportUsecaseImpl{
+		unitOfWork:        c.UnitOfWork,
+		menuRepo:          c.MenuRepo,
+		categoryRepo:      c.CategoryRepo,
+		menuOptionUsecase: c.MenuOptionUsecase,
+	}
+}
+
+type menuImport struct {
+	menu        entity.Menu
+	categoryIds []uint
+	options     []entity.MenuOption
+}
+
+// ImportMenus validates every row of the file before creating anything. Menu
+// names must be new, so importing a file twice does not copy its menus. When a
+// row is invalid the report is returned with domain.ErrMenuImportRejected and
+// no menu is created; a dry run only returns the report.
+func (u *menuImportUsecaseImpl) ImportMenus(file io.Reader, format string, dryRun bool) (*dto.MenuImportReport, error) {
+	rows, rowErrors, err := decodeMenuRows(file, format)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := u.categoryRepo.GetCategories(false)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	categoryIds := map[string]uint{}
+	for _, category := range categories {
+		categoryIds[strings.ToLower(category.Name)] = category.ID
+		if category.Slug != "" {
+			categoryIds[category.Slug] = category.ID
+		}
+	}
+
+	menus, err := u.menuRepo.GetAllMenus()
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	menuIds := map[string]uint{}
+	for _, menu := range menus {
+		menuIds[strings.ToLower(strings.TrimSpace(menu.Name))] = menu.ID
+	}
+
+	report := &dto.MenuImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Errors: []dto.MenuImportRowError{},
+	}
+
+	names := map[string]int{}
+	imports := make([]menuImport, 0, len(rows))
+	for i, row := range rows {
+		item, errs := u.buildMenuImport(row, categoryIds)
+		errs = append(rowErrors[i], errs...)
+
+		name := strings.ToLower(strings.TrimSpace(row.Name))
+		if menuId, ok := menuIds[name]; ok && name != "" {
+			errs = append(errs, fmt.Sprintf("name is already used by menu %d", menuId))
+		} else if first, ok := names[name]; ok && name != "" {
+			errs = append(errs, fmt.Sprintf("name is already used by row %d", first))
+		} else {
+			names[name] = i + 1
+		}
+
+		if len(errs) > 0 {
+			report.Errors = append(report.Errors, dto.MenuImportRowError{Row: i + 1, Name: row.Name, Errors: errs})
+			continue
+		}
+		imports = append(imports, item)
+	}
+
+	if len(report.Errors) > 0 {
+		if dryRun {
+			return report, nil
+		}
+		return report, domain.ErrMenuImportRejected
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	err = u.unitOfWork.Do(func(repos repository.TxRepositories) error {
+		for _, item := range imports {
+			menu, err := repos.MenuRepo.CreateMenu(item.menu)
+			if err != nil {
+				return err
+			}
+
+			menuCategories := make([]entity.CategoriesMenu, 0, len(item.categoryIds))
+			for _, categoryId := range item.categoryIds {
+				menuCategories = append(menuCategories, entity.CategoriesMenu{CategoryID: categoryId, MenuID: menu.ID})
+			}
+
+			err = repos.MenuRepo.CreateMenuCategories(menuCategories)
+			if err != nil {
+				return err
+			}
+
+			_, err = repos.MenuOptionRepo.SaveMenuOptions(menu.ID, item.options)
+			if err != nil {
+				return err
+			}
+
+			err = repos.MenuRepo.RefreshMenuSearchVector(menu.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	report.Imported = len(imports)
+	return report, nil
+}
+
+func (u *menuImportUsecaseImpl) buildMenuImport(row dto.MenuImportRow, categoryIds map[string]uint) (menuImport, []string) {
+	var errs []string
+	item := menuImport{
+		menu: entity.Menu{
+			Name:        strings.TrimSpace(row.Name),
+			Description: strings.TrimSpace(row.Description),
+			Price:       row.Price,
+			PictureUrl:  strings.TrimSpace(row.PictureUrl),
+			MenuOptions: "[]",
+		},
+	}
+
+	if item.menu.Name == "" {
+		errs = append(errs, "name is required")
+	}
+	if item.menu.Description == "" {
+		errs = append(errs, "description is required")
+	}
+	if row.Price <= 0 {
+		errs = append(errs, "price must be greater than 0")
+	}
+
+	if item.menu.PictureUrl != "" {
+		pictureUrl, err := url.ParseRequestURI(item.menu.PictureUrl)
+		if err != nil || (pictureUrl.Scheme != "http" && pictureUrl.Scheme != "https") {
+			errs = append(errs, "picture_url must be an http or https URL")
+		}
+	}
+
+	seen := map[uint]bool{}
+	unknown := false
+	for _, name := range row.Categories {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		categoryId, ok := categoryIds[strings.ToLower(name)]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("category %q does not exist", name))
+			unknown = true
+			continue
+		}
+		if !seen[categoryId] {
+			item.categoryIds = append(item.categoryIds, categoryId)
+			seen[categoryId] = true
+		}
+	}
+	if len(item.categoryIds) == 0 && !unknown {
+		errs = append(errs, "at least one category is required")
+	}
+
+	options := strings.TrimSpace(string(row.Options))
+	if options != "" && options != "null" {
+		var err error
+		item.options, err = u.menuOptionUsecase.ParseMenuOptions(options)
+		if err != nil {
+			errs = append(errs, "options: "+err.Error())
+		}
+	}
+
+	return item, errs
+}
+
+// decodeMenuRows reads the rows of a CSV or JSON menu file. Values that cannot
+// be parsed are reported per row instead of failing the whole file.
+func decodeMenuRows(file io.Reader, format string) ([]dto.MenuImportRow, [][]string, error) {
+	var rows []dto.MenuImportRow
+	var rowErrors [][]string
+
+	switch format {
+	case MenuFileFormatJSON:
+		err := json.NewDecoder(file).Decode(&rows)
+		if err != nil {
+			return nil, nil, domain.ErrInvalidMenuFile
+		}
+		rowErrors = make([][]string, len(rows))
+	case MenuFileFormatCSV:
+		records, err := csv.NewReader(file).ReadAll()
+		if err != nil || len(records) == 0 {
+			return nil, nil, domain.ErrInvalidMenuFile
+		}
+
+		columns := map[string]int{}
+		for i, column := range records[0] {
+			column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+			columns[column] = i
+		}
+		for _, column := range menuFileColumns[:3] {
+			if _, ok := columns[column]; !ok {
+				return nil, nil, fmt.Errorf("%w: missing %s column", domain.ErrInvalidMenuFile, column)
+			}
+		}
+
+		value := func(record []string, column string) string {
+			i, ok := columns[column]
+			if !ok {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		for _, record := range records[1:] {
+			var errs []string
+			row := dto.MenuImportRow{
+				Name:        value(record, "name"),
+				Description: value(record, "description"),
+				PictureUrl:  value(record, "picture_url"),
+			}
+
+			price := value(record, "price")
+			row.Price, err = strconv.Atoi(price)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("price %q is not a whole number", price))
+			}
+
+			if categories := value(record, "categories"); categories != "" {
+				row.Categories = splitMenuFileCategories(categories)
+			}
+			if options := value(record, "options"); options != "" {
+				row.Options = json.RawMessage(options)
+			}
+
+			rows = append(rows, row)
+			rowErrors = append(rowErrors, errs)
+		}
+	default:
+		return nil, nil, domain.ErrInvalidMenuFileFormat
+	}
+
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("%w: no menus found", domain.ErrInvalidMenuFile)
+	}
+
+	return rows, rowErrors, nil
+}
+
+// ExportMenus writes every menu in a file that ImportMenus accepts.
+func (u *menuImportUsecaseImpl) ExportMenus(format string) ([]byte, error) {
+	if format != MenuFileFormatCSV && format != MenuFileFormatJSON {
+		return nil, domain.ErrInvalidMenuFileFormat
+	}
+
+	menus, err := u.menuRepo.GetAllMenus()
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	rows := make([]dto.MenuImportRow, 0, len(menus))
+	for _, menu := range menus {
+		row := dto.MenuImportRow{
+			Name:        menu.Name,
+			Description: menu.Description,
+			Price:       menu.Price,
+			Categories:  []string{},
+			PictureUrl:  menu.PictureUrl,
+		}
+		for _, category := range menu.Categories {
+			row.Categories = append(row.Categories, category.Name)
+		}
+
+		row.Options, err = exportMenuOptions(menu.OptionGroups)
+		if err != nil {
+			return nil, domain.ErrInternalServer
+		}
+
+		rows = append(rows, row)
+	}
+
+	if format == MenuFileFormatJSON {
+		return json.MarshalIndent(rows, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	err = writer.Write(menuFileColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		err = writer.Write([]string{
+			row.Name,
+			row.Description,
+			strconv.Itoa(row.Price),
+			joinMenuFileCategories(row.Categories),
+			string(row.Options),
+			row.PictureUrl,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// joinMenuFileCategories escapes the category names and joins them into one
+// CSV value.
+func joinMenuFileCategories(names []string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, string(menuFileCategorySeparator), `\`+string(menuFileCategorySeparator))
+
+	escaped := make([]string, len(names))
+	for i, name := range names {
+		escaped[i] = escaper.Replace(name)
+	}
+
+	return strings.Join(escaped, string(menuFileCategorySeparator))
+}
+
+// splitMenuFileCategories undoes joinMenuFileCategories. A backslash that does
+// not escape anything is kept, so hand-written files need no escaping unless
+// a name has a separator in it.
+func splitMenuFileCategories(categories string) []string {
+	var names []string
+	var name strings.Builder
+	for i := 0; i < len(categories); i++ {
+		c := categories[i]
+		switch {
+		case c == '\\' && i+1 < len(categories) && (categories[i+1] == '\\' || categories[i+1] == menuFileCategorySeparator):
+			i++
+			name.WriteByte(categories[i])
+		case c == menuFileCategorySeparator:
+			names = append(names, name.String())
+			name.Reset()
+		default:
+			name.WriteByte(c)
+		}
+	}
+
+	return append(names, name.String())
+}
+
+// exportMenuOptions drops the IDs and stock counts, which belong to this
+// database, from the option groups.
+func exportMenuOptions(options []entity.MenuOption) (json.RawMessage, error) {
+	exported := make([]entity.MenuOption, len(options))
+	for i, option := range options {
+		option.ID = 0
+		optionLists := make([]entity.MenuOptionList, len(option.MenuOptionLists))
+		for j, optionList := range option.MenuOptionLists {
+			optionList.ID = 0
+			optionList.Stock = nil
+			optionList.Checked = false
+			optionLists[j] = optionList
+		}
+		option.MenuOptionLists = optionLists
+		exported[i] = option
+	}
+
+	return json.Marshal(exported)
+}
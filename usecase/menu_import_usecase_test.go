@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type menuImportFixture struct {
+	menuRepo       *fakeMenuRepo
+	menuOptionRepo *fakeMenuOptionRepo
+	usecase        MenuImportUsecase
+}
+
+// newMenuImportFixture stores the given menus next to the Burgers (1),
+// Fries | Sides (2) and Drinks (3) categories.
+func newMenuImportFixture(menus ...*entity.Menu) menuImportFixture {
+	categories := []entity.Category{{Name: "Burgers", Slug: "burgers"}, {Name: "Fries | Sides", Slug: "fries-sides"}, {Name: "Drinks", Slug: "drinks"}}
+	for i := range categories {
+		categories[i].ID = uint(i + 1)
+	}
+
+	f := menuImportFixture{
+		menuRepo:       &fakeMenuRepo{menus: map[uint]*entity.Menu{}},
+		menuOptionRepo: &fakeMenuOptionRepo{},
+	}
+	for _, menu := range menus {
+		f.menuRepo.menus[menu.ID] = menu
+	}
+
+	f.usecase = NewMenuImportUsecase(MenuImportUsecaseConfig{
+		UnitOfWork: &fakeUnitOfWork{repos: repository.TxRepositories{
+			MenuRepo:       f.menuRepo,
+			MenuOptionRepo: f.menuOptionRepo,
+		}},
+		MenuRepo:          f.menuRepo,
+		CategoryRepo:      &fakeCategoryRepo{categories: categories},
+		MenuOptionUsecase: NewMenuOptionUsecase(MenuOptionUsecaseConfig{}),
+	})
+
+	return f
+}
+
+func TestMenuExportImportRoundTrip(t *testing.T) {
+	stock := 4
+	burger := testMenu(1, "Cheeseburger", 35000)
+	burger.Description = "Beef, cheddar and pickles"
+	burger.PictureUrl = "https://example.com/cheeseburger.png"
+	burger.Categories = []entity.Category{{Name: "Burgers"}, {Name: "Fries | Sides"}}
+	burger.OptionGroups = []entity.MenuOption{testMenuOption(7, "Size", 1, 1, "Regular", "Large")}
+	burger.OptionGroups[0].Type = entity.MenuOptionTypeSingle
+	burger.OptionGroups[0].MenuOptionLists[1].Stock = &stock
+	burger.OptionGroups[0].MenuOptionLists[1].Available = false
+
+	cola := testMenu(2, "Cola", 10000)
+	cola.Description = "Cold, with ice"
+	cola.Categories = []entity.Category{{Name: "Drinks"}}
+
+	for _, format := range []string{MenuFileFormatCSV, MenuFileFormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			file, err := newMenuImportFixture(burger, cola).usecase.ExportMenus(format)
+			if err != nil {
+				t.Fatalf("export menus: %v", err)
+			}
+
+			f := newMenuImportFixture()
+			report, err := f.usecase.ImportMenus(bytes.NewReader(file), format, false)
+			if err != nil {
+				t.Fatalf("import menus: %v, report %+v", err, report)
+			}
+			if report.Imported != 2 || len(f.menuRepo.menus) != 2 {
+				t.Fatalf("expected both menus to be imported, got %+v", report)
+			}
+
+			imported := f.menuRepo.menus[1]
+			if imported.Name != burger.Name || imported.Description != burger.Description ||
+				imported.Price != burger.Price || imported.PictureUrl != burger.PictureUrl {
+				t.Errorf("expected the burger back, got %+v", imported)
+			}
+
+			want := []entity.CategoriesMenu{{CategoryID: 1, MenuID: 1}, {CategoryID: 2, MenuID: 1}, {CategoryID: 3, MenuID: 2}}
+			if !reflect.DeepEqual(f.menuRepo.menuCategories, want) {
+				t.Errorf("expected categories %+v, got %+v", want, f.menuRepo.menuCategories)
+			}
+
+			options := f.menuOptionRepo.saved[1]
+			if len(options) != 1 || options[0].ID != 0 || options[0].Title != "Size" || options[0].Max != 1 ||
+				len(options[0].MenuOptionLists) != 2 || options[0].MenuOptionLists[1].Name != "Large" {
+				t.Fatalf("expected the size group without IDs, got %+v", options)
+			}
+			if options[0].MenuOptionLists[1].Stock != nil || options[0].MenuOptionLists[1].ID != 0 {
+				t.Errorf("expected the stock and IDs to stay behind, got %+v", options[0].MenuOptionLists[1])
+			}
+			if !options[0].Available || !options[0].MenuOptionLists[0].Available || options[0].MenuOptionLists[1].Available {
+				t.Errorf("expected only the large size to stay unavailable, got %+v", options[0])
+			}
+			if len(f.menuOptionRepo.saved[2]) != 0 {
+				t.Errorf("expected cola to have no options, got %+v", f.menuOptionRepo.saved[2])
+			}
+		})
+	}
+}
+
+func TestImportMenusReportsEveryInvalidRow(t *testing.T) {
+	file := strings.Join([]string{
+		"name,description,price,categories",
+		"Cheeseburger,Beef and cheddar,35000,burgers",
+		"Fries,Crispy,cheap,Sides",
+		"Cola,,10000,Drinks",
+		"cheeseburger,Again,35000,Burgers",
+		`Onion rings,Battered,15000,Fries \| Sides`,
+	}, "\n")
+
+	f := newMenuImportFixture()
+	report, err := f.usecase.ImportMenus(strings.NewReader(file), MenuFileFormatCSV, false)
+	if !errors.Is(err, domain.ErrMenuImportRejected) {
+		t.Fatalf("expected the import to be rejected, got %v", err)
+	}
+
+	want := map[int][]string{
+		2: {`price "cheap" is not a whole number`, "price must be greater than 0", `category "Sides" does not exist`},
+		3: {"description is required"},
+		4: {"name is already used by row 1"},
+	}
+	if report.Total != 5 || len(report.Errors) != len(want) {
+		t.Fatalf("expected rows 2 to 4 to be reported, got %+v", report)
+	}
+	for _, rowError := range report.Errors {
+		if !reflect.DeepEqual(rowError.Errors, want[rowError.Row]) {
+			t.Errorf("row %d: expected %q, got %q", rowError.Row, want[rowError.Row], rowError.Errors)
+		}
+	}
+	if len(f.menuRepo.menus) != 0 {
+		t.Errorf("expected nothing to be imported, got %+v", f.menuRepo.menus)
+	}
+
+	_, err = f.usecase.ImportMenus(strings.NewReader("name,price\nCola,10000"), MenuFileFormatCSV, false)
+	if !errors.Is(err, domain.ErrInvalidMenuFile) {
+		t.Errorf("expected a file without a description column to be unreadable, got %v", err)
+	}
+}
+
+func TestImportMenusDryRunWritesNothing(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		wantErrors int
+	}{
+		{"valid file", "name,description,price,categories\nCola,Cold,10000,Drinks", 0},
+		{"invalid file", "name,description,price,categories\nCola,Cold,0,Drinks", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newMenuImportFixture()
+			report, err := f.usecase.ImportMenus(strings.NewReader(tt.file), MenuFileFormatCSV, true)
+			if err != nil {
+				t.Fatalf("dry run: %v", err)
+			}
+
+			if !report.DryRun || report.Imported != 0 || len(report.Errors) != tt.wantErrors {
+				t.Errorf("expected a dry run report with %d errors, got %+v", tt.wantErrors, report)
+			}
+			if len(f.menuRepo.menus) != 0 || len(f.menuRepo.menuCategories) != 0 || len(f.menuOptionRepo.saved) != 0 {
+				t.Error("expected a dry run not to write anything")
+			}
+		})
+	}
+}
+
+func TestMenuFileCategoriesEscapeSeparators(t *testing.T) {
+	names := []string{"Burgers", "Fries | Sides", `Back\slash`, `Odd \| name`}
+
+	joined := joinMenuFileCategories(names)
+	if joined != `Burgers|Fries \| Sides|Back\\slash|Odd \\\| name` {
+		t.Errorf("unexpected escaping %s", joined)
+	}
+	if got := splitMenuFileCategories(joined); !reflect.DeepEqual(got, names) {
+		t.Errorf("expected %q back, got %q", names, got)
+	}
+
+	if got := splitMenuFileCategories(`Burgers|C:\menus`); !reflect.DeepEqual(got, []string{"Burgers", `C:\menus`}) {
+		t.Errorf("expected a lone backslash to be kept, got %q", got)
+	}
+}
+
+func TestImportMenusRejectsExistingNames(t *testing.T) {
+	cola := testMenu(2, "Cola", 10000)
+	cola.Description = "Cold, with ice"
+	cola.Categories = []entity.Category{{Name: "Drinks"}}
+
+	f := newMenuImportFixture(cola)
+	file, err := f.usecase.ExportMenus(MenuFileFormatCSV)
+	if err != nil {
+		t.Fatalf("export menus: %v", err)
+	}
+
+	report, err := f.usecase.ImportMenus(bytes.NewReader(file), MenuFileFormatCSV, false)
+	if !errors.Is(err, domain.ErrMenuImportRejected) {
+		t.Fatalf("expected the import to be rejected, got %v", err)
+	}
+	if len(report.Errors) != 1 || !reflect.DeepEqual(report.Errors[0].Errors, []string{"name is already used by menu 2"}) {
+		t.Errorf("expected cola to be reported as taken, got %+v", report.Errors)
+	}
+	if len(f.menuRepo.menus) != 1 {
+		t.Errorf("expected no copy of cola, got %+v", f.menuRepo.menus)
+	}
+}
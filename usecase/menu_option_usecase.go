@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"encoding/json"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"strings"
+)
+
+type MenuOptionUsecase interface {
+	GetMenuOptions(menuId uint) ([]entity.MenuOption, error)
+	CreateMenuOption(menuId uint, request dto.MenuOptionRequest) (*entity.MenuOption, error)
+	UpdateMenuOption(menuId uint, optionId uint, request dto.MenuOptionRequest) (*entity.MenuOption, error)
+	DeleteMenuOption(menuId uint, optionId uint) error
+	CreateMenuOptionList(menuId uint, optionId uint, request dto.MenuOptionListRequest) (*entity.MenuOptionList, error)
+	UpdateMenuOptionList(menuId uint, optionId uint, listId uint, request dto.MenuOptionListRequest) (*entity.MenuOptionList, error)
+	DeleteMenuOptionList(menuId uint, optionId uint, listId uint) error
+	ParseMenuOptions(menuOptions string) ([]entity.MenuOption, error)
+}
+
+type menuOptionUsecaseImpl struct {
+	menuRepo       repository.MenuRepository
+	menuOptionRepo repository.MenuOptionRepository
+}
+
+type MenuOptionUsecaseConfig struct {
+	MenuRepo       repository.MenuRepository
+	MenuOptionRepo repository.MenuOptionRepository
+}
+
+func NewMenuOptionUsecase(c MenuOptionUsecaseConfig) MenuOptionUsecase {
+	return &menuOptionUsecaseImpl{
+		menuRepo:       c.MenuRepo,
+		menuOptionRepo: c.MenuOptionRepo,
+	}
+}
+
+func (m *menuOptionUsecaseImpl) GetMenuOptions(menuId uint) ([]entity.MenuOption, error) {
+	menu, _ := m.menuRepo.GetMenuById(menuId)
+	if menu == nil {
+		return nil, domain.ErrMenuNotFound
+	}
+
+	options, err := m.menuOptionRepo.GetMenuOptionsByMenuID(menuId)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return options, nil
+}
+
+func (m *menuOptionUsecaseImpl) CreateMenuOption(menuId uint, request dto.MenuOptionRequest) (*entity.MenuOption, error) {
+	menu, _ := m.menuRepo.GetMenuById(menuId)
+	if menu == nil {
+		return nil, domain.ErrMenuNotFound
+	}
+
+	option := entity.MenuOption{
+		MenuID:    menuId,
+		Title:     request.Title,
+		Type:      request.Type,
+		Min:       request.Min,
+		Max:       request.Max,
+		Available: isAvailable(request.Available),
+	}
+	for _, listRequest := range request.MenuOptionLists {
+		option.MenuOptionLists = append(option.MenuOptionLists, entity.MenuOptionList{
+			Name:        listRequest.Name,
+			Price:       listRequest.Price,
+			Description: listRequest.Description,
+			Available:   isAvailable(listRequest.Available),
+		})
+	}
+
+	err := validateMenuOption(&option)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.menuOptionRepo.CreateMenuOption(option)
+}
+
+// UpdateMenuOption changes the group's own fields; the choices sent along are
+// ignored and are managed with the choice endpoints.
+func (m *menuOptionUsecaseImpl) UpdateMenuOption(menuId uint, optionId uint, request dto.MenuOptionRequest) (*entity.MenuOption, error) {
+	option, err := m.getMenuOption(menuId, optionId)
+	if err != nil {
+		return nil, err
+	}
+
+	option.Title = request.Title
+	option.Type = request.Type
+	option.Min = request.Min
+	option.Max = request.Max
+	option.Available = isAvailable(request.Available)
+
+	err = validateMenuOption(option)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.menuOptionRepo.UpdateMenuOption(*option)
+}
+
+func (m *menuOptionUsecaseImpl) DeleteMenuOption(menuId uint, optionId uint) error {
+	option, err := m.getMenuOption(menuId, optionId)
+	if err != nil {
+		return err
+	}
+
+	return m.menuOptionRepo.DeleteMenuOption(*option)
+}
+
+func (m *menuOptionUsecaseImpl) CreateMenuOptionList(menuId uint, optionId uint, request dto.MenuOptionListRequest) (*entity.MenuOptionList, error) {
+	_, err := m.getMenuOption(menuId, optionId)
+	if err != nil {
+		return nil, err
+	}
+
+	optionList := entity.MenuOptionList{
+		MenuOptionID: optionId,
+		Name:         request.Name,
+		Price:        request.Price,
+		Description:  request.Description,
+		Available:    isAvailable(request.Available),
+	}
+
+	err = validateMenuOptionList(optionList)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.menuOptionRepo.CreateMenuOptionList(optionList)
+}
+
+func (m *menuOptionUsecaseImpl) UpdateMenuOptionList(menuId uint, optionId uint, listId uint, request dto.MenuOptionListRequest) (*entity.MenuOptionList, error) {
+	optionList, err := m.getMenuOptionList(menuId, optionId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	optionList.Name = request.Name
+	optionList.Price = request.Price
+	optionList.Description = request.Description
+	optionList.Available = isAvailable(request.Available)
+
+	err = validateMenuOptionList(*optionList)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.menuOptionRepo.UpdateMenuOptionList(*optionList)
+}
+
+func (m *menuOptionUsecaseImpl) DeleteMenuOptionList(menuId uint, optionId uint, listId uint) error {
+	optionList, err := m.getMenuOptionList(menuId, optionId, listId)
+	if err != nil {
+		return err
+	}
+
+	return m.menuOptionRepo.DeleteMenuOptionList(*optionList)
+}
+
+// menuFormAvailability holds the available flags of the menu form's option
+// groups and choices, which are nil when left out.
+type menuFormAvailability struct {
+	Available       *bool `json:"available"`
+	MenuOptionLists []struct {
+		Available *bool `json:"available"`
+	} `json:"menu_option_lists"`
+}
+
+// ParseMenuOptions reads the option groups of the menu form's JSON field and
+// validates them. Groups and choices are available unless the form says
+// otherwise, and the IDs sent along keep the stored ones. Radio groups sent by
+// older clients need exactly one choice, and groups with a type they made up
+// are typed by their Max.
+func (m *menuOptionUsecaseImpl) ParseMenuOptions(menuOptions string) ([]entity.MenuOption, error) {
+	var options []entity.MenuOption
+	err := json.Unmarshal([]byte(menuOptions), &options)
+	if err != nil {
+		return nil, domain.ErrInvalidMenuOptionGroup
+	}
+
+	var availability []menuFormAvailability
+	err = json.Unmarshal([]byte(menuOptions), &availability)
+	if err != nil {
+		return nil, domain.ErrInvalidMenuOptionGroup
+	}
+
+	for i := range options {
+		if strings.ToLower(options[i].Type) == "radio" {
+			options[i].Min = 1
+		}
+		if normalizeMenuOptionType(options[i].Type) == "" {
+			options[i].Type = entity.MenuOptionTypeMulti
+			if options[i].Max == 1 {
+				options[i].Type = entity.MenuOptionTypeSingle
+			}
+		}
+		options[i].Available = isAvailable(availability[i].Available)
+		for j := range options[i].MenuOptionLists {
+			options[i].MenuOptionLists[j].Available = isAvailable(availability[i].MenuOptionLists[j].Available)
+			options[i].MenuOptionLists[j].Checked = false
+		}
+
+		err = validateMenuOption(&options[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+func (m *menuOptionUsecaseImpl) getMenuOption(menuId uint, optionId uint) (*entity.MenuOption, error) {
+	option, _ := m.menuOptionRepo.GetMenuOptionByID(optionId)
+	if option == nil || option.MenuID != menuId {
+		return nil, domain.ErrMenuOptionNotFound
+	}
+
+	return option, nil
+}
+
+func (m *menuOptionUsecaseImpl) getMenuOptionList(menuId uint, optionId uint, listId uint) (*entity.MenuOptionList, error) {
+	_, err := m.getMenuOption(menuId, optionId)
+	if err != nil {
+		return nil, err
+	}
+
+	optionList, _ := m.menuOptionRepo.GetMenuOptionListByID(listId)
+	if optionList == nil || optionList.MenuOptionID != optionId {
+		return nil, domain.ErrMenuOptionListNotFound
+	}
+
+	return optionList, nil
+}
+
+func isAvailable(available *bool) bool {
+	return available == nil || *available
+}
+
+// normalizeMenuOptionType maps the radio and checkbox types sent by older
+// clients to single and multi.
+func normalizeMenuOptionType(optionType string) string {
+	switch strings.ToLower(optionType) {
+	case entity.MenuOptionTypeSingle, "radio":
+		return entity.MenuOptionTypeSingle
+	case entity.MenuOptionTypeMulti, "checkbox":
+		return entity.MenuOptionTypeMulti
+	}
+
+	return ""
+}
+
+func validateMenuOption(option *entity.MenuOption) error {
+	option.Type = normalizeMenuOptionType(option.Type)
+	if option.Type == "" || strings.TrimSpace(option.Title) == "" {
+		return domain.ErrInvalidMenuOptionGroup
+	}
+
+	if option.Max < 1 || option.Min < 0 || option.Min > option.Max {
+		return domain.ErrInvalidMenuOptionGroup
+	}
+
+	if option.Type == entity.MenuOptionTypeSingle && option.Max != 1 {
+		return domain.ErrInvalidMenuOptionGroup
+	}
+
+	for _, optionList := range option.MenuOptionLists {
+		err := validateMenuOptionList(optionList)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateMenuOptionList(optionList entity.MenuOptionList) error {
+	if strings.TrimSpace(optionList.Name) == "" || optionList.Price < 0 {
+		return domain.ErrInvalidMenuOptionGroup
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"testing"
+)
+
+func TestParseMenuOptionsKeepsAvailabilityAndIDs(t *testing.T) {
+	form := `[
+		{"id": 4, "title": "Size", "type": "radio", "max": "1", "available": false, "menu_option_lists": [
+			{"id": 41, "name": "Regular", "price": "0", "description": ""},
+			{"name": "Large", "price": "5000", "description": "", "available": false, "checked": true}
+		]},
+		{"title": "Toppings", "type": "multi", "max": "2", "menu_option_lists": [
+			{"name": "Cheese", "price": "3000", "description": "", "available": true}
+		]}
+	]`
+
+	options, err := NewMenuOptionUsecase(MenuOptionUsecaseConfig{}).ParseMenuOptions(form)
+	if err != nil {
+		t.Fatalf("parse menu options: %v", err)
+	}
+
+	size, toppings := options[0], options[1]
+	if size.ID != 4 || size.MenuOptionLists[0].ID != 41 || size.Type != "single" || size.Min != 1 {
+		t.Errorf("expected the size group to keep its IDs and be a required single choice, got %+v", size)
+	}
+	if size.Available || !size.MenuOptionLists[0].Available || size.MenuOptionLists[1].Available || size.MenuOptionLists[1].Checked {
+		t.Errorf("expected only the regular size to be available and nothing checked, got %+v", size)
+	}
+	if !toppings.Available || !toppings.MenuOptionLists[0].Available {
+		t.Errorf("expected groups and choices without a flag to be available, got %+v", toppings)
+	}
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"fmt"
+)
+
+// MenuOptionValidator checks the options picked for a cart or order line
+// against the menu's stored option groups.
+type MenuOptionValidator interface {
+	Validate(menuId uint, requested []entity.MenuOption) ([]entity.MenuOption, int, error)
+}
+
+type menuOptionValidatorImpl struct {
+	menuOptionRepo repository.MenuOptionRepository
+}
+
+type MenuOptionValidatorConfig struct {
+	MenuOptionRepo repository.MenuOptionRepository
+}
+
+func NewMenuOptionValidator(c MenuOptionValidatorConfig) MenuOptionValidator {
+	return &menuOptionValidatorImpl{
+		menuOptionRepo: c.MenuOptionRepo,
+	}
+}
+
+// Validate returns the menu's option groups with the picked choices checked
+// and the per-unit surcharge from the stored prices. A rejected selection
+// returns a *domain.MenuOptionError with one reason per offending group, and a
+// failed lookup returns domain.ErrInternalServer.
+func (v *menuOptionValidatorImpl) Validate(menuId uint, requested []entity.MenuOption) ([]entity.MenuOption, int, error) {
+	stored, err := v.menuOptionRepo.GetMenuOptionsByMenuID(menuId)
+	if err != nil {
+		return nil, 0, domain.ErrInternalServer
+	}
+
+	return selectMenuOptions(stored, requested)
+}
+
+// selectMenuOptions copies the stored option groups and marks the choices the
+// client checked. Groups and choices are matched by ID, or by title and name
+// for clients that do not send IDs. Every available group must get between
+// Min and Max choices, and unavailable groups or choices cannot be picked. A
+// group can only be sent once.
+func selectMenuOptions(stored []entity.MenuOption, requested []entity.MenuOption) ([]entity.MenuOption, int, error) {
+	menuOptions := make([]entity.MenuOption, len(stored))
+	for i, option := range stored {
+		menuOptions[i] = option
+		menuOptions[i].MenuOptionLists = make([]entity.MenuOptionList, len(option.MenuOptionLists))
+		for j, optionList := range option.MenuOptionLists {
+			optionList.Checked = false
+			menuOptions[i].MenuOptionLists[j] = optionList
+		}
+	}
+
+	// only the first problem of each group is reported
+	reasons := make([]string, len(menuOptions))
+	reject := func(i int, reason string) {
+		if reasons[i] == "" {
+			reasons[i] = reason
+		}
+	}
+
+	var unknownGroups []domain.MenuOptionGroupError
+	requestedGroups := make([]bool, len(menuOptions))
+	surcharge := 0
+	for _, requestedOption := range requested {
+		i := findMenuOption(menuOptions, requestedOption)
+		if i < 0 {
+			title := requestedOption.Title
+			if title == "" {
+				title = fmt.Sprintf("option group %d", requestedOption.ID)
+			}
+			unknownGroups = append(unknownGroups, domain.MenuOptionGroupError{
+				Title:  title,
+				Reason: "is not an option of this menu",
+			})
+			continue
+		}
+		if requestedGroups[i] {
+			reject(i, "is given more than once")
+			continue
+		}
+		requestedGroups[i] = true
+		option := &menuOptions[i]
+
+		for _, requestedList := range requestedOption.MenuOptionLists {
+			if !requestedList.Checked {
+				continue
+			}
+
+			optionList := findMenuOptionList(option.MenuOptionLists, requestedList)
+			switch {
+			case optionList == nil:
+				reject(i, fmt.Sprintf("%q is not a choice of this group", requestedList.Name))
+			case !option.Available:
+				reject(i, "is not available")
+			case !optionList.Available:
+				reject(i, fmt.Sprintf("%q is not available", optionList.Name))
+			case optionList.Stock != nil && *optionList.Stock <= 0:
+				reject(i, fmt.Sprintf("%q is sold out", optionList.Name))
+			case !optionList.Checked:
+				optionList.Checked = true
+				surcharge += optionList.Price
+			}
+		}
+	}
+
+	var groupErrors []domain.MenuOptionGroupError
+	for i, option := range menuOptions {
+		checked := 0
+		for _, optionList := range option.MenuOptionLists {
+			if optionList.Checked {
+				checked++
+			}
+		}
+
+		switch {
+		case option.Available && option.Min == option.Max && checked != option.Min:
+			reject(i, fmt.Sprintf("choose exactly %d", option.Min))
+		case checked > option.Max:
+			reject(i, fmt.Sprintf("choose at most %d", option.Max))
+		case option.Available && checked < option.Min:
+			reject(i, fmt.Sprintf("choose at least %d", option.Min))
+		}
+
+		if reasons[i] != "" {
+			groupErrors = append(groupErrors, domain.MenuOptionGroupError{Title: option.Title, Reason: reasons[i]})
+		}
+
+		// stock counts change with every order and do not belong in snapshots
+		for j := range option.MenuOptionLists {
+			option.MenuOptionLists[j].Stock = nil
+		}
+	}
+
+	groupErrors = append(groupErrors, unknownGroups...)
+	if len(groupErrors) > 0 {
+		return nil, 0, &domain.MenuOptionError{Groups: groupErrors}
+	}
+
+	return menuOptions, surcharge, nil
+}
+
+func findMenuOption(options []entity.MenuOption, requested entity.MenuOption) int {
+	for i := range options {
+		if requested.ID != 0 && options[i].ID == requested.ID {
+			return i
+		}
+		if requested.ID == 0 && options[i].Title == requested.Title {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func findMenuOptionList(optionLists []entity.MenuOptionList, requested entity.MenuOptionList) *entity.MenuOptionList {
+	for i := range optionLists {
+		if requested.ID != 0 && optionLists[i].ID == requested.ID {
+			return &optionLists[i]
+		}
+		if requested.ID == 0 && optionLists[i].Name == requested.Name {
+			return &optionLists[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/entity"
+	"testing"
+)
+
+// testMenuOption builds an available option group whose choices cost 1000
+// each, with IDs 10*id+1, 10*id+2 and so on.
+func testMenuOption(id uint, title string, min int, max int, names ...string) entity.MenuOption {
+	option := entity.MenuOption{Title: title, Min: min, Max: max, Available: true}
+	option.ID = id
+	for i, name := range names {
+		optionList := entity.MenuOptionList{Name: name, Price: 1000, Available: true}
+		optionList.ID = id*10 + uint(i) + 1
+		option.MenuOptionLists = append(option.MenuOptionLists, optionList)
+	}
+
+	return option
+}
+
+// pick asks for the named choices of group id.
+func pick(id uint, names ...string) entity.MenuOption {
+	option := entity.MenuOption{}
+	option.ID = id
+	for _, name := range names {
+		option.MenuOptionLists = append(option.MenuOptionLists, entity.MenuOptionList{Name: name, Checked: true})
+	}
+
+	return option
+}
+
+func TestSelectMenuOptions(t *testing.T) {
+	soldOut := 0
+	stored := []entity.MenuOption{
+		testMenuOption(1, "Size", 1, 1, "Regular", "Large"),
+		testMenuOption(2, "Toppings", 0, 2, "Cheese", "Egg", "Bacon", "Ham"),
+		testMenuOption(3, "Sauces", 1, 3, "Chili", "Mayo", "Truffle"),
+	}
+	stored[2].MenuOptionLists[2].Available = false
+	stored[1].MenuOptionLists[3].Stock = &soldOut
+
+	tests := []struct {
+		name          string
+		requested     []entity.MenuOption
+		wantSurcharge int
+		wantReasons   []string
+	}{
+		{
+			name:          "valid selection",
+			requested:     []entity.MenuOption{pick(1, "Large"), pick(2, "Cheese", "Egg"), pick(3, "Chili")},
+			wantSurcharge: 4000,
+		},
+		{
+			name:          "choices by group title",
+			requested:     []entity.MenuOption{{Title: "Size", MenuOptionLists: []entity.MenuOptionList{{Name: "Regular", Checked: true}}}, pick(3, "Mayo")},
+			wantSurcharge: 2000,
+		},
+		{
+			name:        "exactly one",
+			requested:   []entity.MenuOption{pick(1, "Regular", "Large"), pick(3, "Chili")},
+			wantReasons: []string{"Size: choose exactly 1"},
+		},
+		{
+			name:        "missing exact group",
+			requested:   []entity.MenuOption{pick(3, "Chili")},
+			wantReasons: []string{"Size: choose exactly 1"},
+		},
+		{
+			name:        "at most",
+			requested:   []entity.MenuOption{pick(1, "Large"), pick(2, "Cheese", "Egg", "Bacon"), pick(3, "Chili")},
+			wantReasons: []string{"Toppings: choose at most 2"},
+		},
+		{
+			name:        "sold out choice",
+			requested:   []entity.MenuOption{pick(1, "Large"), pick(2, "Ham"), pick(3, "Chili")},
+			wantReasons: []string{`Toppings: "Ham" is sold out`},
+		},
+		{
+			name:        "at least",
+			requested:   []entity.MenuOption{pick(1, "Large")},
+			wantReasons: []string{"Sauces: choose at least 1"},
+		},
+		{
+			name:        "unknown group",
+			requested:   []entity.MenuOption{pick(1, "Large"), pick(3, "Chili"), pick(9, "Extra")},
+			wantReasons: []string{"option group 9: is not an option of this menu"},
+		},
+		{
+			name:        "unknown choice",
+			requested:   []entity.MenuOption{pick(1, "Huge"), pick(3, "Chili")},
+			wantReasons: []string{`Size: "Huge" is not a choice of this group`},
+		},
+		{
+			name:        "unavailable choice",
+			requested:   []entity.MenuOption{pick(1, "Large"), pick(3, "Truffle")},
+			wantReasons: []string{`Sauces: "Truffle" is not available`},
+		},
+		{
+			name:        "duplicate group",
+			requested:   []entity.MenuOption{pick(1, "Large"), pick(3, "Chili"), pick(3, "Mayo")},
+			wantReasons: []string{"Sauces: is given more than once"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, surcharge, err := selectMenuOptions(stored, tt.requested)
+
+			if tt.wantReasons == nil {
+				if err != nil {
+					t.Fatalf("expected the selection to pass, got %v", err)
+				}
+				if surcharge != tt.wantSurcharge {
+					t.Errorf("expected a surcharge of %d, got %d", tt.wantSurcharge, surcharge)
+				}
+				if len(selected) != len(stored) {
+					t.Errorf("expected every stored group in the snapshot, got %+v", selected)
+				}
+				return
+			}
+
+			var optionErr *domain.MenuOptionError
+			if !errors.As(err, &optionErr) || !errors.Is(err, domain.ErrInvalidMenuOption) {
+				t.Fatalf("expected a menu option error, got %v", err)
+			}
+			if len(optionErr.Groups) != len(tt.wantReasons) {
+				t.Fatalf("expected %v, got %+v", tt.wantReasons, optionErr.Groups)
+			}
+			for i, group := range optionErr.Groups {
+				if got := group.Title + ": " + group.Reason; got != tt.wantReasons[i] {
+					t.Errorf("expected %q, got %q", tt.wantReasons[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectMenuOptionsHidesStockFromSnapshot(t *testing.T) {
+	stock := 5
+	stored := []entity.MenuOption{testMenuOption(1, "Size", 1, 1, "Regular")}
+	stored[0].MenuOptionLists[0].Stock = &stock
+
+	selected, _, err := selectMenuOptions(stored, []entity.MenuOption{pick(1, "Regular")})
+	if err != nil {
+		t.Fatalf("select menu options: %v", err)
+	}
+
+	if !selected[0].MenuOptionLists[0].Checked || selected[0].MenuOptionLists[0].Stock != nil {
+		t.Errorf("expected a checked choice without stock, got %+v", selected[0].MenuOptionLists[0])
+	}
+	if stored[0].MenuOptionLists[0].Stock == nil || stored[0].MenuOptionLists[0].Checked {
+		t.Error("expected the stored groups to be left untouched")
+	}
+}
+
+func TestValidateMenuOptionsHidesLookupErrors(t *testing.T) {
+	validator := NewMenuOptionValidator(MenuOptionValidatorConfig{
+		MenuOptionRepo: &fakeMenuOptionRepo{err: errors.New("connection reset")},
+	})
+
+	_, _, err := validator.Validate(1, nil)
+	if !errors.Is(err, domain.ErrInternalServer) {
+		t.Errorf("expected ErrInternalServer, got %v", err)
+	}
+}
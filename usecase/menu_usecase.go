@@ -8,18 +8,19 @@ import (
 	"final-project-backend/util"
 )
 
+const menuSuggestionLimit = 8
+
 type MenuUsecase interface {
-	CreateMenu(menu entity.Menu) (*entity.Menu, error)
-	GetMenus(dto.Query) ([]entity.Menu, error)
-	CreateMenuCategories([]entity.CategoriesMenu) error
-	UpdateMenu(menu entity.Menu) (*entity.Menu, error)
+	CreateMenu(menu entity.Menu, categoryIds []uint, options []entity.MenuOption) (*entity.Menu, error)
+	GetMenus(dto.Query) ([]entity.Menu, int64, error)
+	UpdateMenu(menu entity.Menu, categoryIds []uint, options []entity.MenuOption) (*entity.Menu, error)
 	DeleteMenu(id uint) error
-	UpdateMenuCategories(id uint, menuCategory []entity.CategoriesMenu) error
 	GetMenuById(id uint) (*entity.Menu, error)
-	IsValidMenuOptions(id uint, menuOptions string) error
 	ToggleFavoriteMenu(userId uint, menuId uint) (*entity.MenuFavorite, error)
 	GetUserFavoriteMenus(userId uint) ([]entity.Menu, error)
-	GetCategories() ([]entity.Category, error)
+	SuggestMenus(search string) ([]entity.Menu, error)
+	UpdateMenuAvailability(menuId uint, request dto.MenuAvailabilityRequest) (*entity.Menu, error)
+	SetMenuSoldOut(menuId uint, soldOut bool) (*entity.Menu, error)
 }
 
 type menuUsecaseImpl struct {
@@ -27,6 +28,7 @@ type menuUsecaseImpl struct {
 	menuRepo      repository.MenuRepository
 	mediaUploader util.MediaUploader
 	orderRepo     repository.OrderRepository
+	unitOfWork    repository.UnitOfWork
 }
 
 type MenuUsecaseConfig struct {
@@ -34,6 +36,7 @@ type MenuUsecaseConfig struct {
 	MenuRepo      repository.MenuRepository
 	MediaUploader util.MediaUploader
 	OrderRepo     repository.OrderRepository
+	UnitOfWork    repository.UnitOfWork
 }
 
 func NewMenuUsecase(c MenuUsecaseConfig) MenuUsecase {
@@ -42,11 +45,23 @@ func NewMenuUsecase(c MenuUsecaseConfig) MenuUsecase {
 		menuRepo:      c.MenuRepo,
 		mediaUploader: c.MediaUploader,
 		orderRepo:     c.OrderRepo,
+		unitOfWork:    c.UnitOfWork,
 	}
 }
 
-func (m *menuUsecaseImpl) CreateMenu(menu entity.Menu) (*entity.Menu, error) {
-	resMenu, err := m.menuRepo.CreateMenu(menu)
+// CreateMenu creates the menu with its categories and option groups in one
+// transaction. The returned menu holds the stored option groups.
+func (m *menuUsecaseImpl) CreateMenu(menu entity.Menu, categoryIds []uint, options []entity.MenuOption) (*entity.Menu, error) {
+	var resMenu *entity.Menu
+	err := m.unitOfWork.Do(func(repos repository.TxRepositories) error {
+		var err error
+		resMenu, err = repos.MenuRepo.CreateMenu(menu)
+		if err != nil {
+			return err
+		}
+
+		return saveMenuDetails(repos, resMenu, categoryIds, options)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -54,22 +69,37 @@ func (m *menuUsecaseImpl) CreateMenu(menu entity.Menu) (*entity.Menu, error) {
 	return resMenu, nil
 }
 
-func (m *menuUsecaseImpl) CreateMenuCategories(menuCategory []entity.CategoriesMenu) error {
-	err := m.menuRepo.CreateMenuCategories(menuCategory)
+// saveMenuDetails links the saved menu to its categories, saves its option
+// groups and refreshes its search vector, which includes the category names.
+func saveMenuDetails(repos repository.TxRepositories, menu *entity.Menu, categoryIds []uint, options []entity.MenuOption) error {
+	if len(categoryIds) > 0 {
+		menuCategories := make([]entity.CategoriesMenu, 0, len(categoryIds))
+		for _, categoryId := range util.UniqueUint(categoryIds) {
+			menuCategories = append(menuCategories, entity.CategoriesMenu{CategoryID: categoryId, MenuID: menu.ID})
+		}
+
+		err := repos.MenuRepo.CreateMenuCategories(menuCategories)
+		if err != nil {
+			return err
+		}
+	}
+
+	optionGroups, err := repos.MenuOptionRepo.SaveMenuOptions(menu.ID, options)
 	if err != nil {
 		return err
 	}
+	menu.OptionGroups = optionGroups
 
-	return nil
+	return repos.MenuRepo.RefreshMenuSearchVector(menu.ID)
 }
 
-func (m *menuUsecaseImpl) GetMenus(query dto.Query) ([]entity.Menu, error) {
-	menus, err := m.menuRepo.GetMenus(query)
+func (m *menuUsecaseImpl) GetMenus(query dto.Query) ([]entity.Menu, int64, error) {
+	menus, total, err := m.menuRepo.GetMenus(query)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return menus, nil
+	return menus, total, nil
 }
 
 func (m *menuUsecaseImpl) GetMenuById(id uint) (*entity.Menu, error) {
@@ -85,8 +115,25 @@ func (m *menuUsecaseImpl) GetMenuById(id uint) (*entity.Menu, error) {
 	return menu, nil
 }
 
-func (m *menuUsecaseImpl) UpdateMenu(menu entity.Menu) (*entity.Menu, error) {
-	resMenu, err := m.menuRepo.UpdateMenu(menu)
+// UpdateMenu saves the menu, replaces its categories and saves its option groups in
+// one transaction. The returned menu holds the stored option groups.
+func (m *menuUsecaseImpl) UpdateMenu(menu entity.Menu, categoryIds []uint, options []entity.MenuOption) (*entity.Menu, error) {
+	menu.Categories = nil
+
+	var resMenu *entity.Menu
+	err := m.unitOfWork.Do(func(repos repository.TxRepositories) error {
+		err := repos.MenuRepo.DeleteMenuCategoriesByMenuId(menu.ID)
+		if err != nil {
+			return err
+		}
+
+		resMenu, err = repos.MenuRepo.UpdateMenu(menu)
+		if err != nil {
+			return err
+		}
+
+		return saveMenuDetails(repos, resMenu, categoryIds, options)
+	})
 	if err != nil {
 		return nil, domain.ErrUpdateMenu
 	}
@@ -94,20 +141,6 @@ func (m *menuUsecaseImpl) UpdateMenu(menu entity.Menu) (*entity.Menu, error) {
 	return resMenu, nil
 }
 
-func (m *menuUsecaseImpl) UpdateMenuCategories(id uint, menuCategory []entity.CategoriesMenu) error {
-	err := m.menuRepo.DeleteMenuCategoriesByMenuId(id)
-	if err != nil {
-		return err
-	}
-
-	err = m.menuRepo.CreateMenuCategories(menuCategory)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (m *menuUsecaseImpl) DeleteMenu(id uint) error {
 	menu, err := m.GetMenuById(id)
 	if err != nil {
@@ -122,15 +155,6 @@ func (m *menuUsecaseImpl) DeleteMenu(id uint) error {
 	return nil
 }
 
-func (m *menuUsecaseImpl) IsValidMenuOptions(menuId uint, option string) error {
-	err := m.menuRepo.IsValidMenuOptions(menuId, option)
-	if err != nil {
-		return domain.ErrMalformedRequest
-	}
-
-	return nil
-}
-
 func (m *menuUsecaseImpl) ToggleFavoriteMenu(userId uint, menuId uint) (*entity.MenuFavorite, error) {
 	menu, _ := m.menuRepo.GetMenuById(menuId)
 	if menu == nil {
@@ -154,11 +178,52 @@ func (m *menuUsecaseImpl) GetUserFavoriteMenus(userId uint) ([]entity.Menu, erro
 	return menus, nil
 }
 
-func (m *menuUsecaseImpl) GetCategories() ([]entity.Category, error) {
-	categories, err := m.menuRepo.GetCategories()
+// SuggestMenus returns up to menuSuggestionLimit menus for autocomplete.
+func (m *menuUsecaseImpl) SuggestMenus(search string) ([]entity.Menu, error) {
+	return m.menuRepo.SuggestMenus(search, menuSuggestionLimit)
+}
+
+func (m *menuUsecaseImpl) UpdateMenuAvailability(menuId uint, request dto.MenuAvailabilityRequest) (*entity.Menu, error) {
+	menu, err := m.GetMenuById(menuId)
 	if err != nil {
 		return nil, err
 	}
 
-	return categories, nil
+	windows := []entity.MenuAvailability{}
+	for _, windowRequest := range request.Windows {
+		window := entity.MenuAvailability{
+			DayOfWeek: windowRequest.DayOfWeek,
+			StartTime: windowRequest.StartTime,
+			EndTime:   windowRequest.EndTime,
+		}
+
+		err = validateAvailabilityWindow(window)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	err = m.menuRepo.ReplaceMenuAvailability(menu.ID, windows)
+	if err != nil {
+		return nil, domain.ErrUpdateMenu
+	}
+
+	return m.SetMenuSoldOut(menu.ID, request.IsSoldOut)
+}
+
+// SetMenuSoldOut lets the kitchen take a menu off sale and back without
+// touching its availability windows.
+func (m *menuUsecaseImpl) SetMenuSoldOut(menuId uint, soldOut bool) (*entity.Menu, error) {
+	menu, err := m.GetMenuById(menuId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.menuRepo.UpdateMenuSoldOut(menu.ID, soldOut)
+	if err != nil {
+		return nil, domain.ErrUpdateMenu
+	}
+
+	return m.GetMenuById(menuId)
 }
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"final-project-backend/entity"
+	"sync"
+)
+
+// orderEventBuffer is how many events a slow subscriber may fall behind
+// before further events for it are dropped.
+const orderEventBuffer = 16
+
+// OrderEventHub is an in-process pub/sub of order status events keyed by
+// order ID. It only reaches subscribers connected to this server instance.
+type OrderEventHub interface {
+	Subscribe(orderId uint) (<-chan entity.OrderStatusEvent, func())
+	Publish(event entity.OrderStatusEvent)
+}
+
+type orderEventHubImpl struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan entity.OrderStatusEvent]struct{}
+}
+
+func NewOrderEventHub() OrderEventHub {
+	return &orderEventHubImpl{
+		subscribers: map[uint]map[chan entity.OrderStatusEvent]struct{}{},
+	}
+}
+
+// Subscribe returns a channel receiving the order's events and a function that
+// unsubscribes and closes the channel.
+func (h *orderEventHubImpl) Subscribe(orderId uint) (<-chan entity.OrderStatusEvent, func()) {
+	ch := make(chan entity.OrderStatusEvent, orderEventBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[orderId] == nil {
+		h.subscribers[orderId] = map[chan entity.OrderStatusEvent]struct{}{}
+	}
+	h.subscribers[orderId][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[orderId], ch)
+			if len(h.subscribers[orderId]) == 0 {
+				delete(h.subscribers, orderId)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish never blocks; subscribers whose buffer is full miss the event.
+func (h *orderEventHubImpl) Publish(event entity.OrderStatusEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[event.OrderID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package usecase
+
+const (
+	OrderStatusPlaced    = "placed"
+	OrderStatusConfirmed = "confirmed"
+	OrderStatusPreparing = "preparing"
+	OrderStatusOnTheWay  = "on the way"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+// OrderStatusTransitions lists the statuses an order may move to from each
+// status. Delivered and cancelled orders are final.
+var OrderStatusTransitions = map[string][]string{
+	OrderStatusPlaced:    {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed: {OrderStatusPreparing, OrderStatusCancelled},
+	OrderStatusPreparing: {OrderStatusOnTheWay, OrderStatusCancelled},
+	OrderStatusOnTheWay:  {OrderStatusDelivered},
+	OrderStatusDelivered: {},
+	OrderStatusCancelled: {},
+}
+
+// userCancellableStatuses are the statuses in which the customer may still
+// cancel, i.e. before the kitchen starts preparing the order.
+var userCancellableStatuses = map[string]bool{
+	OrderStatusPlaced:    true,
+	OrderStatusConfirmed: true,
+}
+
+// deliveryStatusByOrderStatus mirrors the order status onto its delivery.
+var deliveryStatusByOrderStatus = map[string]string{
+	OrderStatusPlaced:    "pending",
+	OrderStatusConfirmed: "pending",
+	OrderStatusPreparing: "pending",
+	OrderStatusOnTheWay:  "on the way",
+	OrderStatusDelivered: "delivered",
+	OrderStatusCancelled: "cancelled",
+}
+
+func canTransitionOrderStatus(from string, to string) bool {
+	for _, status := range OrderStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// currentOrderStatus treats orders created before statuses existed as placed.
+func currentOrderStatus(status string) string {
+	if status == "" {
+		return OrderStatusPlaced
+	}
+
+	return status
+}
+
+// IsFinalOrderStatus reports whether no further transitions are possible.
+func IsFinalOrderStatus(status string) bool {
+	return len(OrderStatusTransitions[status]) == 0
+}
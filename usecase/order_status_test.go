@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"reflect"
+	"testing"
+)
+
+type orderStatusFixture struct {
+	orderRepo    *fakeOrderRepo
+	deliveryRepo *fakeDeliveryRepo
+	couponRepo   *fakeCouponRepo
+	orderUsecase OrderUsecase
+}
+
+// newOrderStatusFixture stores order 1 of user 1 in the given status, with
+// coupon 3 when withCoupon is set, and its delivery 2.
+func newOrderStatusFixture(status string, withCoupon bool) orderStatusFixture {
+	order := &entity.Order{UserID: 1, Status: status}
+	order.ID = 1
+	if withCoupon {
+		couponId := uint(3)
+		order.CouponID = &couponId
+	}
+
+	delivery := &entity.Delivery{OrderID: 1, Status: deliveryStatusByOrderStatus[status]}
+	delivery.ID = 2
+
+	f := orderStatusFixture{
+		orderRepo:    &fakeOrderRepo{orders: map[uint]*entity.Order{1: order}},
+		deliveryRepo: &fakeDeliveryRepo{deliveries: map[uint]*entity.Delivery{1: delivery}},
+		couponRepo:   &fakeCouponRepo{},
+	}
+	f.orderUsecase = NewOrderUsecase(OrderUsecaseConfig{
+		OrderRepo:    f.orderRepo,
+		DeliveryRepo: f.deliveryRepo,
+		UnitOfWork: &fakeUnitOfWork{repos: repository.TxRepositories{
+			OrderRepo:    f.orderRepo,
+			DeliveryRepo: f.deliveryRepo,
+			CouponRepo:   f.couponRepo,
+		}},
+		EventHub: NewOrderEventHub(),
+	})
+
+	return f
+}
+
+func TestCanTransitionOrderStatus(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{OrderStatusPlaced, OrderStatusConfirmed, true},
+		{OrderStatusPlaced, OrderStatusCancelled, true},
+		{OrderStatusPlaced, OrderStatusPreparing, false},
+		{OrderStatusConfirmed, OrderStatusPreparing, true},
+		{OrderStatusPreparing, OrderStatusOnTheWay, true},
+		{OrderStatusPreparing, OrderStatusCancelled, true},
+		{OrderStatusOnTheWay, OrderStatusDelivered, true},
+		{OrderStatusOnTheWay, OrderStatusCancelled, false},
+		{OrderStatusDelivered, OrderStatusCancelled, false},
+		{OrderStatusCancelled, OrderStatusPlaced, false},
+		{OrderStatusConfirmed, OrderStatusPlaced, false},
+	}
+
+	for _, tt := range tests {
+		if got := canTransitionOrderStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("canTransitionOrderStatus(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	for _, status := range []string{OrderStatusDelivered, OrderStatusCancelled} {
+		if !IsFinalOrderStatus(status) {
+			t.Errorf("expected %q to be final", status)
+		}
+	}
+	if currentOrderStatus("") != OrderStatusPlaced {
+		t.Error("expected orders without a status to be placed")
+	}
+}
+
+func TestUpdateOrderStatusRecordsTransition(t *testing.T) {
+	f := newOrderStatusFixture(OrderStatusPreparing, false)
+
+	order, err := f.orderUsecase.UpdateOrderStatus(1, OrderStatusOnTheWay, dto.UserResponse{ID: 9}, "rider picked up")
+	if err != nil {
+		t.Fatalf("update order status: %v", err)
+	}
+
+	if order.Status != OrderStatusOnTheWay || f.deliveryRepo.deliveries[1].Status != "on the way" {
+		t.Errorf("expected the order and delivery to be on the way, got %q and %q", order.Status, f.deliveryRepo.deliveries[1].Status)
+	}
+	if f.deliveryRepo.deliveries[1].DeliveryDate.IsZero() {
+		t.Error("expected the delivery date to be set")
+	}
+
+	want := entity.OrderStatusEvent{OrderID: 1, FromStatus: OrderStatusPreparing, ToStatus: OrderStatusOnTheWay, ActorID: 9, Note: "rider picked up"}
+	if len(f.orderRepo.events) != 1 || f.orderRepo.events[0] != want {
+		t.Errorf("expected event %+v, got %+v", want, f.orderRepo.events)
+	}
+
+	_, err = f.orderUsecase.UpdateOrderStatus(1, OrderStatusPlaced, dto.UserResponse{ID: 9}, "")
+	if !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Errorf("expected moving back to be rejected, got %v", err)
+	}
+
+	_, err = f.orderUsecase.UpdateOrderStatus(1, "lost", dto.UserResponse{ID: 9}, "")
+	if !errors.Is(err, domain.ErrInvalidOrderStatus) {
+		t.Errorf("expected an unknown status to be rejected, got %v", err)
+	}
+}
+
+func TestCancelOrderOnlyBeforePreparing(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr error
+	}{
+		{OrderStatusPlaced, nil},
+		{OrderStatusConfirmed, nil},
+		{OrderStatusPreparing, domain.ErrOrderNotCancellable},
+		{OrderStatusOnTheWay, domain.ErrOrderNotCancellable},
+		{OrderStatusDelivered, domain.ErrOrderNotCancellable},
+	}
+
+	for _, tt := range tests {
+		f := newOrderStatusFixture(tt.status, false)
+
+		_, err := f.orderUsecase.CancelOrder(1, dto.UserResponse{ID: 1})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("cancel %q order: expected %v, got %v", tt.status, tt.wantErr, err)
+		}
+	}
+
+	f := newOrderStatusFixture(OrderStatusPlaced, false)
+	_, err := f.orderUsecase.CancelOrder(1, dto.UserResponse{ID: 2})
+	if !errors.Is(err, domain.ErrOrderNotFound) {
+		t.Errorf("expected another user's order to be not found, got %v", err)
+	}
+}
+
+func TestCancelOrderRestoresCouponAndStock(t *testing.T) {
+	f := newOrderStatusFixture(OrderStatusConfirmed, true)
+
+	order, err := f.orderUsecase.CancelOrder(1, dto.UserResponse{ID: 1})
+	if err != nil {
+		t.Fatalf("cancel order: %v", err)
+	}
+
+	if order.Status != OrderStatusCancelled || f.deliveryRepo.deliveries[1].Status != "cancelled" {
+		t.Errorf("expected the order and delivery to be cancelled, got %q and %q", order.Status, f.deliveryRepo.deliveries[1].Status)
+	}
+	if len(f.orderRepo.restoredStock) != 1 {
+		t.Errorf("expected the stock to be restored once, got %v", f.orderRepo.restoredStock)
+	}
+	if len(f.couponRepo.restored) != 1 || f.couponRepo.restored[0] != 3 {
+		t.Errorf("expected coupon 3 to be given back, got %v", f.couponRepo.restored)
+	}
+	if len(f.couponRepo.cancelledRedemptions) != 1 {
+		t.Errorf("expected the redemption to be cancelled, got %v", f.couponRepo.cancelledRedemptions)
+	}
+
+	f = newOrderStatusFixture(OrderStatusPlaced, false)
+	_, err = f.orderUsecase.CancelOrder(1, dto.UserResponse{ID: 1})
+	if err != nil {
+		t.Fatalf("cancel order without coupon: %v", err)
+	}
+	if len(f.couponRepo.restored) != 0 || len(f.orderRepo.restoredStock) != 1 {
+		t.Errorf("expected only the stock to be restored, got coupons %v and stock %v", f.couponRepo.restored, f.orderRepo.restoredStock)
+	}
+}
+
+func TestGetAllOrdersRejectsUnknownStatus(t *testing.T) {
+	f := newOrderStatusFixture(OrderStatusPlaced, false)
+
+	_, _, err := f.orderUsecase.GetAllOrders(dto.UserResponse{ID: 1}, dto.Query{Status: "lost"})
+	if !errors.Is(err, domain.ErrInvalidQuery) {
+		t.Errorf("expected an unknown status filter to be rejected, got %v", err)
+	}
+
+	orders, total, err := f.orderUsecase.GetAllOrders(dto.UserResponse{ID: 1}, dto.Query{Status: OrderStatusPlaced})
+	if err != nil || total != 1 || len(orders) != 1 {
+		t.Errorf("expected the placed order, got %v of %d and %v", orders, total, err)
+	}
+}
+
+func TestGetUserOrderByIDReturnsPromotions(t *testing.T) {
+	f := newOrderStatusFixture(OrderStatusPlaced, false)
+	f.orderRepo.orders[1].Promotions = []entity.OrderPromotion{
+		{PromotionID: 4, Name: "Half price burgers", RuleType: entity.PromotionRuleCategoryPercentage, Discount: 15000},
+	}
+
+	order, err := f.orderUsecase.GetUserOrderByID(1, dto.UserResponse{ID: 1})
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+
+	want := []dto.AppliedPromotion{{PromotionID: 4, Name: "Half price burgers", RuleType: entity.PromotionRuleCategoryPercentage, Discount: 15000}}
+	if !reflect.DeepEqual(order.Promotions, want) {
+		t.Errorf("expected promotions %+v, got %+v", want, order.Promotions)
+	}
+
+	f.orderRepo.orders[1].Promotions = nil
+	order, err = f.orderUsecase.GetUserOrderByID(1, dto.UserResponse{ID: 1})
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	if order.Promotions == nil || len(order.Promotions) != 0 {
+		t.Errorf("expected an empty promotions list, got %#v", order.Promotions)
+	}
+}
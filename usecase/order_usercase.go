@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"encoding/json"
 	"final-project-backend/domain"
 	"final-project-backend/dto"
 	"final-project-backend/entity"
@@ -9,8 +10,9 @@ import (
 )
 
 type OrderUsecase interface {
-	GetAllOrders(dto.UserResponse, dto.Query) ([]entity.Order, error)
+	GetAllOrders(dto.UserResponse, dto.Query) ([]entity.Order, int64, error)
 	GetOrderByID(id uint) (*entity.Order, error)
+	GetUserOrderByID(id uint, user dto.UserResponse) (*dto.OrderResponse, error)
 	GetAllPaymentOptions() ([]entity.PaymentOption, error)
 	CreateOrder(entity.Order) (*entity.Order, error)
 	CreateOrderProcess(entity.Order, []entity.OrderDetail, entity.Delivery) (*entity.Order, error)
@@ -18,6 +20,10 @@ type OrderUsecase interface {
 	CreateCustomerReview(dto.CustomerReviewRequest) (*entity.CustomerReview, error)
 	GetCustomerReviewsByMenuId(id uint) (*[]entity.CustomerReview, error)
 	GetTransactionTotalByDate(date time.Time) (int64, error)
+	UpdateOrderStatus(orderId uint, status string, actor dto.UserResponse, note string) (*entity.Order, error)
+	CancelOrder(orderId uint, user dto.UserResponse) (*entity.Order, error)
+	GetOrderTimeline(orderId uint, user dto.UserResponse) ([]entity.OrderStatusEvent, error)
+	SubscribeOrderEvents(orderId uint, user dto.UserResponse) (<-chan entity.OrderStatusEvent, func(), error)
 }
 
 type orderUsecaseImpl struct {
@@ -26,6 +32,8 @@ type orderUsecaseImpl struct {
 	menuRepo       repository.MenuRepository
 	paymentOptRepo repository.PaymentOptionRepository
 	deliveryRepo   repository.DeliveryRepository
+	unitOfWork     repository.UnitOfWork
+	eventHub       OrderEventHub
 }
 
 type OrderUsecaseConfig struct {
@@ -34,6 +42,8 @@ type OrderUsecaseConfig struct {
 	MenuRepo       repository.MenuRepository
 	PaymentOptRepo repository.PaymentOptionRepository
 	DeliveryRepo   repository.DeliveryRepository
+	UnitOfWork     repository.UnitOfWork
+	EventHub       OrderEventHub
 }
 
 func NewOrderUsecase(c OrderUsecaseConfig) OrderUsecase {
@@ -43,10 +53,18 @@ func NewOrderUsecase(c OrderUsecaseConfig) OrderUsecase {
 		menuRepo:       c.MenuRepo,
 		paymentOptRepo: c.PaymentOptRepo,
 		deliveryRepo:   c.DeliveryRepo,
+		unitOfWork:     c.UnitOfWork,
+		eventHub:       c.EventHub,
 	}
 }
 
-func (o *orderUsecaseImpl) GetAllOrders(user dto.UserResponse, query dto.Query) ([]entity.Order, error) {
+// GetAllOrders lists the user's orders, or every order for admins. The status
+// filter only takes the known order statuses.
+func (o *orderUsecaseImpl) GetAllOrders(user dto.UserResponse, query dto.Query) ([]entity.Order, int64, error) {
+	if _, ok := OrderStatusTransitions[query.Status]; query.Status != "" && !ok {
+		return nil, 0, domain.ErrInvalidQuery
+	}
+
 	return o.orderRepo.GetAllOrders(user, query)
 }
 
@@ -63,6 +81,66 @@ func (o *orderUsecaseImpl) GetOrderByID(id uint) (*entity.Order, error) {
 	return order, nil
 }
 
+// GetUserOrderByID returns the order with its menu options parsed. Users can
+// only read their own orders while admins can read any. Other users' orders
+// are reported as not found so their IDs do not leak.
+func (o *orderUsecaseImpl) GetUserOrderByID(id uint, user dto.UserResponse) (*dto.OrderResponse, error) {
+	order, err := o.GetOrderByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role != "admin" && order.UserID != user.ID {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	var orderDetails []entity.OrderDetailResp
+	for _, orderDetail := range order.OrderDetails {
+		var menuOptions []entity.MenuOption
+		if orderDetail.MenuOptions != "" {
+			err = json.Unmarshal([]byte(orderDetail.MenuOptions), &menuOptions)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		orderDetails = append(orderDetails, entity.OrderDetailResp{
+			ID:          orderDetail.ID,
+			OrderID:     orderDetail.OrderID,
+			MenuID:      orderDetail.MenuID,
+			Quantity:    orderDetail.Quantity,
+			MenuOptions: menuOptions,
+			IsReviewed:  orderDetail.IsReviewed,
+			Menu:        orderDetail.Menu,
+		})
+	}
+
+	promotions := []dto.AppliedPromotion{}
+	for _, promotion := range order.Promotions {
+		promotions = append(promotions, dto.AppliedPromotion{
+			PromotionID: promotion.PromotionID,
+			Name:        promotion.Name,
+			RuleType:    promotion.RuleType,
+			Discount:    promotion.Discount,
+		})
+	}
+
+	return &dto.OrderResponse{
+		ID:              order.ID,
+		OrderDate:       order.OrderDate,
+		CouponID:        order.CouponID,
+		PaymentOptionID: order.PaymentOptionID,
+		PaymentOption:   order.PaymentOption,
+		OrderedMenus:    order.OrderedMenus,
+		Status:          currentOrderStatus(order.Status),
+		TotalPrice:      order.TotalPrice,
+		UserID:          order.UserID,
+		Delivery:        order.Delivery,
+		OrderDetails:    orderDetails,
+		Promotions:      promotions,
+	}, nil
+}
+
 func (o *orderUsecaseImpl) GetAllPaymentOptions() ([]entity.PaymentOption, error) {
 	return o.paymentOptRepo.GetAllPaymentOptions()
 }
@@ -130,3 +208,134 @@ func (o *orderUsecaseImpl) GetCustomerReviewsByMenuId(id uint) (*[]entity.Custom
 func (o *orderUsecaseImpl) GetTransactionTotalByDate(date time.Time) (int64, error) {
 	return o.orderRepo.GetTransactionTotalByDate(date)
 }
+
+func (o *orderUsecaseImpl) UpdateOrderStatus(orderId uint, status string, actor dto.UserResponse, note string) (*entity.Order, error) {
+	if _, ok := OrderStatusTransitions[status]; !ok {
+		return nil, domain.ErrInvalidOrderStatus
+	}
+
+	order, _ := o.orderRepo.GetOrderByID(orderId)
+	if order == nil {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	return o.transitionOrder(*order, status, actor.ID, note)
+}
+
+func (o *orderUsecaseImpl) CancelOrder(orderId uint, user dto.UserResponse) (*entity.Order, error) {
+	order, _ := o.orderRepo.GetOrderByID(orderId)
+	if order == nil {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	if order.UserID != user.ID {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	if !userCancellableStatuses[currentOrderStatus(order.Status)] {
+		return nil, domain.ErrOrderNotCancellable
+	}
+
+	return o.transitionOrder(*order, OrderStatusCancelled, user.ID, "")
+}
+
+// GetOrderTimeline returns the status events of the order, oldest first. Only
+// the customer who placed the order and admins can see it.
+func (o *orderUsecaseImpl) GetOrderTimeline(orderId uint, user dto.UserResponse) ([]entity.OrderStatusEvent, error) {
+	order, _ := o.orderRepo.GetOrderByID(orderId)
+	if order == nil {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	if user.Role != "admin" && order.UserID != user.ID {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	return o.orderRepo.GetOrderStatusEvents(order.ID)
+}
+
+// SubscribeOrderEvents subscribes to the order's status events. Like the
+// timeline, only the order's customer and admins may subscribe.
+func (o *orderUsecaseImpl) SubscribeOrderEvents(orderId uint, user dto.UserResponse) (<-chan entity.OrderStatusEvent, func(), error) {
+	order, _ := o.orderRepo.GetOrderByID(orderId)
+	if order == nil {
+		return nil, nil, domain.ErrOrderNotFound
+	}
+
+	if user.Role != "admin" && order.UserID != user.ID {
+		return nil, nil, domain.ErrOrderNotFound
+	}
+
+	events, unsubscribe := o.eventHub.Subscribe(order.ID)
+	return events, unsubscribe, nil
+}
+
+// transitionOrder moves the order and its delivery to the new status in one
+// transaction, recording the transition on the order's timeline and giving the
+// coupon back when the order is cancelled.
+func (o *orderUsecaseImpl) transitionOrder(order entity.Order, status string, actorId uint, note string) (*entity.Order, error) {
+	if !canTransitionOrderStatus(currentOrderStatus(order.Status), status) {
+		return nil, domain.ErrInvalidStatusTransition
+	}
+
+	var event *entity.OrderStatusEvent
+	err := o.unitOfWork.Do(func(repos repository.TxRepositories) error {
+		err := repos.OrderRepo.UpdateOrderStatus(order, status)
+		if err != nil {
+			return err
+		}
+
+		event, err = repos.OrderRepo.CreateOrderStatusEvent(entity.OrderStatusEvent{
+			OrderID:    order.ID,
+			FromStatus: currentOrderStatus(order.Status),
+			ToStatus:   status,
+			ActorID:    actorId,
+			Note:       note,
+		})
+		if err != nil {
+			return err
+		}
+
+		delivery, err := repos.DeliveryRepo.GetDeliveryByOrderID(order.ID)
+		if err != nil {
+			return err
+		}
+
+		delivery.Status = deliveryStatusByOrderStatus[status]
+		if status == OrderStatusOnTheWay {
+			delivery.DeliveryDate = time.Now()
+		}
+
+		_, err = repos.DeliveryRepo.UpdateDeliveryStatus(*delivery)
+		if err != nil {
+			return err
+		}
+
+		if status == OrderStatusCancelled {
+			err = repos.OrderRepo.RestoreOrderStock(order.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if status == OrderStatusCancelled && order.CouponID != nil {
+			err = repos.CouponRepo.CancelCouponRedemption(order.ID)
+			if err != nil {
+				return err
+			}
+
+			return repos.CouponRepo.RestoreUserCoupon(*order.CouponID, order.UserID)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// publish only after commit so subscribers never see a rolled back status
+	o.eventHub.Publish(*event)
+
+	return o.orderRepo.GetOrderByID(order.ID)
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"time"
+)
+
+// PricingEngine prices order lines from the menus stored in the database, so
+// the prices sent by the client are never trusted.
+type PricingEngine interface {
+	QuoteOrder(userId uint, details []*dto.OrderDetailRequest, coupon *entity.Coupon) (*dto.OrderQuote, error)
+	QuotePromotionOrder(userId uint, promotion entity.Promotion, details []*dto.OrderDetailRequest, coupon *entity.Coupon) (*dto.OrderQuote, error)
+}
+
+type pricingEngineImpl struct {
+	menuRepo            repository.MenuRepository
+	promotionRepo       repository.PromotionRepository
+	menuOptionValidator MenuOptionValidator
+	couponUsecase       CouponUsecase
+	deliveryFee         int
+}
+
+type PricingEngineConfig struct {
+	MenuRepo            repository.MenuRepository
+	PromotionRepo       repository.PromotionRepository
+	MenuOptionValidator MenuOptionValidator
+	CouponUsecase       CouponUsecase
+	DeliveryFee         int
+}
+
+func NewPricingEngine(c PricingEngineConfig) PricingEngine {
+	return &pricingEngineImpl{
+		menuRepo:            c.MenuRepo,
+		promotionRepo:       c.PromotionRepo,
+		menuOptionValidator: c.MenuOptionValidator,
+		couponUsecase:       c.CouponUsecase,
+		deliveryFee:         c.DeliveryFee,
+	}
+}
+
+// QuoteOrder prices the order with the active promotions. A coupon only joins
+// the promotions that stack with coupons, so when a promotion that does not
+// stack gives more off, the quote keeps that promotion and drops the coupon,
+// leaving CouponID unset.
+func (p *pricingEngineImpl) QuoteOrder(userId uint, details []*dto.OrderDetailRequest, coupon *entity.Coupon) (*dto.OrderQuote, error) {
+	quote := dto.OrderQuote{DeliveryFee: p.deliveryFee}
+
+	for _, detail := range details {
+		line, err := p.priceLine(detail, true)
+		if err != nil {
+			return nil, err
+		}
+
+		quote.Lines = append(quote.Lines, *line)
+		quote.Subtotal += line.Subtotal
+	}
+
+	promotions, err := p.promotionRepo.GetActivePromotionRules(time.Now())
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	withoutCoupon := quote
+	withoutCoupon.Lines = append([]dto.OrderQuoteLine(nil), quote.Lines...)
+	applyPromotions(&withoutCoupon, promotions, false)
+	totalQuote(&withoutCoupon)
+	if coupon == nil {
+		return &withoutCoupon, nil
+	}
+
+	applyPromotions(&quote, promotions, true)
+	err = p.applyCoupon(&quote, userId, coupon)
+	if err != nil {
+		return nil, err
+	}
+
+	if withoutCoupon.TotalPrice <= quote.TotalPrice {
+		return &withoutCoupon, nil
+	}
+
+	return &quote, nil
+}
+
+// QuotePromotionOrder prices a promotion bundle: the promotion price replaces
+// the menu prices, while checked options are still charged per quantity.
+func (p *pricingEngineImpl) QuotePromotionOrder(userId uint, promotion entity.Promotion, details []*dto.OrderDetailRequest, coupon *entity.Coupon) (*dto.OrderQuote, error) {
+	quote := dto.OrderQuote{
+		PromotionPrice: promotion.Price,
+		Subtotal:       promotion.Price,
+		DeliveryFee:    p.deliveryFee,
+	}
+
+	for _, detail := range details {
+		if !isPromotionMenu(promotion, detail.MenuID) {
+			return nil, domain.ErrMenuNotInPromotion
+		}
+
+		line, err := p.priceLine(detail, false)
+		if err != nil {
+			return nil, err
+		}
+
+		quote.Lines = append(quote.Lines, *line)
+		quote.Subtotal += line.Subtotal
+	}
+
+	err := p.applyCoupon(&quote, userId, coupon)
+	if err != nil {
+		return nil, err
+	}
+
+	return &quote, nil
+}
+
+func (p *pricingEngineImpl) priceLine(detail *dto.OrderDetailRequest, chargeMenuPrice bool) (*dto.OrderQuoteLine, error) {
+	if detail.Quantity <= 0 {
+		return nil, domain.ErrInvalidQuantity
+	}
+
+	menu, _ := p.menuRepo.GetMenuById(detail.MenuID)
+	if menu == nil {
+		return nil, domain.ErrMenuNotFound
+	}
+
+	if !IsMenuAvailable(*menu, time.Now()) {
+		return nil, domain.ErrMenuUnavailable
+	}
+
+	menuOptions, surcharge, err := p.menuOptionValidator.Validate(menu.ID, detail.MenuOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	line := dto.OrderQuoteLine{
+		MenuID:          menu.ID,
+		MenuName:        menu.Name,
+		Quantity:        detail.Quantity,
+		OptionSurcharge: surcharge,
+		MenuOptions:     menuOptions,
+	}
+	for _, category := range menu.Categories {
+		line.CategoryIDs = append(line.CategoryIDs, category.ID)
+	}
+
+	if chargeMenuPrice {
+		line.UnitPrice = menu.Price
+	}
+
+	line.Subtotal = (line.UnitPrice + line.OptionSurcharge) * line.Quantity
+
+	return &line, nil
+}
+
+func isPromotionMenu(promotion entity.Promotion, menuId uint) bool {
+	for _, promotionDetail := range promotion.PromotionDetails {
+		if promotionDetail.MenuID == menuId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyCoupon lets the coupon usecase evaluate the coupon against the priced
+// lines left to pay after the promotions and totals the quote.
+func (p *pricingEngineImpl) applyCoupon(quote *dto.OrderQuote, userId uint, coupon *entity.Coupon) error {
+	if coupon != nil {
+		evaluation, err := p.couponUsecase.Evaluate(*coupon, userId, *quote)
+		if err != nil {
+			return err
+		}
+
+		quote.CouponID = &coupon.ID
+		quote.CouponDiscount = evaluation.Discount
+		quote.DeliveryDiscount = evaluation.DeliveryDiscount
+		if remaining := quote.Subtotal - quote.PromotionDiscount; quote.CouponDiscount > remaining {
+			quote.CouponDiscount = remaining
+		}
+	}
+
+	totalQuote(quote)
+
+	return nil
+}
+
+// totalQuote totals the quote with its discounts and delivery fee.
+func totalQuote(quote *dto.OrderQuote) {
+	quote.TotalPrice = quote.Subtotal - quote.PromotionDiscount - quote.CouponDiscount + quote.DeliveryFee - quote.DeliveryDiscount
+	if quote.TotalPrice < 0 {
+		quote.TotalPrice = 0
+	}
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"testing"
+)
+
+func newTestPricingEngine(menus map[uint]*entity.Menu, options []entity.MenuOption, evaluation dto.CouponEvaluation, promotions ...entity.Promotion) PricingEngine {
+	return NewPricingEngine(PricingEngineConfig{
+		MenuRepo:      &fakeMenuRepo{menus: menus},
+		PromotionRepo: &fakePromotionRepo{rules: promotions},
+		MenuOptionValidator: NewMenuOptionValidator(MenuOptionValidatorConfig{
+			MenuOptionRepo: &fakeMenuOptionRepo{options: options},
+		}),
+		CouponUsecase: &fakeCouponUsecase{evaluation: evaluation},
+		DeliveryFee:   10000,
+	})
+}
+
+func sizeOptions() []entity.MenuOption {
+	return []entity.MenuOption{{
+		ID: 1, Title: "Size", Min: 0, Max: 1, Available: true,
+		MenuOptionLists: []entity.MenuOptionList{
+			{ID: 1, Name: "Regular", Available: true},
+			{ID: 2, Name: "Large", Price: 5000, Available: true},
+		},
+	}}
+}
+
+func TestQuoteOrderChargesOptionSurcharges(t *testing.T) {
+	menus := map[uint]*entity.Menu{1: testMenu(1, "Burger", 30000)}
+	engine := newTestPricingEngine(menus, sizeOptions(), dto.CouponEvaluation{})
+
+	quote, err := engine.QuoteOrder(1, []*dto.OrderDetailRequest{{
+		MenuID:   1,
+		Quantity: 2,
+		MenuOptions: []entity.MenuOption{{ID: 1, MenuOptionLists: []entity.MenuOptionList{
+			{ID: 2, Checked: true},
+		}}},
+	}}, nil)
+	if err != nil {
+		t.Fatalf("quote order: %v", err)
+	}
+
+	line := quote.Lines[0]
+	if line.UnitPrice != 30000 || line.OptionSurcharge != 5000 || line.Subtotal != 70000 {
+		t.Errorf("expected (30000 + 5000) x 2 = 70000, got %+v", line)
+	}
+	if quote.Subtotal != 70000 || quote.TotalPrice != 80000 {
+		t.Errorf("expected subtotal 70000 and total 80000, got %d and %d", quote.Subtotal, quote.TotalPrice)
+	}
+}
+
+func TestQuotePromotionOrderOnlyChargesOptions(t *testing.T) {
+	menus := map[uint]*entity.Menu{1: testMenu(1, "Burger", 30000)}
+	engine := newTestPricingEngine(menus, sizeOptions(), dto.CouponEvaluation{})
+	promotion := entity.Promotion{Price: 45000, PromotionDetails: []entity.PromotionDetail{{MenuID: 1}}}
+
+	quote, err := engine.QuotePromotionOrder(1, promotion, []*dto.OrderDetailRequest{{
+		MenuID:   1,
+		Quantity: 2,
+		MenuOptions: []entity.MenuOption{{ID: 1, MenuOptionLists: []entity.MenuOptionList{
+			{ID: 2, Checked: true},
+		}}},
+	}}, nil)
+	if err != nil {
+		t.Fatalf("quote promotion order: %v", err)
+	}
+
+	line := quote.Lines[0]
+	if line.UnitPrice != 0 || line.Subtotal != 10000 {
+		t.Errorf("expected the menu to be free and only the surcharge charged, got %+v", line)
+	}
+	if quote.Subtotal != 55000 || quote.TotalPrice != 65000 {
+		t.Errorf("expected subtotal 55000 and total 65000, got %d and %d", quote.Subtotal, quote.TotalPrice)
+	}
+
+	_, err = engine.QuotePromotionOrder(1, promotion, []*dto.OrderDetailRequest{{MenuID: 2, Quantity: 1}}, nil)
+	if err == nil {
+		t.Error("expected a menu outside the promotion to be rejected")
+	}
+}
+
+func TestQuoteOrderFloorsCouponAtZero(t *testing.T) {
+	menus := map[uint]*entity.Menu{1: testMenu(1, "Burger", 30000)}
+	engine := newTestPricingEngine(menus, nil, dto.CouponEvaluation{Discount: 50000, DeliveryDiscount: 10000})
+	coupon := entity.Coupon{}
+	coupon.ID = 7
+
+	quote, err := engine.QuoteOrder(1, []*dto.OrderDetailRequest{{MenuID: 1, Quantity: 1}}, &coupon)
+	if err != nil {
+		t.Fatalf("quote order: %v", err)
+	}
+
+	if quote.CouponID == nil || *quote.CouponID != 7 {
+		t.Errorf("expected coupon 7 on the quote, got %v", quote.CouponID)
+	}
+	if quote.CouponDiscount != 30000 {
+		t.Errorf("expected the coupon to be capped at the subtotal, got %d", quote.CouponDiscount)
+	}
+	if quote.TotalPrice != 0 {
+		t.Errorf("expected the total to floor at zero, got %d", quote.TotalPrice)
+	}
+}
+
+func TestQuoteOrderKeepsTheCheaperOfCouponAndPromotion(t *testing.T) {
+	burger := testMenu(1, "Burger", 30000)
+	burger.Categories = []entity.Category{testCategory(1)}
+	menus := map[uint]*entity.Menu{1: burger}
+	halfPrice := categoryPercentage(4, 1, 50)
+	coupon := entity.Coupon{}
+	coupon.ID = 7
+
+	tests := []struct {
+		name           string
+		couponDiscount int
+		wantCoupon     bool
+		wantTotal      int
+	}{
+		{name: "promotion gives more", couponDiscount: 5000, wantTotal: 25000},
+		{name: "coupon gives more", couponDiscount: 20000, wantCoupon: true, wantTotal: 20000},
+		{name: "tie keeps the coupon unused", couponDiscount: 15000, wantTotal: 25000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newTestPricingEngine(menus, nil, dto.CouponEvaluation{Discount: tt.couponDiscount}, halfPrice)
+
+			quote, err := engine.QuoteOrder(1, []*dto.OrderDetailRequest{{MenuID: 1, Quantity: 1}}, &coupon)
+			if err != nil {
+				t.Fatalf("quote order: %v", err)
+			}
+
+			if (quote.CouponID != nil) != tt.wantCoupon || quote.TotalPrice != tt.wantTotal {
+				t.Fatalf("expected coupon %v and total %d, got %+v", tt.wantCoupon, tt.wantTotal, quote)
+			}
+			if tt.wantCoupon && (len(quote.Promotions) != 0 || quote.PromotionDiscount != 0) {
+				t.Errorf("expected the promotion to make way for the coupon, got %+v", quote.Promotions)
+			}
+			if !tt.wantCoupon && (len(quote.Promotions) != 1 || quote.CouponDiscount != 0) {
+				t.Errorf("expected only the promotion, got %+v", quote)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+)
+
+// applyPromotions applies the automatic promotions to the quote in the given
+// order, which must be by descending priority. Every discount is taken off the
+// lines it comes from, so later promotions and the coupon only see what those
+// lines have left to pay. Promotions giving no discount never block the ones
+// after them.
+func applyPromotions(quote *dto.OrderQuote, promotions []entity.Promotion, hasCoupon bool) {
+	stackable := true
+
+	for _, promotion := range promotions {
+		if hasCoupon && !promotion.StacksWithCoupons {
+			continue
+		}
+		if len(quote.Promotions) > 0 && (!stackable || !promotion.StacksWithPromotions) {
+			continue
+		}
+
+		lineDiscounts := promotionLineDiscounts(promotion, quote.Lines)
+		discount := 0
+		for i, line := range quote.Lines {
+			if remaining := line.Subtotal - line.PromotionDiscount; lineDiscounts[i] > remaining {
+				lineDiscounts[i] = remaining
+			}
+			discount += lineDiscounts[i]
+		}
+		if discount <= 0 {
+			continue
+		}
+
+		for i := range quote.Lines {
+			quote.Lines[i].PromotionDiscount += lineDiscounts[i]
+		}
+		quote.Promotions = append(quote.Promotions, dto.AppliedPromotion{
+			PromotionID: promotion.ID,
+			Name:        promotion.Name,
+			RuleType:    promotion.RuleType,
+			Discount:    discount,
+		})
+		quote.PromotionDiscount += discount
+		stackable = stackable && promotion.StacksWithPromotions
+	}
+}
+
+// promotionLineDiscounts returns what the promotion takes off each line.
+func promotionLineDiscounts(promotion entity.Promotion, lines []dto.OrderQuoteLine) []int {
+	discounts := make([]int, len(lines))
+
+	switch promotion.RuleType {
+	case entity.PromotionRuleBuyXGetY:
+		group := promotion.BuyQuantity + promotion.FreeQuantity
+		if promotion.BuyQuantity <= 0 || promotion.FreeQuantity <= 0 {
+			return discounts
+		}
+
+		for _, menu := range promotionMenus(promotion, lines) {
+			spreadMenuDiscount(discounts, lines, menu, menu.quantity/group*promotion.FreeQuantity*menu.unitPrice)
+		}
+	case entity.PromotionRuleCategoryPercentage:
+		for i, line := range lines {
+			for _, categoryId := range line.CategoryIDs {
+				if categoryId == promotion.CategoryID {
+					discounts[i] = line.Subtotal * promotion.Percentage / 100
+					break
+				}
+			}
+		}
+	case entity.PromotionRuleBundlePrice:
+		menus := promotionMenus(promotion, lines)
+		if len(menus) == 0 {
+			return discounts
+		}
+
+		sets, fullPrice := -1, 0
+		for _, menu := range menus {
+			if sets < 0 || menu.quantity < sets {
+				sets = menu.quantity
+			}
+			fullPrice += menu.unitPrice
+		}
+		if sets == 0 || fullPrice <= promotion.Price {
+			return discounts
+		}
+
+		// every menu of the set gives up its share of the saving, the last
+		// one also takes what rounding left over
+		saving := sets * (fullPrice - promotion.Price)
+		for i, menu := range menus {
+			share := saving * menu.unitPrice / fullPrice
+			if i == len(menus)-1 {
+				share = saving
+			}
+			spreadMenuDiscount(discounts, lines, menu, share)
+			saving -= share
+		}
+	}
+
+	return discounts
+}
+
+// spreadMenuDiscount takes discount off the menu price of the menu's lines in
+// order. Option surcharges are never discounted.
+func spreadMenuDiscount(discounts []int, lines []dto.OrderQuoteLine, menu promotionMenu, discount int) {
+	for i, line := range lines {
+		if discount <= 0 {
+			return
+		}
+		if line.MenuID != menu.id {
+			continue
+		}
+
+		lineDiscount := line.UnitPrice * line.Quantity
+		if lineDiscount > discount {
+			lineDiscount = discount
+		}
+		discounts[i] += lineDiscount
+		discount -= lineDiscount
+	}
+}
+
+type promotionMenu struct {
+	id        uint
+	quantity  int
+	unitPrice int
+}
+
+// promotionMenus totals the ordered quantity of every promotion menu, priced
+// at its cheapest line, in the order of the promotion details.
+func promotionMenus(promotion entity.Promotion, lines []dto.OrderQuoteLine) []promotionMenu {
+	var menus []promotionMenu
+	seen := map[uint]bool{}
+	for _, detail := range promotion.PromotionDetails {
+		if seen[detail.MenuID] {
+			continue
+		}
+		seen[detail.MenuID] = true
+
+		menu := promotionMenu{id: detail.MenuID, unitPrice: -1}
+		for _, line := range lines {
+			if line.MenuID != detail.MenuID {
+				continue
+			}
+
+			menu.quantity += line.Quantity
+			if menu.unitPrice < 0 || line.UnitPrice < menu.unitPrice {
+				menu.unitPrice = line.UnitPrice
+			}
+		}
+		if menu.quantity == 0 {
+			menu.unitPrice = 0
+		}
+
+		menus = append(menus, menu)
+	}
+
+	return menus
+}
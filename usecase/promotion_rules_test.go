@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"reflect"
+	"testing"
+)
+
+// promotionLines orders one 20000 burger (menu 1), two more burgers with a
+// 5000 surcharge and two 10000 drinks (menu 2). Burgers are in category 1 and
+// drinks in category 2.
+func promotionLines() []dto.OrderQuoteLine {
+	return []dto.OrderQuoteLine{
+		{MenuID: 1, UnitPrice: 20000, Quantity: 1, Subtotal: 20000, CategoryIDs: []uint{1}},
+		{MenuID: 1, UnitPrice: 20000, OptionSurcharge: 5000, Quantity: 2, Subtotal: 50000, CategoryIDs: []uint{1}},
+		{MenuID: 2, UnitPrice: 10000, Quantity: 2, Subtotal: 20000, CategoryIDs: []uint{2}},
+	}
+}
+
+// testPromotion builds promotion id of the rule type for the given menus.
+func testPromotion(id uint, ruleType string, menuIds ...uint) entity.Promotion {
+	promotion := entity.Promotion{Name: "Promotion", RuleType: ruleType}
+	promotion.ID = id
+	for _, menuId := range menuIds {
+		promotion.PromotionDetails = append(promotion.PromotionDetails, entity.PromotionDetail{MenuID: menuId})
+	}
+
+	return promotion
+}
+
+func buyXGetY(id uint, buy int, free int, menuIds ...uint) entity.Promotion {
+	promotion := testPromotion(id, entity.PromotionRuleBuyXGetY, menuIds...)
+	promotion.BuyQuantity = buy
+	promotion.FreeQuantity = free
+	return promotion
+}
+
+func categoryPercentage(id uint, categoryId uint, percentage int) entity.Promotion {
+	promotion := testPromotion(id, entity.PromotionRuleCategoryPercentage)
+	promotion.CategoryID = categoryId
+	promotion.Percentage = percentage
+	return promotion
+}
+
+func bundlePrice(id uint, price int, menuIds ...uint) entity.Promotion {
+	promotion := testPromotion(id, entity.PromotionRuleBundlePrice, menuIds...)
+	promotion.Price = price
+	return promotion
+}
+
+// stacking lets the promotion stack with other promotions and with coupons.
+func stacking(promotion entity.Promotion) entity.Promotion {
+	promotion.StacksWithPromotions = true
+	promotion.StacksWithCoupons = true
+	return promotion
+}
+
+func TestPromotionLineDiscounts(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion entity.Promotion
+		want      []int
+	}{
+		{
+			name:      "buy x get y across lines",
+			promotion: buyXGetY(1, 1, 2, 1),
+			want:      []int{20000, 20000, 0},
+		},
+		{
+			name:      "buy x get y per menu",
+			promotion: buyXGetY(1, 1, 1, 1, 2),
+			want:      []int{20000, 0, 10000},
+		},
+		{
+			name:      "buy x get y without enough units",
+			promotion: buyXGetY(1, 3, 1, 2),
+			want:      []int{0, 0, 0},
+		},
+		{
+			name:      "category percentage",
+			promotion: categoryPercentage(1, 1, 10),
+			want:      []int{2000, 5000, 0},
+		},
+		{
+			name:      "category percentage of another category",
+			promotion: categoryPercentage(1, 3, 10),
+			want:      []int{0, 0, 0},
+		},
+		{
+			name:      "bundle split by menu price",
+			promotion: bundlePrice(1, 25000, 1, 2),
+			want:      []int{6666, 0, 3334},
+		},
+		{
+			name:      "bundle missing a menu",
+			promotion: bundlePrice(1, 25000, 1, 2, 3),
+			want:      []int{0, 0, 0},
+		},
+		{
+			name:      "bundle dearer than its menus",
+			promotion: bundlePrice(1, 35000, 1, 2),
+			want:      []int{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := promotionLineDiscounts(tt.promotion, promotionLines()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected line discounts %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyPromotions(t *testing.T) {
+	nonStacking := categoryPercentage(1, 1, 10)
+	notForCoupons := stacking(categoryPercentage(1, 1, 10))
+	notForCoupons.StacksWithCoupons = false
+
+	tests := []struct {
+		name              string
+		promotions        []entity.Promotion
+		hasCoupon         bool
+		wantPromotions    []uint
+		wantLineDiscounts []int
+	}{
+		{
+			name:              "stacking promotions see what the others left",
+			promotions:        []entity.Promotion{stacking(categoryPercentage(1, 1, 10)), stacking(buyXGetY(2, 1, 2, 1))},
+			wantPromotions:    []uint{1, 2},
+			wantLineDiscounts: []int{20000, 25000, 0},
+		},
+		{
+			name:              "a promotion that does not stack keeps the others out",
+			promotions:        []entity.Promotion{nonStacking, stacking(bundlePrice(2, 25000, 1, 2))},
+			wantPromotions:    []uint{1},
+			wantLineDiscounts: []int{2000, 5000, 0},
+		},
+		{
+			name:              "a promotion that does not stack joins no other",
+			promotions:        []entity.Promotion{stacking(bundlePrice(2, 25000, 1, 2)), nonStacking},
+			wantPromotions:    []uint{2},
+			wantLineDiscounts: []int{6666, 0, 3334},
+		},
+		{
+			name:              "a promotion without a discount blocks nothing",
+			promotions:        []entity.Promotion{buyXGetY(1, 3, 1, 2), stacking(categoryPercentage(2, 2, 50))},
+			wantPromotions:    []uint{2},
+			wantLineDiscounts: []int{0, 0, 10000},
+		},
+		{
+			name:              "discounts are capped at what the line has left",
+			promotions:        []entity.Promotion{stacking(categoryPercentage(1, 2, 60)), stacking(categoryPercentage(2, 2, 60))},
+			wantPromotions:    []uint{1, 2},
+			wantLineDiscounts: []int{0, 0, 20000},
+		},
+		{
+			name:              "a coupon skips promotions that do not stack with it",
+			promotions:        []entity.Promotion{notForCoupons, stacking(categoryPercentage(2, 2, 50))},
+			hasCoupon:         true,
+			wantPromotions:    []uint{2},
+			wantLineDiscounts: []int{0, 0, 10000},
+		},
+		{
+			name:              "without a coupon every promotion is considered",
+			promotions:        []entity.Promotion{notForCoupons, stacking(categoryPercentage(2, 2, 50))},
+			wantPromotions:    []uint{1, 2},
+			wantLineDiscounts: []int{2000, 5000, 10000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := dto.OrderQuote{Lines: promotionLines(), Subtotal: 90000}
+			applyPromotions(&quote, tt.promotions, tt.hasCoupon)
+
+			var promotions []uint
+			total := 0
+			for _, promotion := range quote.Promotions {
+				promotions = append(promotions, promotion.PromotionID)
+				total += promotion.Discount
+			}
+			if !reflect.DeepEqual(promotions, tt.wantPromotions) {
+				t.Errorf("expected promotions %v, got %v", tt.wantPromotions, promotions)
+			}
+
+			lineDiscounts := []int{}
+			lineTotal := 0
+			for _, line := range quote.Lines {
+				lineDiscounts = append(lineDiscounts, line.PromotionDiscount)
+				lineTotal += line.PromotionDiscount
+			}
+			if !reflect.DeepEqual(lineDiscounts, tt.wantLineDiscounts) {
+				t.Errorf("expected line discounts %v, got %v", tt.wantLineDiscounts, lineDiscounts)
+			}
+			if quote.PromotionDiscount != total || quote.PromotionDiscount != lineTotal {
+				t.Errorf("expected the promotion discount %d to match the promotions %d and lines %d", quote.PromotionDiscount, total, lineTotal)
+			}
+		})
+	}
+}
@@ -1,11 +1,11 @@
 package usecase
 
 import (
-	"encoding/json"
 	"final-project-backend/domain"
 	"final-project-backend/dto"
 	"final-project-backend/entity"
 	"final-project-backend/repository"
+	"fmt"
 	"time"
 )
 
@@ -21,32 +21,38 @@ type PromotionUsecase interface {
 type promotionUsecaseImpl struct {
 	promotionRepo     repository.PromotionRepository
 	paymentOptionRepo repository.PaymentOptionRepository
+	categoryRepo      repository.CategoryRepository
 	mediaUsecase      MediaUsecase
 	menuUsecase       MenuUsecase
 	cartUsecase       CartUsecase
 	couponUsecase     CouponUsecase
 	orderUsecase      OrderUsecase
+	checkoutUsecase   CheckoutUsecase
 }
 
 type PromotionUsecaseConfig struct {
 	PromotionRepo     repository.PromotionRepository
 	PaymentOptionRepo repository.PaymentOptionRepository
+	CategoryRepo      repository.CategoryRepository
 	MediaUsecase      MediaUsecase
 	MenuUsecase       MenuUsecase
 	CartUsecase       CartUsecase
 	CouponUsecase     CouponUsecase
 	OrderUsecase      OrderUsecase
+	CheckoutUsecase   CheckoutUsecase
 }
 
 func NewPromotionUsecase(c PromotionUsecaseConfig) PromotionUsecase {
 	return &promotionUsecaseImpl{
 		promotionRepo:     c.PromotionRepo,
 		paymentOptionRepo: c.PaymentOptionRepo,
+		categoryRepo:      c.CategoryRepo,
 		mediaUsecase:      c.MediaUsecase,
 		menuUsecase:       c.MenuUsecase,
 		cartUsecase:       c.CartUsecase,
 		couponUsecase:     c.CouponUsecase,
 		orderUsecase:      c.OrderUsecase,
+		checkoutUsecase:   c.CheckoutUsecase,
 	}
 }
 
@@ -71,6 +77,11 @@ func (u *promotionUsecaseImpl) GetPromotionById(id uint) (*entity.Promotion, err
 }
 
 func (u *promotionUsecaseImpl) CreatePromotion(input dto.PromotionFormRequest) (*entity.Promotion, error) {
+	err := u.validatePromotionRule(input)
+	if err != nil {
+		return nil, err
+	}
+
 	var promotionDetails []entity.PromotionDetail
 
 	for _, menuIDs := range input.MenuIDs {
@@ -102,6 +113,8 @@ func (u *promotionUsecaseImpl) CreatePromotion(input dto.PromotionFormRequest) (
 		Price:       input.Price,
 		ExpiredDate: input.ExpiredDate,
 	}
+	applyPromotionRule(&promotion, input)
+
 	var picUrl, publicId string
 
 	if input.Picture.Size != 0 {
@@ -125,6 +138,11 @@ func (u *promotionUsecaseImpl) CreatePromotion(input dto.PromotionFormRequest) (
 }
 
 func (u *promotionUsecaseImpl) UpdatePromotion(input dto.PromotionFormRequest, id uint) (*entity.Promotion, error) {
+	err := u.validatePromotionRule(input)
+	if err != nil {
+		return nil, err
+	}
+
 	var promotionDetails []entity.PromotionDetail
 
 	for _, menuIDs := range input.MenuIDs {
@@ -170,6 +188,7 @@ func (u *promotionUsecaseImpl) UpdatePromotion(input dto.PromotionFormRequest, i
 	promotion.Description = input.Description
 	promotion.Price = input.Price
 	promotion.ExpiredDate = input.ExpiredDate
+	applyPromotionRule(promotion, input)
 
 	err = u.promotionRepo.DeletePaymentRequirements(id)
 	if err != nil {
@@ -222,85 +241,62 @@ func (u *promotionUsecaseImpl) CreatePromotionOrder(orderRequest dto.PromotionOr
 		return nil, domain.ErrPromotionNotFound
 	}
 
-	paymentOption, err := u.paymentOptionRepo.GetPaymentOptionById(orderRequest.PaymentOptionID)
-	if paymentOption == nil {
-		return nil, domain.ErrPaymentOptionNotFound
-	}
-
-	order := entity.Order{
-		CouponID:        orderRequest.CouponID,
-		PaymentOptionID: orderRequest.PaymentOptionID,
+	if !isBundlePromotion(*promotion) {
+		return nil, domain.ErrPromotionNotOrderable
 	}
 
-	totalPrice := 0
+	return u.checkoutUsecase.CheckoutPromotion(*promotion, orderRequest)
+}
 
-	for _, orderDetailRequest := range orderRequest.OrderDetailRequest {
-		menuOptionStr, _ := json.Marshal(orderDetailRequest.MenuOptions)
-		err := u.menuUsecase.IsValidMenuOptions(orderDetailRequest.MenuID, string(menuOptionStr))
-		if err != nil {
-			return nil, domain.ErrMalformedRequest
-		}
+// isBundlePromotion reports whether the promotion can be ordered at its price
+// through its own order flow. Buy x get y and category percentage promotions
+// are only applied to regular orders and have no price to charge.
+func isBundlePromotion(promotion entity.Promotion) bool {
+	isBundleRule := promotion.RuleType == "" || promotion.RuleType == entity.PromotionRuleBundlePrice
+	return isBundleRule && promotion.Price > 0
+}
 
-		menu, _ := u.menuUsecase.GetMenuById(orderDetailRequest.MenuID)
-		if menu == nil {
-			return nil, domain.ErrMenuNotFound
+// validatePromotionRule checks the request carries what its rule type needs.
+// Bundles can be ordered on their own, so they need a price.
+func (u *promotionUsecaseImpl) validatePromotionRule(input dto.PromotionFormRequest) error {
+	switch input.RuleType {
+	case "", entity.PromotionRuleBundlePrice:
+		if input.Price <= 0 {
+			return fmt.Errorf("%w: price must be greater than 0", domain.ErrInvalidPromotionRule)
 		}
-
-		for _, menuOption := range orderDetailRequest.MenuOptions {
-			for _, optionList := range menuOption.MenuOptionLists {
-				totalPrice += optionList.Price * orderDetailRequest.Quantity
-			}
+	case entity.PromotionRuleBuyXGetY:
+		if input.BuyQuantity <= 0 || input.FreeQuantity <= 0 {
+			return fmt.Errorf("%w: buy_quantity and free_quantity must be greater than 0", domain.ErrInvalidPromotionRule)
 		}
-	}
-
-	totalPrice = totalPrice + promotion.Price
-
-	orderedMenus := promotion.Name
-
-	order.TotalPrice = totalPrice
-	order.OrderedMenus = orderedMenus
-
-	var coupon *entity.Coupon
-	if orderRequest.CouponID != nil {
-		coupon, err = u.couponUsecase.GetCouponById(*orderRequest.CouponID)
-		if coupon == nil {
-			return nil, domain.ErrCouponNotFound
+	case entity.PromotionRuleCategoryPercentage:
+		if input.Percentage <= 0 || input.Percentage > 100 {
+			return fmt.Errorf("%w: percentage must be between 1 and 100", domain.ErrInvalidPromotionRule)
 		}
 
-		userCoupon, _ := u.couponUsecase.GetUserCouponByFK(coupon.ID, orderRequest.UserID)
-		if userCoupon == nil {
-			return nil, domain.ErrUserCouponNotFound
+		category, _ := u.categoryRepo.GetCategoryByID(input.CategoryID)
+		if category == nil {
+			return fmt.Errorf("%w: no category with id %d", domain.ErrInvalidPromotionRule, input.CategoryID)
 		}
-		order.TotalPrice = order.TotalPrice - coupon.Discount
-	}
-
-	order.UserID = orderRequest.UserID
-	order.OrderDate = time.Now()
 
-	if order.TotalPrice < 0 {
-		order.TotalPrice = 0
+		return nil
+	default:
+		return fmt.Errorf("%w: rule_type must be buy_x_get_y, category_percentage or bundle_price", domain.ErrInvalidPromotionRule)
 	}
 
-	var orderDetails []entity.OrderDetail
-	for _, orderDetailRequest := range orderRequest.OrderDetailRequest {
-		menuOptionStr, _ := json.Marshal(orderDetailRequest.MenuOptions)
-		orderDetail := entity.OrderDetail{
-			MenuID:      orderDetailRequest.MenuID,
-			Quantity:    orderDetailRequest.Quantity,
-			MenuOptions: string(menuOptionStr),
-		}
-		orderDetails = append(orderDetails, orderDetail)
-	}
-
-	deliveryData := entity.Delivery{
-		Address: orderRequest.DeliveryAddress,
-		Status:  "pending",
+	if len(input.MenuIDs) == 0 {
+		return fmt.Errorf("%w: menu_ids is required", domain.ErrInvalidPromotionRule)
 	}
 
-	orderRes, err := u.orderUsecase.CreateOrderProcess(order, orderDetails, deliveryData)
-	if err != nil {
-		return nil, err
-	}
+	return nil
+}
 
-	return orderRes, nil
+func applyPromotionRule(promotion *entity.Promotion, input dto.PromotionFormRequest) {
+	promotion.RuleType = input.RuleType
+	promotion.BuyQuantity = input.BuyQuantity
+	promotion.FreeQuantity = input.FreeQuantity
+	promotion.CategoryID = input.CategoryID
+	promotion.Percentage = input.Percentage
+	promotion.Priority = input.Priority
+	promotion.StacksWithPromotions = input.StacksWithPromotions
+	promotion.StacksWithCoupons = input.StacksWithCoupons
 }
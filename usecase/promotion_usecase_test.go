@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"testing"
+	"time"
+)
+
+func TestCreatePromotionOrderOnlyOrdersBundles(t *testing.T) {
+	nextWeek := time.Now().AddDate(0, 0, 7)
+	bundle := testPromotion(1, "", 1, 2)
+	bundle.Price = 25000
+	expired := bundle
+	expired.ExpiredDate = time.Now().AddDate(0, 0, -1)
+
+	tests := []struct {
+		name      string
+		promotion entity.Promotion
+		wantErr   error
+	}{
+		{"bundle", bundle, nil},
+		{"bundle price rule", bundlePrice(1, 25000, 1, 2), nil},
+		{"bundle without a price", testPromotion(1, "", 1, 2), domain.ErrPromotionNotOrderable},
+		{"buy x get y", buyXGetY(1, 1, 1, 1), domain.ErrPromotionNotOrderable},
+		{"category percentage", categoryPercentage(1, 1, 10), domain.ErrPromotionNotOrderable},
+		{"expired", expired, domain.ErrPromotionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.promotion.ExpiredDate.IsZero() {
+				tt.promotion.ExpiredDate = nextWeek
+			}
+			checkoutUsecase := &fakeCheckoutUsecase{}
+			promotionUsecase := NewPromotionUsecase(PromotionUsecaseConfig{
+				PromotionRepo:   &fakePromotionRepo{promotions: map[uint]*entity.Promotion{1: &tt.promotion}},
+				CheckoutUsecase: checkoutUsecase,
+			})
+
+			_, err := promotionUsecase.CreatePromotionOrder(dto.PromotionOrderRequest{PromotionID: 1, UserID: 1})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if ordered := len(checkoutUsecase.ordered) == 1; ordered != (tt.wantErr == nil) {
+				t.Errorf("expected the promotion to be ordered only when allowed, got %v", checkoutUsecase.ordered)
+			}
+		})
+	}
+}
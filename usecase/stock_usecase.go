@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+)
+
+// DefaultLowStockThreshold is used by the low-stock report when the admin
+// does not pass a threshold.
+const DefaultLowStockThreshold = 5
+
+type StockUsecase interface {
+	SetMenuStock(menuId uint, stock *int) (*entity.Menu, error)
+	RestockMenu(menuId uint, quantity int) (*entity.Menu, error)
+	SetMenuOptionListStock(menuId uint, optionId uint, listId uint, stock *int) (*entity.MenuOptionList, error)
+	RestockMenuOptionList(menuId uint, optionId uint, listId uint, quantity int) (*entity.MenuOptionList, error)
+	GetLowStockReport(threshold int) (*dto.LowStockReport, error)
+}
+
+type stockUsecaseImpl struct {
+	stockRepo      repository.StockRepository
+	menuRepo       repository.MenuRepository
+	menuOptionRepo repository.MenuOptionRepository
+}
+
+type StockUsecaseConfig struct {
+	StockRepo      repository.StockRepository
+	MenuRepo       repository.MenuRepository
+	MenuOptionRepo repository.MenuOptionRepository
+}
+
+func NewStockUsecase(c StockUsecaseConfig) StockUsecase {
+	return &stockUsecaseImpl{
+		stockRepo:      c.StockRepo,
+		menuRepo:       c.MenuRepo,
+		menuOptionRepo: c.MenuOptionRepo,
+	}
+}
+
+func (s *stockUsecaseImpl) SetMenuStock(menuId uint, stock *int) (*entity.Menu, error) {
+	if stock != nil && *stock < 0 {
+		return nil, domain.ErrInvalidStock
+	}
+
+	menu, _ := s.menuRepo.GetMenuById(menuId)
+	if menu == nil {
+		return nil, domain.ErrMenuNotFound
+	}
+
+	err := s.stockRepo.SetMenuStock(menuId, stock)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return s.menuRepo.GetMenuById(menuId)
+}
+
+func (s *stockUsecaseImpl) RestockMenu(menuId uint, quantity int) (*entity.Menu, error) {
+	if quantity <= 0 {
+		return nil, domain.ErrInvalidStock
+	}
+
+	menu, _ := s.menuRepo.GetMenuById(menuId)
+	if menu == nil {
+		return nil, domain.ErrMenuNotFound
+	}
+
+	err := s.stockRepo.RestockMenu(menuId, quantity)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return s.menuRepo.GetMenuById(menuId)
+}
+
+func (s *stockUsecaseImpl) SetMenuOptionListStock(menuId uint, optionId uint, listId uint, stock *int) (*entity.MenuOptionList, error) {
+	if stock != nil && *stock < 0 {
+		return nil, domain.ErrInvalidStock
+	}
+
+	_, err := s.getMenuOptionList(menuId, optionId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.stockRepo.SetMenuOptionListStock(listId, stock)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return s.menuOptionRepo.GetMenuOptionListByID(listId)
+}
+
+func (s *stockUsecaseImpl) RestockMenuOptionList(menuId uint, optionId uint, listId uint, quantity int) (*entity.MenuOptionList, error) {
+	if quantity <= 0 {
+		return nil, domain.ErrInvalidStock
+	}
+
+	_, err := s.getMenuOptionList(menuId, optionId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.stockRepo.RestockMenuOptionList(listId, quantity)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return s.menuOptionRepo.GetMenuOptionListByID(listId)
+}
+
+func (s *stockUsecaseImpl) GetLowStockReport(threshold int) (*dto.LowStockReport, error) {
+	if threshold < 0 {
+		return nil, domain.ErrInvalidStock
+	}
+
+	menus, err := s.stockRepo.GetLowStockMenus(threshold)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	choices, err := s.stockRepo.GetLowStockMenuOptionLists(threshold)
+	if err != nil {
+		return nil, domain.ErrInternalServer
+	}
+
+	return &dto.LowStockReport{
+		Threshold: threshold,
+		Menus:     menus,
+		Choices:   choices,
+	}, nil
+}
+
+// getMenuOptionList makes sure the choice belongs to the option group and the
+// option group to the menu of the route.
+func (s *stockUsecaseImpl) getMenuOptionList(menuId uint, optionId uint, listId uint) (*entity.MenuOptionList, error) {
+	menu, _ := s.menuRepo.GetMenuById(menuId)
+	if menu == nil {
+		return nil, domain.ErrMenuNotFound
+	}
+
+	option, _ := s.menuOptionRepo.GetMenuOptionByID(optionId)
+	if option == nil || option.MenuID != menuId {
+		return nil, domain.ErrMenuOptionNotFound
+	}
+
+	optionList, _ := s.menuOptionRepo.GetMenuOptionListByID(listId)
+	if optionList == nil || optionList.MenuOptionID != optionId {
+		return nil, domain.ErrMenuOptionListNotFound
+	}
+
+	return optionList, nil
+}
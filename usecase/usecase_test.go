@@ -0,0 +1,366 @@
+package usecase
+
+import (
+	"errors"
+	"final-project-backend/domain"
+	"final-project-backend/dto"
+	"final-project-backend/entity"
+	"final-project-backend/repository"
+	"fmt"
+	"mime/multipart"
+	"time"
+)
+
+// The fakes below embed the repository interfaces so each only implements the
+// methods the tests reach. Calling anything else panics on the nil interface.
+
+type fakeMenuRepo struct {
+	repository.MenuRepository
+	menus          map[uint]*entity.Menu
+	menuCategories []entity.CategoriesMenu
+}
+
+func (r *fakeMenuRepo) GetMenuById(id uint) (*entity.Menu, error) {
+	menu, ok := r.menus[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return menu, nil
+}
+
+func (r *fakeMenuRepo) GetAllMenus() ([]entity.Menu, error) {
+	menus := []entity.Menu{}
+	for id := uint(1); len(menus) < len(r.menus); id++ {
+		if menu, ok := r.menus[id]; ok {
+			menus = append(menus, *menu)
+		}
+	}
+
+	return menus, nil
+}
+
+func (r *fakeMenuRepo) CreateMenu(menu entity.Menu) (*entity.Menu, error) {
+	if r.menus == nil {
+		r.menus = map[uint]*entity.Menu{}
+	}
+
+	menu.ID = uint(len(r.menus) + 1)
+	r.menus[menu.ID] = &menu
+	return &menu, nil
+}
+
+func (r *fakeMenuRepo) CreateMenuCategories(menuCategories []entity.CategoriesMenu) error {
+	r.menuCategories = append(r.menuCategories, menuCategories...)
+	return nil
+}
+
+func (r *fakeMenuRepo) RefreshMenuSearchVector(menuId uint) error {
+	return nil
+}
+
+// fakeCategoryRepo keeps the categories in a slice. Saving a category fails
+// with saveErr when it is set.
+type fakeCategoryRepo struct {
+	repository.CategoryRepository
+	categories []entity.Category
+	saveErr    error
+}
+
+func (r *fakeCategoryRepo) GetCategoryByID(id uint) (*entity.Category, error) {
+	for _, category := range r.categories {
+		if category.ID == id {
+			return &category, nil
+		}
+	}
+
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeCategoryRepo) GetCategoryBySlug(slug string) (*entity.Category, error) {
+	for _, category := range r.categories {
+		if category.Slug == slug {
+			return &category, nil
+		}
+	}
+
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeCategoryRepo) CreateCategory(category entity.Category) (*entity.Category, error) {
+	if r.saveErr != nil {
+		return nil, r.saveErr
+	}
+
+	category.ID = uint(len(r.categories) + 1)
+	r.categories = append(r.categories, category)
+	return &category, nil
+}
+
+func (r *fakeCategoryRepo) UpdateCategory(category entity.Category) (*entity.Category, error) {
+	if r.saveErr != nil {
+		return nil, r.saveErr
+	}
+
+	for i := range r.categories {
+		if r.categories[i].ID == category.ID {
+			r.categories[i] = category
+		}
+	}
+	return &category, nil
+}
+
+func (r *fakeCategoryRepo) GetCategories(withMenus bool) ([]entity.Category, error) {
+	return r.categories, nil
+}
+
+func (r *fakeCategoryRepo) GetCategoriesByIDs(ids []uint) ([]entity.Category, error) {
+	categories := []entity.Category{}
+	for _, category := range r.categories {
+		for _, id := range ids {
+			if category.ID == id {
+				categories = append(categories, category)
+			}
+		}
+	}
+
+	return categories, nil
+}
+
+type fakePromotionRepo struct {
+	repository.PromotionRepository
+	rules      []entity.Promotion
+	promotions map[uint]*entity.Promotion
+}
+
+func (r *fakePromotionRepo) GetPromotionById(id uint) (*entity.Promotion, error) {
+	promotion, ok := r.promotions[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+
+	copied := *promotion
+	return &copied, nil
+}
+
+func (r *fakePromotionRepo) GetActivePromotionRules(now time.Time) ([]entity.Promotion, error) {
+	return r.rules, nil
+}
+
+type fakeMenuOptionRepo struct {
+	repository.MenuOptionRepository
+	options []entity.MenuOption
+	err     error
+	saved   map[uint][]entity.MenuOption
+}
+
+func (r *fakeMenuOptionRepo) GetMenuOptionsByMenuID(menuId uint) ([]entity.MenuOption, error) {
+	return r.options, r.err
+}
+
+func (r *fakeMenuOptionRepo) SaveMenuOptions(menuId uint, options []entity.MenuOption) ([]entity.MenuOption, error) {
+	if r.saved == nil {
+		r.saved = map[uint][]entity.MenuOption{}
+	}
+
+	r.saved[menuId] = options
+	return options, nil
+}
+
+// fakeCouponUsecase returns a fixed evaluation for every coupon.
+type fakeCouponUsecase struct {
+	CouponUsecase
+	evaluation dto.CouponEvaluation
+}
+
+func (c *fakeCouponUsecase) Evaluate(coupon entity.Coupon, userId uint, quote dto.OrderQuote) (*dto.CouponEvaluation, error) {
+	evaluation := c.evaluation
+	evaluation.CouponID = coupon.ID
+	return &evaluation, nil
+}
+
+func testMenu(id uint, name string, price int) *entity.Menu {
+	menu := &entity.Menu{Name: name, Price: price}
+	menu.ID = id
+	return menu
+}
+
+// fakeUnitOfWork runs the work against the given repositories. It does not
+// roll anything back, so tests only look at its effects after a success.
+type fakeUnitOfWork struct {
+	repos repository.TxRepositories
+}
+
+func (u *fakeUnitOfWork) Do(fn func(repos repository.TxRepositories) error) error {
+	return fn(u.repos)
+}
+
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	orders        map[uint]*entity.Order
+	events        []entity.OrderStatusEvent
+	restoredStock []uint
+}
+
+func (r *fakeOrderRepo) GetOrderByID(id uint) (*entity.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrderRepo) GetAllOrders(user dto.UserResponse, query dto.Query) ([]entity.Order, int64, error) {
+	var orders []entity.Order
+	for _, order := range r.orders {
+		if order.UserID == user.ID && (query.Status == "" || order.Status == query.Status) {
+			orders = append(orders, *order)
+		}
+	}
+
+	return orders, int64(len(orders)), nil
+}
+
+func (r *fakeOrderRepo) UpdateOrderStatus(order entity.Order, status string) error {
+	stored := r.orders[order.ID]
+	if stored.Status != order.Status {
+		return domain.ErrInvalidStatusTransition
+	}
+
+	stored.Status = status
+	return nil
+}
+
+func (r *fakeOrderRepo) CreateOrderStatusEvent(event entity.OrderStatusEvent) (*entity.OrderStatusEvent, error) {
+	r.events = append(r.events, event)
+	return &event, nil
+}
+
+func (r *fakeOrderRepo) RestoreOrderStock(orderId uint) error {
+	r.restoredStock = append(r.restoredStock, orderId)
+	return nil
+}
+
+type fakeDeliveryRepo struct {
+	repository.DeliveryRepository
+	deliveries map[uint]*entity.Delivery
+}
+
+func (r *fakeDeliveryRepo) GetDeliveryByID(id uint) (*entity.Delivery, error) {
+	for _, delivery := range r.deliveries {
+		if delivery.ID == id {
+			copied := *delivery
+			return &copied, nil
+		}
+	}
+
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeDeliveryRepo) GetDeliveryByOrderID(orderId uint) (*entity.Delivery, error) {
+	delivery, ok := r.deliveries[orderId]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+
+	copied := *delivery
+	return &copied, nil
+}
+
+func (r *fakeDeliveryRepo) UpdateDeliveryStatus(delivery entity.Delivery) (*entity.Delivery, error) {
+	r.deliveries[delivery.OrderID] = &delivery
+	return &delivery, nil
+}
+
+type fakeCouponRepo struct {
+	repository.CouponRepository
+	coupons              map[uint]*entity.Coupon
+	usedOrders           int64
+	restored             []uint
+	cancelledRedemptions []uint
+}
+
+func (r *fakeCouponRepo) GetCouponById(id uint) (*entity.Coupon, error) {
+	coupon, ok := r.coupons[id]
+	if !ok {
+		return nil, domain.ErrCouponNotFound
+	}
+
+	copied := *coupon
+	return &copied, nil
+}
+
+func (r *fakeCouponRepo) GetCouponByRedeemCode(code string) (*entity.Coupon, error) {
+	for _, coupon := range r.coupons {
+		if coupon.RedeemCode == code {
+			copied := *coupon
+			return &copied, nil
+		}
+	}
+
+	return nil, domain.ErrRedeemCodeNotFound
+}
+
+func (r *fakeCouponRepo) CreateCoupon(coupon entity.Coupon) (*entity.Coupon, error) {
+	if r.coupons == nil {
+		r.coupons = map[uint]*entity.Coupon{}
+	}
+
+	coupon.ID = uint(len(r.coupons) + 1)
+	r.coupons[coupon.ID] = &coupon
+	return &coupon, nil
+}
+
+func (r *fakeCouponRepo) UpdateCoupon(coupon entity.Coupon) (*entity.Coupon, error) {
+	r.coupons[coupon.ID] = &coupon
+	return &coupon, nil
+}
+
+func (r *fakeCouponRepo) CountUserCouponOrders(couponId uint, userId uint) (int64, error) {
+	return r.usedOrders, nil
+}
+
+func (r *fakeCouponRepo) RestoreUserCoupon(couponId uint, userId uint) error {
+	r.restored = append(r.restored, couponId)
+	return nil
+}
+
+func (r *fakeCouponRepo) CancelCouponRedemption(orderId uint) error {
+	r.cancelledRedemptions = append(r.cancelledRedemptions, orderId)
+	return nil
+}
+
+// fakeMediaUsecase hands out numbered public IDs and records the deleted ones.
+type fakeMediaUsecase struct {
+	MediaUsecase
+	uploaded []string
+	deleted  []string
+}
+
+func (m *fakeMediaUsecase) FileUpload(file multipart.FileHeader) (string, string, error) {
+	publicId := fmt.Sprintf("upload-%d", len(m.uploaded)+1)
+	m.uploaded = append(m.uploaded, publicId)
+	return "https://example.com/" + publicId, publicId, nil
+}
+
+func (m *fakeMediaUsecase) FileDelete(publicId string) error {
+	m.deleted = append(m.deleted, publicId)
+	return nil
+}
+
+// fakeCheckoutUsecase places every promotion order as order 1 and records the
+// ordered promotions.
+type fakeCheckoutUsecase struct {
+	CheckoutUsecase
+	ordered []uint
+}
+
+func (u *fakeCheckoutUsecase) CheckoutPromotion(promotion entity.Promotion, input dto.PromotionOrderRequest) (*entity.Order, error) {
+	u.ordered = append(u.ordered, promotion.ID)
+
+	order := &entity.Order{UserID: input.UserID}
+	order.ID = 1
+	return order, nil
+}
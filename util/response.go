@@ -1,6 +1,8 @@
 package util
 
 import (
+	"final-project-backend/dto"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -8,6 +10,11 @@ type JSONResponseSuccess struct {
 	Data any `json:"data"`
 }
 
+type JSONResponsePaginated struct {
+	Data       any            `json:"data"`
+	Pagination dto.Pagination `json:"pagination"`
+}
+
 type JSONResponseError struct {
 	Message string `json:"message"`
 	Code    string `json:"code"`
@@ -19,6 +26,13 @@ func ResponseSuccesJSON(c *gin.Context, data any, statusCode int) {
 	})
 }
 
+func ResponsePaginatedJSON(c *gin.Context, data any, pagination dto.Pagination, statusCode int) {
+	c.JSON(statusCode, JSONResponsePaginated{
+		Data:       data,
+		Pagination: pagination,
+	})
+}
+
 func ResponseErrorJSON(c *gin.Context, message string, code string, statusCode int) {
 	c.JSON(statusCode, JSONResponseError{
 		Message: message,
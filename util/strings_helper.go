@@ -1,9 +1,12 @@
 package util
 
 import (
+	crand "crypto/rand"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -28,3 +31,34 @@ func RandomFileName(length int) string {
 func RemoveSpaces(inputString string) string {
 	return regexp.MustCompile(`\s+`).ReplaceAllString(inputString, "")
 }
+
+func IsSlug(inputString string) (bool, error) {
+	return regexp.MatchString(`^[a-z0-9]+(-[a-z0-9]+)*$`, inputString)
+}
+
+// Slugify lowercases the string and joins its words with dashes.
+func Slugify(inputString string) string {
+	slug := regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(inputString), "-")
+	return strings.Trim(slug, "-")
+}
+
+// codeAlphabet leaves out characters that are easily confused, like 0 and O.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// RandomCode returns an unguessable uppercase code for users to type in.
+func RandomCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := crand.Int(crand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+func IsRedeemCode(inputString string) (bool, error) {
+	return regexp.MatchString(`^[A-Z0-9]{4,20}$`, inputString)
+}